@@ -1,5 +1,7 @@
 module github.com/wbrefvem/go-gits
 
+go 1.13
+
 require (
 	cloud.google.com/go v0.34.0 // indirect
 	code.gitea.io/sdk v0.0.0-20181204230321-6abdddefe30b
@@ -1,8 +1,12 @@
 package bitbucketcloud
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -10,7 +14,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/log"
@@ -26,8 +29,26 @@ type CloudProvider struct {
 	URL      string
 	Name     string
 	Git      git.Gitter
+	Options  git.ProviderOptions
+	// ForkPollConfig is how ForkRepository polls for the fork
+	ForkPollConfig git.ForkPollConfig
+
+	token string
+	// users caches the users looked up by GetUsers
+	users *git.UserCache
+	// apiURL is the base URL of the API for the requests the client doesn't support
+	apiURL string
 }
 
+// bitbucketForkPollConfig checks for forks every couple of seconds for up to a minute
+var bitbucketForkPollConfig = git.ForkPollConfig{
+	Interval: 2 * time.Second,
+	Timeout:  time.Minute,
+}
+
+// rawEmailMatcher extracts the email from the raw Git commit author in the form: User <email@example.com>
+var rawEmailMatcher = regexp.MustCompile("[^<]*<([^>]+)>")
+
 var stateMap = map[string]string{
 	"SUCCESSFUL": "success",
 	"FAILED":     "failure",
@@ -35,25 +56,37 @@ var stateMap = map[string]string{
 	"STOPPED":    "stopped",
 }
 
-func NewProvider(username, serverURL, token, providerName string, git git.Gitter) (git.Provider, error) {
-	ctx := context.Background()
+func init() {
+	git.RegisterProviderFactory(git.KindBitBucketCloud, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, git.KindBitBucketCloud, gitter, options...)
+	})
+}
+
+func NewProvider(username, serverURL, token, providerName string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	providerOptions := git.NewProviderOptions(options...)
 
 	basicAuth := bitbucket.BasicAuth{
 		UserName: username,
 		Password: token,
 	}
-	basicAuthContext := context.WithValue(ctx, bitbucket.ContextBasicAuth, basicAuth)
+	basicAuthContext := context.WithValue(providerOptions.RequestContext(), bitbucket.ContextBasicAuth, basicAuth)
 
 	provider := CloudProvider{
 		URL:      serverURL,
 		Name:     providerName,
 		Username: username,
 		Context:  basicAuthContext,
-		Git:      git,
+		Git:      gitter,
+		Options:  providerOptions,
+		token:    token,
+		users:    &git.UserCache{},
 	}
+	provider.ForkPollConfig = bitbucketForkPollConfig
 
 	cfg := bitbucket.NewConfiguration()
+	cfg.HTTPClient = provider.Options.NewHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(cfg)
+	provider.apiURL = cfg.BasePath
 
 	return &provider, nil
 }
@@ -62,34 +95,44 @@ func (b *CloudProvider) ListOrganisations() ([]git.Organisation, error) {
 
 	teams := []git.Organisation{}
 
-	var results bitbucket.PaginatedTeams
-	var err error
-
-	// Pagination is gross.
-	for {
-		if results.Next == "" {
+	err := paginate(func(next string) (string, error) {
+		var results bitbucket.PaginatedTeams
+		var err error
+		if next == "" {
 			results, _, err = b.Client.TeamsApi.TeamsGet(b.Context, map[string]interface{}{"role": "member"})
 		} else {
-			results, _, err = b.Client.PagingApi.TeamsPageGet(b.Context, results.Next)
+			results, _, err = b.Client.PagingApi.TeamsPageGet(b.Context, next)
 		}
 
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		for _, team := range results.Values {
 			teams = append(teams, git.Organisation{Login: team.Username})
 		}
-
-		if results.Next == "" {
-			break
-		}
+		return results.Next, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return teams, nil
 }
 
+func (b *CloudProvider) ListTeams(org string) ([]*git.Team, error) {
+	return nil, fmt.Errorf("list teams: %w", git.ErrNotSupported)
+}
+
 func BitbucketRepositoryToGitRepository(bRepo bitbucket.Repository) *git.Repository {
+	return toGitRepository(bRepo, true)
+}
+
+func (b *CloudProvider) toGitRepository(bRepo bitbucket.Repository) *git.Repository {
+	return toGitRepository(bRepo, b.Options.AppendGitSuffix)
+}
+
+func toGitRepository(bRepo bitbucket.Repository, appendGitSuffix bool) *git.Repository {
 	var sshURL string
 	var httpCloneURL string
 	for _, link := range bRepo.Links.Clone {
@@ -103,7 +146,7 @@ func BitbucketRepositoryToGitRepository(bRepo bitbucket.Repository) *git.Reposit
 	}
 	if httpCloneURL == "" {
 		httpCloneURL = bRepo.Links.Html.Href
-		if !strings.HasSuffix(httpCloneURL, ".git") {
+		if appendGitSuffix && !strings.HasSuffix(httpCloneURL, ".git") {
 			httpCloneURL += ".git"
 		}
 	}
@@ -120,36 +163,61 @@ func BitbucketRepositoryToGitRepository(bRepo bitbucket.Repository) *git.Reposit
 	}
 }
 
+// cloudRepository is a repository with its main branch, which the client doesn't decode
+type cloudRepository struct {
+	bitbucket.Repository
+	Mainbranch *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+func (b *CloudProvider) fromCloudRepository(repo cloudRepository) *git.Repository {
+	answer := b.toGitRepository(repo.Repository)
+	if repo.Mainbranch != nil {
+		answer.DefaultBranch = repo.Mainbranch.Name
+	}
+	return answer
+}
+
+// ListRepositories lists the repositories directly, as the client doesn't decode their main branch
 func (b *CloudProvider) ListRepositories(org string) ([]*git.Repository, error) {
 
 	repos := []*git.Repository{}
 
-	var results bitbucket.PaginatedRepositories
-	var err error
-
-	for {
-		if results.Next == "" {
-			results, _, err = b.Client.RepositoriesApi.RepositoriesUsernameGet(b.Context, org, nil)
-		} else {
-			results, _, err = b.Client.PagingApi.RepositoriesPageGet(b.Context, results.Next)
+	err := paginate(func(next string) (string, error) {
+		u := next
+		if u == "" {
+			u = util.UrlJoin(b.apiURL, "repositories", org)
 		}
-
+		results := struct {
+			Values []cloudRepository `json:"values"`
+			Next   string            `json:"next"`
+		}{}
+		err := b.getJSON(u, &results)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		for _, repo := range results.Values {
-			repos = append(repos, BitbucketRepositoryToGitRepository(repo))
-		}
-
-		if results.Next == "" {
-			break
+			repos = append(repos, b.fromCloudRepository(repo))
 		}
+		return results.Next, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return repos, nil
 }
 
+func (b *CloudProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	return nil, fmt.Errorf("list repositories paged: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	return b.ListRepositories(b.Username)
+}
+
 func (b *CloudProvider) CreateRepository(
 	org string,
 	name string,
@@ -158,7 +226,7 @@ func (b *CloudProvider) CreateRepository(
 
 	options := map[string]interface{}{}
 	options["body"] = bitbucket.Repository{
-		IsPrivate: private,
+		IsPrivate: b.Options.IsPrivate(private),
 		Scm:       "git",
 	}
 
@@ -173,7 +241,7 @@ func (b *CloudProvider) CreateRepository(
 		return nil, err
 	}
 
-	return BitbucketRepositoryToGitRepository(result), nil
+	return b.toGitRepository(result), nil
 }
 
 func (b *CloudProvider) GetRepository(
@@ -181,17 +249,18 @@ func (b *CloudProvider) GetRepository(
 	name string,
 ) (*git.Repository, error) {
 
-	repo, _, err := b.Client.RepositoriesApi.RepositoriesUsernameRepoSlugGet(
-		b.Context,
-		org,
-		name,
-	)
+	// the repository is got directly as the client doesn't decode its main branch
+	repo := cloudRepository{}
+	err := b.getJSON(util.UrlJoin(b.apiURL, "repositories", org, name), &repo)
 
+	if isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return BitbucketRepositoryToGitRepository(repo), nil
+	return b.fromCloudRepository(repo), nil
 }
 
 func (b *CloudProvider) DeleteRepository(org string, name string) error {
@@ -237,24 +306,25 @@ func (b *CloudProvider) ForkRepository(
 
 	// Fork isn't ready
 	if err != nil {
-
-		// Wait up to 1 minute for the fork to be ready
-		for i := 0; i < 30; i++ {
-			_, _, err = b.Client.RepositoriesApi.RepositoriesUsernameRepoSlugForksGet(
+		err = b.ForkPollConfig.Poll(b.Options, func() error {
+			_, _, err := b.Client.RepositoriesApi.RepositoriesUsernameRepoSlugForksGet(
 				b.Context,
 				originalOrg,
 				repo.Name,
 			)
-
-			if err == nil {
-				break
-			}
-
-			time.Sleep(2 * time.Second)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Gave up waiting for the fork of %s/%s to be ready: %s", originalOrg, name, err)
 		}
 	}
 
-	return BitbucketRepositoryToGitRepository(repo), nil
+	return b.toGitRepository(repo), nil
+}
+
+// SetForkPollConfig sets how often ForkRepository checks whether the fork is ready and how long it waits for it
+func (b *CloudProvider) SetForkPollConfig(config git.ForkPollConfig) {
+	b.ForkPollConfig = config
 }
 
 func (b *CloudProvider) RenameRepository(
@@ -278,7 +348,7 @@ func (b *CloudProvider) RenameRepository(
 		return nil, err
 	}
 
-	return BitbucketRepositoryToGitRepository(repo), nil
+	return b.toGitRepository(repo), nil
 }
 
 func (b *CloudProvider) ValidateRepositoryName(org string, name string) error {
@@ -300,6 +370,18 @@ func (b *CloudProvider) ValidateRepositoryName(org string, name string) error {
 	return err
 }
 
+func (b *CloudProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	return nil, fmt.Errorf("get merge config: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	return fmt.Errorf("set merge config: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	return fmt.Errorf("set repository topics: %w", git.ErrNotSupported)
+}
+
 func (b *CloudProvider) CreatePullRequest(
 	data *git.PullRequestArguments,
 ) (*git.PullRequest, error) {
@@ -359,7 +441,9 @@ func (b *CloudProvider) CreatePullRequest(
 				break
 			}
 
-			time.Sleep(2 * time.Second)
+			if err := b.Options.Sleep(2 * time.Second); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -439,26 +523,9 @@ func (p *CloudProvider) GetPullRequest(owner string, repoInfo *git.Repository, n
 		return nil, err
 	}
 
-	author := p.UserInfo(pr.Author.Username)
-
-	if author.Email == "" {
-		// bitbucket makes this part difficult, there is no way to directly
-		// associate a username to an email through the API or vice versa
-		// so our best attempt is to try to figure out the author email
-		// from the commits
-		commits, err := p.GetPullRequestCommits(owner, repoInfo, number)
-
-		if err != nil {
-			log.Warn("Unable to get commits for PR: " + owner + "/" + repo + "/" + strconv.Itoa(number) + " -- " + err.Error())
-		}
-
-		// we get correct login and email per commit, find the matching author
-		for _, commit := range commits {
-			if commit.Author.Login == author.Login {
-				author.Email = commit.Author.Email
-				break
-			}
-		}
+	author, err := p.pullRequestAuthor(owner, repo, number, pr.Author.Username)
+	if err != nil {
+		log.Warn("Unable to get commits for PR: " + owner + "/" + repo + "/" + strconv.Itoa(number) + " -- " + err.Error())
 	}
 
 	return &git.PullRequest{
@@ -471,6 +538,137 @@ func (p *CloudProvider) GetPullRequest(owner string, repoInfo *git.Repository, n
 	}, nil
 }
 
+// pullRequestAuthor returns the user info of the author of the pull request. Bitbucket makes this part
+// difficult, there is no way to directly associate a username to an email through the API or vice versa,
+// so when the user info has no email our best attempt is the email of the first commit of the pull request
+// made by the author. The commits are read a page at a time, and only fetched one at a time when a page
+// doesn't include their author, stopping at the first match
+func (b *CloudProvider) pullRequestAuthor(owner string, repo string, number int, login string) (*git.User, error) {
+	author := b.UserInfo(login)
+	if author == nil {
+		author = &git.User{Login: login}
+	}
+	if author.Email != "" {
+		return author, nil
+	}
+
+	err := paginate(func(next string) (string, error) {
+		commits := map[string]interface{}{}
+		var err error
+		if next == "" {
+			// for some reason the 2nd parameter is the PR id, seems like an inconsistency/bug in the api
+			commits, _, err = b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdCommitsGet(b.Context, owner, strconv.Itoa(number), repo)
+		} else {
+			err = b.getJSON(next, &commits)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		commitValues, ok := commits["values"].([]interface{})
+		if !ok {
+			return "", fmt.Errorf("No commitValues for %s/%s/%d", owner, repo, number)
+		}
+
+		for _, data := range commitValues {
+			comm, ok := data.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			commitLogin, raw := "", ""
+			if commitAuthor, ok := comm["author"].(map[string]interface{}); ok {
+				raw, _ = commitAuthor["raw"].(string)
+				if user, ok := commitAuthor["user"].(map[string]interface{}); ok {
+					commitLogin, _ = user["username"].(string)
+				}
+			}
+
+			if raw == "" {
+				sha, ok := comm["hash"].(string)
+				if !ok {
+					continue
+				}
+				commit, _, err := b.Client.CommitsApi.RepositoriesUsernameRepoSlugCommitRevisionGet(b.Context, owner, repo, sha)
+				if err != nil {
+					return "", err
+				}
+				if commit.Author == nil {
+					continue
+				}
+				raw = commit.Author.Raw
+				if commit.Author.User != nil {
+					commitLogin = commit.Author.User.Username
+				}
+			}
+
+			if commitLogin == login {
+				// raw contains the Git commit author in the form: User <email@example.com>
+				author.Email = rawEmailMatcher.ReplaceAllString(raw, "$1")
+				return "", nil
+			}
+		}
+		next, _ = commits["next"].(string)
+		return next, nil
+	})
+	return author, err
+}
+
+// getJSON decodes the page at the given URL, such as the next link of a page the client can't follow
+func (b *CloudProvider) getJSON(u string, v interface{}) error {
+	return b.do(http.MethodGet, u, nil, v)
+}
+
+// do sends a request to an API the client doesn't support, encoding body as JSON unless it is nil and
+// decoding the response into v unless it is nil
+func (b *CloudProvider) do(method string, u string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(b.Username, b.token)
+
+	resp, err := b.Options.NewHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return &responseError{method: method, url: u, status: resp.Status, StatusCode: resp.StatusCode}
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// responseError is returned by do when the API answers with an error status
+type responseError struct {
+	method     string
+	url        string
+	status     string
+	StatusCode int
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s %s returned %s", e.method, e.url, e.status)
+}
+
+// isStatus returns true if the error is a response of the API with the status code
+func isStatus(err error, statusCode int) bool {
+	respErr, ok := err.(*responseError)
+	return ok && respErr.StatusCode == statusCode
+}
+
 func (b *CloudProvider) GetPullRequestCommits(owner string, repository *git.Repository, number int) ([]*git.Commit, error) {
 	repo := repository.Name
 	answer := []*git.Commit{}
@@ -491,8 +689,6 @@ func (b *CloudProvider) GetPullRequestCommits(owner string, repository *git.Repo
 		return answer, fmt.Errorf("No commitValues for %s/%s/%d", owner, repo, number)
 	}
 
-	rawEmailMatcher, _ := regexp.Compile("[^<]*<([^>]+)>")
-
 	for _, data := range commitValues {
 		if data == nil {
 			continue
@@ -549,60 +745,154 @@ func (b *CloudProvider) GetPullRequestCommits(owner string, repository *git.Repo
 
 		answer = append(answer, summary)
 	}
+
+	// look the authors up together rather than one for each commit
+	logins := []string{}
+	for _, commit := range answer {
+		if commit.Author.Login != "" {
+			logins = append(logins, commit.Author.Login)
+		}
+	}
+	users, err := b.GetUsers(logins)
+	if err != nil {
+		return answer, err
+	}
+	for _, commit := range answer {
+		if user, ok := users[commit.Author.Login]; ok {
+			author := *user
+			author.Email = commit.Author.Email
+			commit.Author = &author
+		}
+	}
 	return answer, nil
 }
 
-func (b *CloudProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
+// pullRequestStates are the bitbucket pull request states listed for each state of git pull requests
+var pullRequestStates = map[string][]string{
+	git.PullRequestStateOpen:   {"OPEN"},
+	git.PullRequestStateClosed: {"MERGED", "DECLINED", "SUPERSEDED"},
+	git.PullRequestStateAll:    {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"},
+}
 
-	latestCommitStatus := bitbucket.Commitstatus{}
+func (b *CloudProvider) ListPullRequests(owner string, repository *git.Repository, state string) ([]*git.PullRequest, error) {
+	repo := repository.Name
+	states, ok := pullRequestStates[state]
+	if !ok {
+		return nil, fmt.Errorf("invalid pull request state %s", state)
+	}
 
-	var result bitbucket.PaginatedCommitstatuses
-	var err error
+	answer := []*git.PullRequest{}
+	for _, bState := range states {
+		err := paginate(func(next string) (string, error) {
+			var results bitbucket.PaginatedPullrequests
+			var err error
+			if next == "" {
+				results, _, err = b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsGet(b.Context, owner, repo, map[string]interface{}{"state": bState})
+			} else {
+				results, _, err = b.Client.PagingApi.PullrequestsPageGet(b.Context, next)
+			}
 
-	for {
-		if result.Next == "" {
-			result, _, err = b.Client.CommitstatusesApi.RepositoriesUsernameRepoSlugCommitNodeStatusesGet(
-				b.Context,
-				pr.Owner,
-				pr.Repo,
-				pr.LastCommitSha,
-			)
-		} else {
-			result, _, err = b.Client.PagingApi.CommitstatusesPageGet(b.Context, result.Next)
-		}
+			if err != nil {
+				return "", err
+			}
 
+			for _, pr := range results.Values {
+				answer = append(answer, toPullRequest(owner, repo, pr))
+			}
+			return results.Next, nil
+		})
 		if err != nil {
-			return "", err
+			return nil, err
 		}
+	}
+	return answer, nil
+}
 
-		// Our first time building, so return "success"
-		if result.Size == 0 {
-			return "success", nil
-		}
+// ListPullRequestsByAuthor filters the pull requests of the repository as the client can't query them by author
+func (b *CloudProvider) ListPullRequestsByAuthor(owner string, repository *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	prs, err := b.ListPullRequests(owner, repository, state)
+	if err != nil {
+		return nil, err
+	}
+	return git.FilterPullRequestsByAuthor(prs, author), nil
+}
 
-		for _, status := range result.Values {
-			if status.CreatedOn.After(latestCommitStatus.CreatedOn) {
-				latestCommitStatus = status
-			}
-		}
+// GetPullRequestByBranch looks through the open pull requests as the client can't query them by branch
+func (b *CloudProvider) GetPullRequestByBranch(owner string, repository *git.Repository, branch string) (*git.PullRequest, error) {
+	prs, err := b.ListPullRequests(owner, repository, git.PullRequestStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindPullRequestByBranch(prs, branch), nil
+}
 
-		if result.Next == "" {
-			break
+// toPullRequest converts a pull request from a list, which doesn't include its description or author details
+func toPullRequest(owner string, repo string, pr bitbucket.Pullrequest) *git.PullRequest {
+	number := int(pr.Id)
+	state := pr.State
+	merged := state == "MERGED"
+	answer := &git.PullRequest{
+		URL:    pr.Links.Html.Href,
+		Owner:  owner,
+		Repo:   repo,
+		Number: &number,
+		State:  &state,
+		Merged: &merged,
+		Title:  pr.Title,
+	}
+	if pr.Author != nil {
+		answer.Author = &git.User{
+			Login: pr.Author.Username,
 		}
 	}
+	if pr.Source != nil {
+		if pr.Source.Branch != nil {
+			answer.HeadRef = &pr.Source.Branch.Name
+		}
+		if pr.Source.Commit != nil {
+			answer.LastCommitSha = pr.Source.Commit.Hash
+		}
+	}
+	if pr.Destination != nil && pr.Destination.Branch != nil {
+		answer.BaseRef = &pr.Destination.Branch.Name
+	}
+	return answer
+}
+
+// ClosePullRequest declines the pull request, which is how bitbucket closes it without merging
+func (b *CloudProvider) ClosePullRequest(pr *git.PullRequest) error {
+	_, _, err := b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdDeclinePost(
+		b.Context,
+		pr.Owner,
+		strconv.FormatInt(int64(*pr.Number), 10),
+		pr.Repo,
+	)
+
+	if err != nil {
+		return err
+	}
 
-	return stateMap[latestCommitStatus.State], nil
+	declined := "DECLINED"
+	pr.State = &declined
+	return nil
+}
+
+func (b *CloudProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
+	statuses, err := b.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
+	if err != nil {
+		return "", err
+	}
+	return git.OverallState(statuses), nil
 }
 
 func (b *CloudProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
 
 	statuses := []*git.RepoStatus{}
 
-	var result bitbucket.PaginatedCommitstatuses
-	var err error
-
-	for {
-		if result.Next == "" {
+	err := paginate(func(next string) (string, error) {
+		var result bitbucket.PaginatedCommitstatuses
+		var err error
+		if next == "" {
 			result, _, err = b.Client.CommitstatusesApi.RepositoriesUsernameRepoSlugCommitNodeStatusesGet(
 				b.Context,
 				org,
@@ -610,38 +900,44 @@ func (b *CloudProvider) ListCommitStatus(org string, repo string, sha string) ([
 				sha,
 			)
 		} else {
-			result, _, err = b.Client.PagingApi.CommitstatusesPageGet(b.Context, result.Next)
+			result, _, err = b.Client.PagingApi.CommitstatusesPageGet(b.Context, next)
 		}
 
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		for _, status := range result.Values {
-
-			if err != nil {
-				return nil, err
-			}
-
+			createdOn := status.CreatedOn
 			newStatus := &git.RepoStatus{
 				ID:          status.Key,
+				Context:     status.Key,
 				URL:         status.Links.Commit.Href,
 				State:       stateMap[status.State],
 				TargetURL:   status.Links.Self.Href,
 				Description: status.Description,
+				CreatedAt:   &createdOn,
 			}
 			statuses = append(statuses, newStatus)
 		}
-
-		if result.Next == "" {
-			break
-		}
+		return result.Next, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return statuses, nil
 }
 
+func (b *CloudProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	statuses, err := b.ListCommitStatus(org, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindRepoStatus(statuses, context), nil
+}
+
 func (b *CloudProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
-	return &git.RepoStatus{}, errors.New("TODO")
+	return nil, fmt.Errorf("update commit status: %w", git.ErrNotSupported)
 }
 
 func (b *CloudProvider) MergePullRequest(pr *git.PullRequest, message string) error {
@@ -670,6 +966,9 @@ func (b *CloudProvider) MergePullRequest(pr *git.PullRequest, message string) er
 }
 
 func (b *CloudProvider) CreateWebHook(data *git.WebhookArguments) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
 
 	options := map[string]interface{}{
 		"body": map[string]interface{}{
@@ -704,12 +1003,46 @@ func (p *CloudProvider) UpdateWebHook(data *git.WebhookArguments) error {
 	return fmt.Errorf("not implemented!")
 }
 
+func (p *CloudProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	return nil, fmt.Errorf("list webhook deliveries: %w", git.ErrNotSupported)
+}
+
+func (p *CloudProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return fmt.Errorf("redeliver webhook: %w", git.ErrNotSupported)
+}
+
+func (p *CloudProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	return fmt.Errorf("ping webhook: %w", git.ErrNotSupported)
+}
+
+// issueStateMap maps the states of Bitbucket issues to open or closed
+var issueStateMap = map[string]string{
+	"new":       "open",
+	"open":      "open",
+	"on hold":   "open",
+	"resolved":  "closed",
+	"invalid":   "closed",
+	"duplicate": "closed",
+	"wontfix":   "closed",
+	"closed":    "closed",
+}
+
+// normalizeIssueState returns open or closed for a Bitbucket issue state. Unknown states are
+// treated as open
+func normalizeIssueState(state string) string {
+	if normalized, ok := issueStateMap[strings.ToLower(state)]; ok {
+		return normalized
+	}
+	return "open"
+}
+
 func BitbucketIssueToIssue(bIssue bitbucket.Issue) *git.Issue {
 	id := int(bIssue.Id)
 	ownerAndRepo := strings.Split(bIssue.Repository.FullName, "/")
 	owner := ownerAndRepo[0]
 
 	var assignee git.User
+	state := normalizeIssueState(bIssue.State)
 
 	if bIssue.Assignee != nil {
 		assignee = git.User{
@@ -725,15 +1058,18 @@ func BitbucketIssueToIssue(bIssue bitbucket.Issue) *git.Issue {
 		Number:    &id,
 		Title:     bIssue.Title,
 		Body:      bIssue.Content.Markup,
-		State:     &bIssue.State,
+		State:     &state,
 		IssueURL:  &bIssue.Links.Html.Href,
 		CreatedAt: &bIssue.CreatedOn,
 		UpdatedAt: &bIssue.UpdatedOn,
-		ClosedAt:  &bIssue.UpdatedOn,
 		Assignees: []git.User{
 			assignee,
 		},
 	}
+	// bitbucket has no closed date so the last update is the best guess, but only while the issue is closed
+	if state == "closed" {
+		gitIssue.ClosedAt = &bIssue.UpdatedOn
+	}
 	return gitIssue
 }
 
@@ -752,30 +1088,29 @@ func (b *CloudProvider) SearchIssues(org string, name string, query string) ([]*
 
 	gitIssues := []*git.Issue{}
 
-	var issues bitbucket.PaginatedIssues
-	var err error
-
-	for {
-		if issues.Next == "" {
+	err := paginate(func(next string) (string, error) {
+		var issues bitbucket.PaginatedIssues
+		var err error
+		if next == "" {
 			issues, _, err = b.Client.IssueTrackerApi.RepositoriesUsernameRepoSlugIssuesGet(b.Context, org, name)
 		} else {
-			issues, _, err = b.Client.PagingApi.IssuesPageGet(b.Context, issues.Next)
+			issues, _, err = b.Client.PagingApi.IssuesPageGet(b.Context, next)
 		}
 
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		for _, issue := range issues.Values {
 			gitIssues = append(gitIssues, BitbucketIssueToIssue(issue))
 		}
-
-		if issues.Next == "" {
-			break
-		}
+		return issues.Next, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return gitIssues, nil
+	return git.ParseIssueQuery(query).FilterIssues(gitIssues), nil
 }
 
 func (b *CloudProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*git.Issue, error) {
@@ -837,20 +1172,57 @@ func (b *CloudProvider) CreateIssue(owner string, repo string, issue *git.Issue)
 	return BitbucketIssueToIssue(bIssue), nil
 }
 
+// AddPRComment comments on the pull request. The client predates the API for creating pull request
+// comments so it is called directly
 func (b *CloudProvider) AddPRComment(pr *git.PullRequest, comment string) error {
-	log.Warn("Bitbucket Cloud doesn't support adding PR comments via the REST API")
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for PullRequest %#v", pr)
+	}
+	u := util.UrlJoin(b.apiURL, "repositories", pr.Owner, pr.Repo, "pullrequests", strconv.Itoa(*pr.Number), "comments")
+	err := b.postComment(u, comment)
+	if err != nil {
+		return fmt.Errorf("Failed to comment on pull request %s/%s#%d due to: %s", pr.Owner, pr.Repo, *pr.Number, err)
+	}
 	return nil
 }
 
+// postComment posts a comment in markdown to the comments API at the given URL
+func (b *CloudProvider) postComment(u string, comment string) error {
+	body := map[string]interface{}{
+		"content": map[string]string{
+			"raw": comment,
+		},
+	}
+	return b.do(http.MethodPost, u, body, nil)
+}
+
+// CreateIssueComment comments on the issue. Like pull request comments, the client can't create issue
+// comments so the API is called directly
 func (b *CloudProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
-	log.Warn("Bitbucket Cloud doesn't support adding issue comments viea the REST API")
+	u := util.UrlJoin(b.apiURL, "repositories", owner, repo, "issues", strconv.Itoa(number), "comments")
+	err := b.postComment(u, comment)
+	if err != nil {
+		return fmt.Errorf("Failed to comment on issue %s/%s#%d due to: %s", owner, repo, number, err)
+	}
 	return nil
 }
 
+func (b *CloudProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return fmt.Errorf("add labels to issue: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return fmt.Errorf("remove label from issue: %w", git.ErrNotSupported)
+}
+
 func (b *CloudProvider) HasIssues() bool {
 	return true
 }
 
+func (b *CloudProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	return fmt.Errorf("set issues enabled: %w", git.ErrNotSupported)
+}
+
 func (b *CloudProvider) IsGitHub() bool {
 	return false
 }
@@ -872,7 +1244,7 @@ func (b *CloudProvider) IsGerrit() bool {
 }
 
 func (b *CloudProvider) Kind() string {
-	return "bitbucketcloud"
+	return git.KindBitBucketCloud
 }
 
 // Exposed by Jenkins plugin; this one is for https://wiki.jenkins.io/display/JENKINS/BitBucket+Plugin
@@ -893,14 +1265,45 @@ func (b *CloudProvider) BranchArchiveURL(org string, name string, branch string)
 }
 
 func (p *CloudProvider) CurrentUsername() string {
+	if p.Username == "" {
+		user, err := p.GetCurrentUser()
+		if err == nil {
+			p.Username = user.Login
+		}
+	}
 	return p.Username
 }
 
+func (p *CloudProvider) GetCurrentUser() (*git.User, error) {
+	user, _, err := p.Client.UsersApi.UserGet(p.Context)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the authenticated user due to: %s", err)
+	}
+	// the email address is not part of the public profile
+	return &git.User{
+		Login:     user.Username,
+		Name:      user.DisplayName,
+		AvatarURL: user.Links.Avatar.Href,
+		URL:       user.Links.Self.Href,
+	}, nil
+}
+
 func (p *CloudProvider) UserInfo(username string) *git.User {
-	user, _, err := p.Client.UsersApi.UsersUsernameGet(p.Context, username)
+	user, err := p.getUser(username)
 	if err != nil {
 		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
-		return nil
+	}
+	return user
+}
+
+// getUser looks up a user, or returns nil if there is no such user
+func (p *CloudProvider) getUser(username string) (*git.User, error) {
+	user, r, err := p.Client.UsersApi.UsersUsernameGet(p.Context, username)
+	if r != nil && r.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", username, err)
 	}
 
 	return &git.User{
@@ -908,17 +1311,117 @@ func (p *CloudProvider) UserInfo(username string) *git.User {
 		Name:      user.DisplayName,
 		AvatarURL: user.Links.Avatar.Href,
 		URL:       user.Links.Self.Href,
-	}
+	}, nil
+}
+
+func (p *CloudProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return p.users.GetUsers(usernames, p.getUser)
+}
+
+func (b *CloudProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	return nil, fmt.Errorf("list milestones: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	return nil, fmt.Errorf("create milestone: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) CloseMilestone(org string, name string, id int64) error {
+	return fmt.Errorf("close milestone: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	return nil, fmt.Errorf("list contributors: %w", git.ErrNotSupported)
 }
 
 func (b *CloudProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
-	log.Warn("Bitbucket Cloud doesn't support releases")
-	return nil
+	return fmt.Errorf("update release: %w", git.ErrNotSupported)
 }
 
 func (p *CloudProvider) ListReleases(org string, name string) ([]*git.Release, error) {
-	answer := []*git.Release{}
-	log.Warn("Bitbucket Cloud doesn't support releases")
+	return nil, fmt.Errorf("list releases: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	return fmt.Errorf("create branch: %w", git.ErrNotSupported)
+}
+
+// DeleteBranch deletes the branch using the refs API, which the client doesn't support
+func (b *CloudProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := b.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	if branch == repo.DefaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, git.ErrDefaultBranch)
+	}
+	err = b.do(http.MethodDelete, util.UrlJoin(b.apiURL, "repositories", org, name, "refs/branches", branch), nil, nil)
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return errors.Wrapf(err, "failed to delete branch %s of %s/%s", branch, org, name)
+	}
+	return nil
+}
+
+func (b *CloudProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	return fmt.Errorf("create tag: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	return nil, fmt.Errorf("get tag: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	return nil, fmt.Errorf("list repository tags: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	return 0, 0, fmt.Errorf("get ahead behind: %w", git.ErrNotSupported)
+}
+
+// SyncFork merges the upstream branch into the fork using a local clone
+func (b *CloudProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return git.SyncForkWithGit(b, b.Git, b.Options, b.token, org, name, upstreamOrg, upstreamName, branch)
+}
+
+func (b *CloudProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	return nil, fmt.Errorf("list pull request activity: %w", git.ErrNotSupported)
+}
+
+// diffStatPage is a page of the diffstat of a pull request, which has an entry for each file it changes
+type diffStatPage struct {
+	Values []struct {
+		LinesAdded   int `json:"lines_added"`
+		LinesRemoved int `json:"lines_removed"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+func (b *CloudProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	return nil, fmt.Errorf("get pull request approval state: %w", git.ErrNotSupported)
+}
+
+// GetPullRequestStats sums the lines added and removed by each file of the diffstat of the pull request
+func (b *CloudProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	answer := &git.DiffStat{}
+	err := paginate(func(next string) (string, error) {
+		if next == "" {
+			next = util.UrlJoin(b.apiURL, "repositories", pr.Owner, pr.Repo, "pullrequests", strconv.Itoa(*pr.Number), "diffstat")
+		}
+		var page diffStatPage
+		err := b.getJSON(next, &page)
+		if err != nil {
+			return "", err
+		}
+		for _, file := range page.Values {
+			answer.Additions += file.LinesAdded
+			answer.Deletions += file.LinesRemoved
+			answer.ChangedFiles++
+		}
+		return page.Next, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return answer, nil
 }
 
@@ -927,18 +1430,28 @@ func (b *CloudProvider) AddCollaborator(user string, organisation string, repo s
 	return nil
 }
 
-func (b *CloudProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+func (b *CloudProvider) ListInvitations() ([]*git.Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation: %w", git.ErrNotSupported)
 }
 
-func (b *CloudProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return &github.Response{}, nil
+func (b *CloudProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	return nil, fmt.Errorf("get file last commit: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	return nil, fmt.Errorf("list branches containing commit: %w", git.ErrNotSupported)
 }
 
 func (b *CloudProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on bitbucket")
+	return nil, fmt.Errorf("get content: %w", git.ErrNotSupported)
+}
+
+func (b *CloudProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	return "", fmt.Errorf("resolve ref: %w", git.ErrNotSupported)
 }
 
 func (b *CloudProvider) AccessTokenURL() string {
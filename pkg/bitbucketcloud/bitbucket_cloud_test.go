@@ -1,11 +1,15 @@
 package bitbucketcloud
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	bitbucket "github.com/wbrefvem/go-bitbucket"
 	"github.com/wbrefvem/go-gits/pkg/git"
@@ -23,6 +27,10 @@ type BitbucketCloudProviderTestSuite struct {
 	server    *httptest.Server
 	provider  CloudProvider
 	providers map[string]CloudProvider
+	requests  []string
+
+	// comment is the last body posted to a comments API
+	comment map[string]interface{}
 }
 
 const (
@@ -68,6 +76,12 @@ var bitbucketRouter = util.Router{
 	"/repositories/test-user/test-repo/pullrequests/3/": util.MethodMap{
 		"GET": "pullrequests.test-repo-closed.json",
 	},
+	"/repositories/test-user/test-repo/pullrequests/5/": util.MethodMap{
+		"GET": "pullrequests.test-repo-closed.json",
+	},
+	"/repositories/test-user/test-repo/pullrequests/6/": util.MethodMap{
+		"GET": "pullrequests.test-repo.json",
+	},
 	"/repositories/test-org/test-repo/pullrequests/4/": util.MethodMap{
 		"GET": "pullrequests.test-org.test-repo-closed.json",
 	},
@@ -77,6 +91,9 @@ var bitbucketRouter = util.Router{
 	"/repositories/test-user/test-repo/pullrequests/3/commits": util.MethodMap{
 		"GET": "pullrequests.test-repo.commits.json",
 	},
+	"/repositories/test-user/test-repo/pullrequests/5/commits": util.MethodMap{
+		"GET": "pullrequests.test-repo.5.commits.json",
+	},
 	"/repositories/test-org/test-repo/pullrequests/4/commits": util.MethodMap{
 		"GET": "pullrequests.test-org.test-repo.commits.json",
 	},
@@ -102,6 +119,9 @@ var bitbucketRouter = util.Router{
 	"/repositories/test-user/test-repo/issues/1": util.MethodMap{
 		"GET": "issues.test-repo.issue-1.json",
 	},
+	"/user": util.MethodMap{
+		"GET": "users.test-user.json",
+	},
 	"/users/test-user": util.MethodMap{
 		"GET": "users.test-user.json",
 	},
@@ -110,7 +130,7 @@ var bitbucketRouter = util.Router{
 func setupGitProvider(url, name, user string) (git.Provider, error) {
 
 	cli := git.NewGitCLI()
-	bp, err := NewBitbucketCloudProvider(user, url, "", "bitbucketcloud", cli)
+	bp, err := NewProvider(user, url, "", "bitbucketcloud", cli)
 
 	return bp, err
 }
@@ -122,7 +142,40 @@ func (suite *BitbucketCloudProviderTestSuite) SetupSuite() {
 		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/bitbucket_cloud", methodMap))
 	}
 
-	suite.server = httptest.NewServer(suite.mux)
+	createComment := func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.comment = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.comment))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 101}`)
+	}
+	suite.mux.HandleFunc("/repositories/test-user/test-repo/pullrequests/3/comments", createComment)
+	suite.mux.HandleFunc("/repositories/test-user/test-repo/issues/1/comments", createComment)
+
+	// only the feature branch exists
+	suite.mux.HandleFunc("/repositories/test-user/test-repo/refs/branches/", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		if !strings.HasSuffix(r.URL.Path, "/refs/branches/feature") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// the diffstat of the pull request has a file on each page
+	suite.mux.HandleFunc("/repositories/test-user/test-repo/pullrequests/3/diffstat", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values": [{"status": "modified", "lines_added": 0, "lines_removed": 1}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"status": "modified", "lines_added": 2, "lines_removed": 1}],
+			"next": "%s/repositories/test-user/test-repo/pullrequests/3/diffstat?page=2"}`, suite.server.URL)
+	})
+
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.requests = append(suite.requests, r.URL.Path)
+		suite.mux.ServeHTTP(w, r)
+	}))
 	suite.Require().NotNil(suite.server)
 
 	cfg := bitbucket.NewConfiguration()
@@ -143,6 +196,7 @@ func (suite *BitbucketCloudProviderTestSuite) SetupSuite() {
 		suite.Require().NotNil(bp)
 		suite.Require().True(ok)
 		bp.Client = clientSingleton
+		bp.apiURL = suite.server.URL
 
 		suite.providers[profile.username] = *bp
 	}
@@ -162,10 +216,18 @@ func (suite *BitbucketCloudProviderTestSuite) TestListRepositories() {
 
 		for _, repo := range repos {
 			suite.Require().NotNil(repo)
+			suite.Require().Equal("master", repo.DefaultBranch)
 		}
 	}
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestListRepositoriesForCurrentUser() {
+	repos, err := suite.provider.ListRepositoriesForCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(len(repos), 2)
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestGetRepository() {
 
 	repo, err := suite.provider.GetRepository(
@@ -177,6 +239,7 @@ func (suite *BitbucketCloudProviderTestSuite) TestGetRepository() {
 	suite.Require().Nil(err)
 
 	suite.Require().Equal(repo.Name, "test-repo")
+	suite.Require().Equal("master", repo.DefaultBranch)
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestDeleteRepository() {
@@ -316,6 +379,79 @@ func (suite *BitbucketCloudProviderTestSuite) TestGetPullRequest() {
 
 	suite.Require().Nil(err)
 	suite.Require().Equal(*pr.Number, 3)
+	suite.Require().Equal("test-user@gmail.com", pr.Author.Email)
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestDeleteBranch() {
+	suite.requests = nil
+
+	err := suite.provider.DeleteBranch("test-user", "test-repo", "feature")
+	suite.Require().Nil(err)
+	suite.Require().Contains(suite.requests, "/repositories/test-user/test-repo/refs/branches/feature")
+
+	// a branch which is already gone is fine
+	err = suite.provider.DeleteBranch("test-user", "test-repo", "deleted")
+	suite.Require().Nil(err)
+
+	err = suite.provider.DeleteBranch("test-user", "test-repo", "master")
+	suite.Require().True(git.IsDefaultBranch(err))
+
+	err = suite.provider.DeleteBranch("test-user", "missing-repo", "feature")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestGetPullRequestStopsAtAuthorCommit() {
+	suite.requests = nil
+
+	pr, err := suite.provider.GetPullRequest(
+		"test-user",
+		&git.Repository{Name: "test-repo"},
+		5,
+	)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("test-user@gmail.com", pr.Author.Email)
+	// the first commit is by the author so the second is never fetched
+	suite.Require().Contains(suite.requests, "/repositories/test-user/test-repo/commit/bbc7b863a56144647a806646b73e3b43749decad")
+	suite.Require().NotContains(suite.requests, "/repositories/test-user/test-repo/commit/7793466f879b83f1bdd8f3fc3f761bc3cb61bc41")
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestGetPullRequestFollowsCommitPages() {
+	pages := []string{}
+	suite.mux.HandleFunc("/repositories/test-user/test-repo/pullrequests/6/commits", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		switch page {
+		case "":
+			fmt.Fprintf(w, `{"values": [{"hash": "a1", "author": {"raw": "Other User <other-user@example.com>", "user": {"username": "other-user"}}}],
+				"next": "%s/repositories/test-user/test-repo/pullrequests/6/commits?page=2"}`, suite.server.URL)
+		case "2":
+			fmt.Fprintf(w, `{"values": [{"hash": "a2", "author": {"raw": "Test User <test-user@example.com>", "user": {"username": "test-user"}}}],
+				"next": "%s/repositories/test-user/test-repo/pullrequests/6/commits?page=3"}`, suite.server.URL)
+		default:
+			fmt.Fprint(w, `{"values": []}`)
+		}
+	})
+
+	pr, err := suite.provider.GetPullRequest(
+		"test-user",
+		&git.Repository{Name: "test-repo"},
+		6,
+	)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("test-user@example.com", pr.Author.Email)
+	// the author is found on the second page so the third is never fetched
+	suite.Require().Equal([]string{"", "2"}, pages)
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("test-user", user.Login)
+	suite.Require().Equal("Test User", user.Name)
+	suite.Require().Equal("https://api.bitbucket.org/2.0/users/test-user", user.URL)
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestPullRequestCommits() {
@@ -324,6 +460,9 @@ func (suite *BitbucketCloudProviderTestSuite) TestPullRequestCommits() {
 	suite.Require().Nil(err)
 	suite.Require().Equal(len(commits), 2)
 	suite.Require().Equal(commits[0].Author.Email, "test-user@gmail.com")
+	// the author is looked up with the users API
+	suite.Require().Equal(username, commits[0].Author.Login)
+	suite.Require().Equal("https://api.bitbucket.org/2.0/users/test-user", commits[0].Author.URL)
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestPullRequestLastCommitStatus() {
@@ -337,7 +476,7 @@ func (suite *BitbucketCloudProviderTestSuite) TestPullRequestLastCommitStatus()
 
 	suite.Require().Nil(err)
 	suite.Require().NotEmpty(lastCommitStatus)
-	suite.Require().Equal(lastCommitStatus, "in-progress")
+	suite.Require().Equal(lastCommitStatus, "pending")
 }
 
 func (suite *BitbucketCloudProviderTestSuite) testStatuses(statuses []*git.RepoStatus, err error) {
@@ -356,6 +495,20 @@ func (suite *BitbucketCloudProviderTestSuite) testStatuses(statuses []*git.RepoS
 	}
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestGetCommitStatus() {
+	status, err := suite.provider.GetCommitStatus("test-user", "test-repo", "5c8afc5", "626bb1b3")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("626bb1b3", status.Context)
+	suite.Require().Equal("in-progress", status.State)
+
+	status, err = suite.provider.GetCommitStatus("test-user", "test-repo", "5c8afc5", "missing")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(status)
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestListCommitStatus() {
 	statuses, err := suite.provider.ListCommitStatus("test-user", "test-repo", "5c8afc5")
 	suite.testStatuses(statuses, err)
@@ -388,6 +541,13 @@ func (suite *BitbucketCloudProviderTestSuite) TestCreateWebHook() {
 	suite.Require().Nil(err)
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestKind() {
+	suite.Require().Equal(git.KindBitBucketCloud, suite.provider.Kind())
+	suite.Require().Contains(git.KindGits, suite.provider.Kind())
+	suite.Require().True(suite.provider.IsBitbucketCloud())
+	suite.Require().False(suite.provider.IsBitbucketServer())
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestSearchIssues() {
 	issues, err := suite.provider.SearchIssues("test-user", "test-repo", "")
 
@@ -396,6 +556,9 @@ func (suite *BitbucketCloudProviderTestSuite) TestSearchIssues() {
 
 	for _, issue := range issues {
 		suite.Require().NotNil(issue)
+		// the issues are all new so they have never been closed
+		suite.Require().Equal("open", *issue.State)
+		suite.Require().Nil(issue.ClosedAt)
 	}
 }
 
@@ -419,19 +582,66 @@ func (suite *BitbucketCloudProviderTestSuite) TestCreateIssue() {
 	suite.Require().NotNil(issue)
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestGetPullRequestStats() {
+	number := 3
+	pr := &git.PullRequest{
+		Owner:  "test-user",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+
+	stats, err := suite.provider.GetPullRequestStats(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.DiffStat{Additions: 2, Deletions: 2, ChangedFiles: 2}, stats)
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestAddPRComment() {
-	err := suite.provider.AddPRComment(nil, "")
+	number := 3
+	pr := &git.PullRequest{
+		Owner:  "test-user",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+
+	err := suite.provider.AddPRComment(pr, "Preview environment is ready")
+
 	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"raw": "Preview environment is ready"}, suite.comment["content"])
+
+	// there are no comments to post to on a missing pull request
+	number = 99
+	err = suite.provider.AddPRComment(pr, "Preview environment is ready")
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "404")
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestCreateIssueComment() {
-	err := suite.provider.CreateIssueComment("", "", 0, "")
+	err := suite.provider.CreateIssueComment("test-user", "test-repo", 1, "Fixed in version 1.0.3")
+
 	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"raw": "Fixed in version 1.0.3"}, suite.comment["content"])
+
+	err = suite.provider.CreateIssueComment("test-user", "test-repo", 99, "Fixed in version 1.0.3")
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "404")
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestUpdateRelease() {
 	err := suite.provider.UpdateRelease("", "", "", nil)
-	suite.Require().Nil(err)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestListReleases() {
+	releases, err := suite.provider.ListReleases("", "")
+	suite.Require().Nil(releases)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *BitbucketCloudProviderTestSuite) TestUpdateCommitStatus() {
+	status, err := suite.provider.UpdateCommitStatus("test-user", "test-repo", "6dcb09b5", &git.RepoStatus{State: "success"})
+	suite.Require().Nil(status)
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestUserInfo() {
@@ -446,16 +656,32 @@ func (suite *BitbucketCloudProviderTestSuite) TestAddCollaborator() {
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
-	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	_, err := suite.provider.ListInvitations()
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	err := suite.provider.AcceptInvitation(1)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func TestNormalizeIssueState(t *testing.T) {
+	t.Parallel()
+	states := map[string]string{
+		"new":       "open",
+		"open":      "open",
+		"on hold":   "open",
+		"resolved":  "closed",
+		"invalid":   "closed",
+		"duplicate": "closed",
+		"wontfix":   "closed",
+		"closed":    "closed",
+		"RESOLVED":  "closed",
+		"unknown":   "open",
+	}
+	for state, expected := range states {
+		assert.Equal(t, expected, normalizeIssueState(state), "state %s", state)
+	}
 }
 
 func TestBitbucketCloudProviderTestSuite(t *testing.T) {
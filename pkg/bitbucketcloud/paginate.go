@@ -0,0 +1,17 @@
+package bitbucketcloud
+
+// paginate calls fetchPage with the URL of each page, starting with a blank URL for the first page, until a
+// page has no next page. It also stops if a page links to itself so a bad next link can't loop forever
+func paginate(fetchPage func(next string) (string, error)) error {
+	next := ""
+	for {
+		pageNext, err := fetchPage(next)
+		if err != nil {
+			return err
+		}
+		if pageNext == "" || pageNext == next {
+			return nil
+		}
+		next = pageNext
+	}
+}
@@ -0,0 +1,33 @@
+package bitbucketcloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"":       "page-2",
+		"page-2": "page-3",
+		"page-3": "",
+	}
+	fetched := []string{}
+	err := paginate(func(next string) (string, error) {
+		fetched = append(fetched, next)
+		return pages[next], nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, fetched)
+
+	// a page linking to itself is the last one
+	calls := 0
+	err = paginate(func(next string) (string, error) {
+		calls++
+		return "page-2", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
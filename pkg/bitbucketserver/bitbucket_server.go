@@ -1,16 +1,19 @@
 package bitbucketserver
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
 
 	bitbucket "github.com/gfleury/go-bitbucket-v1"
 	"github.com/jenkins-x/jx/pkg/log"
@@ -26,47 +29,83 @@ type ServerProvider struct {
 	URL      string
 	Name     string
 	Git      git.Gitter
+	Options  git.ProviderOptions
+	// ForkPollConfig is how ForkRepository polls for the fork
+	ForkPollConfig git.ForkPollConfig
+
+	token string
+	// users caches the users looked up by GetUsers
+	users *git.UserCache
+}
+
+// bitbucketForkPollConfig checks for forks every couple of seconds for up to a minute
+var bitbucketForkPollConfig = git.ForkPollConfig{
+	Interval: 2 * time.Second,
+	Timeout:  time.Minute,
 }
 
 type projectsPage struct {
-	Size          int                 `json:"size"`
-	Limit         int                 `json:"limit"`
-	Start         int                 `json:"start"`
-	NextPageStart int                 `json:"nextPageStart"`
-	IsLastPage    bool                `json:"isLastPage"`
-	Values        []bitbucket.Project `json:"values"`
+	Values []bitbucket.Project `json:"values"`
 }
 
 type commitsPage struct {
-	Size          int                `json:"size"`
-	Limit         int                `json:"limit"`
-	Start         int                `json:"start"`
-	NextPageStart int                `json:"nextPageStart"`
-	IsLastPage    bool               `json:"isLastPage"`
-	Values        []bitbucket.Commit `json:"values"`
+	Values []bitbucket.Commit `json:"values"`
 }
 
 type buildStatusesPage struct {
-	Size       int                     `json:"size"`
-	Limit      int                     `json:"limit"`
-	Start      int                     `json:"start"`
-	IsLastPage bool                    `json:"isLastPage"`
-	Values     []bitbucket.BuildStatus `json:"values"`
+	Values []bitbucket.BuildStatus `json:"values"`
 }
 
 type reposPage struct {
-	Size          int                    `json:"size"`
-	Limit         int                    `json:"limit"`
-	Start         int                    `json:"start"`
-	NextPageStart int                    `json:"nextPageStart"`
-	IsLastPage    bool                   `json:"isLastPage"`
-	Values        []bitbucket.Repository `json:"values"`
+	Values []bitbucket.Repository `json:"values"`
+}
+
+type pullRequestsPage struct {
+	Values []bitbucket.PullRequest `json:"values"`
+}
+
+type activityUser struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug"`
+}
+
+type activityComment struct {
+	Text string `json:"text"`
+}
+
+type activityCommit struct {
+	ID string `json:"id"`
+}
+
+type pullRequestActivity struct {
+	ID          int              `json:"id"`
+	CreatedDate int64            `json:"createdDate"`
+	User        activityUser     `json:"user"`
+	Action      string           `json:"action"`
+	Comment     *activityComment `json:"comment"`
+	Commit      *activityCommit  `json:"commit"`
+	FromHash    string           `json:"fromHash"`
+}
+
+type activitiesPage struct {
+	Values []pullRequestActivity `json:"values"`
 }
 
 type pullrequestEndpointBranch struct {
 	Name string `json:"name,omitempty"`
 }
 
+const defaultWebHookName = "Jenkins X Web Hook"
+
+var defaultWebHookEvents = []string{"repo:refs_changed", "repo:modified", "repo:forked", "repo:comment:added", "repo:comment:edited", "repo:comment:deleted", "pr:opened", "pr:reviewer:approved", "pr:reviewer:unapproved", "pr:reviewer:needs_work", "pr:merged", "pr:declined", "pr:deleted", "pr:comment:added", "pr:comment:edited", "pr:comment:deleted"}
+
+var activityActionMap = map[string]string{
+	"DECLINED": "closed",
+	"RESCOPED": "pushed",
+}
+
 var stateMap = map[string]string{
 	"SUCCESSFUL": "success",
 	"FAILED":     "failure",
@@ -74,24 +113,62 @@ var stateMap = map[string]string{
 	"STOPPED":    "stopped",
 }
 
-func NewProvider(username, serverURL, token, providerName string, git git.Gitter) (git.Provider, error) {
-	ctx := context.Background()
-	apiKeyAuthContext := context.WithValue(ctx, bitbucket.ContextAccessToken, token)
+// buildStateMap translates our commit status states to bitbucket build states, it is the reverse of stateMap
+// plus the states bitbucket doesn't have
+var buildStateMap = map[string]string{
+	"pending": "INPROGRESS",
+	"error":   "FAILED",
+}
+
+// buildStatusRequest is the body of a request to the build status API
+type buildStatusRequest struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+func init() {
+	for buildState, state := range stateMap {
+		buildStateMap[state] = buildState
+	}
+
+	git.RegisterProviderFactory(git.KindBitBucketServer, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, git.KindBitBucketServer, gitter, options...)
+	})
+}
+
+func NewProvider(username, serverURL, token, providerName string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	providerOptions := git.NewProviderOptions(options...)
+	apiKeyAuthContext := context.WithValue(providerOptions.RequestContext(), bitbucket.ContextAccessToken, token)
 
 	provider := ServerProvider{
 		Username: username,
 		URL:      serverURL,
 		Context:  apiKeyAuthContext,
-		Git:      git,
+		Git:      gitter,
+		Options:  providerOptions,
+		token:    token,
+		users:    &git.UserCache{},
 	}
+	provider.ForkPollConfig = bitbucketForkPollConfig
 
 	cfg := bitbucket.NewConfiguration(serverURL + "/rest")
+	cfg.HTTPClient = provider.Options.NewHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(apiKeyAuthContext, cfg)
 
 	return &provider, nil
 }
 
 func BitbucketServerRepositoryToGitRepository(bRepo bitbucket.Repository) *git.Repository {
+	return toGitRepository(bRepo, true)
+}
+
+func (b *ServerProvider) toGitRepository(bRepo bitbucket.Repository) *git.Repository {
+	return toGitRepository(bRepo, b.Options.AppendGitSuffix)
+}
+
+func toGitRepository(bRepo bitbucket.Repository, appendGitSuffix bool) *git.Repository {
 	var sshURL string
 	var httpCloneURL string
 	for _, link := range bRepo.Links.Clone {
@@ -107,7 +184,7 @@ func BitbucketServerRepositoryToGitRepository(bRepo bitbucket.Repository) *git.R
 		for _, link := range cloneLinks {
 			if link.Name == "http" {
 				httpCloneURL = link.Href
-				if !strings.HasSuffix(httpCloneURL, ".git") {
+				if appendGitSuffix && !strings.HasSuffix(httpCloneURL, ".git") {
 					httpCloneURL += ".git"
 				}
 			}
@@ -117,12 +194,19 @@ func BitbucketServerRepositoryToGitRepository(bRepo bitbucket.Repository) *git.R
 		httpCloneURL = sshURL
 	}
 
+	var projectKey string
+	if bRepo.Project != nil {
+		projectKey = bRepo.Project.Key
+	}
+
 	return &git.Repository{
-		Name:     bRepo.Name,
-		HTMLURL:  bRepo.Links.Self[0].Href,
-		CloneURL: httpCloneURL,
-		SSHURL:   sshURL,
-		Fork:     isFork,
+		Name:         bRepo.Name,
+		HTMLURL:      bRepo.Links.Self[0].Href,
+		CloneURL:     httpCloneURL,
+		SSHURL:       sshURL,
+		Fork:         isFork,
+		Organisation: projectKey,
+		Project:      projectKey,
 	}
 }
 
@@ -130,6 +214,9 @@ func (b *ServerProvider) GetRepository(org string, name string) (*git.Repository
 	var repo bitbucket.Repository
 	apiResponse, err := b.Client.DefaultApi.GetRepository(org, name)
 
+	if apiResponse != nil && apiResponse.Response.StatusCode == 404 {
+		return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -139,70 +226,122 @@ func (b *ServerProvider) GetRepository(org string, name string) (*git.Repository
 		return nil, err
 	}
 
-	return BitbucketServerRepositoryToGitRepository(repo), nil
+	answer := b.toGitRepository(repo)
+	if answer.Project == "" {
+		answer.Organisation = org
+		answer.Project = org
+	}
+	answer.DefaultBranch, err = b.defaultBranch(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// defaultBranch returns the default branch of the repository, which isn't part of the repository in the API, or
+// an empty string if the repository is empty or the branch can't be looked up. Only transport failures are errors
+// as the repository itself is still readable when the branch isn't
+func (b *ServerProvider) defaultBranch(org string, name string) (string, error) {
+	defaultBranch := struct {
+		DisplayID string `json:"displayId"`
+	}{}
+	u := util.UrlJoin(b.URL, "/rest/api/1.0/projects", org, "repos", name, "branches/default")
+	status, err := b.getJSON(u, &defaultBranch)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the default branch of %s/%s", org, name)
+	}
+	if status >= 300 {
+		if status != http.StatusNotFound {
+			log.Warnf("Unable to get the default branch of %s/%s: %d\n", org, name, status)
+		}
+		return "", nil
+	}
+	return defaultBranch.DisplayID, nil
 }
 
 func (b *ServerProvider) ListOrganisations() ([]git.Organisation, error) {
-	var orgsPage projectsPage
 	orgsList := []git.Organisation{}
 	paginationOptions := make(map[string]interface{})
 
-	paginationOptions["start"] = 0
 	paginationOptions["limit"] = 25
-	for {
+	err := paginate(func(start int) (pageMeta, error) {
+		var orgsPage projectsPage
+		paginationOptions["start"] = start
 		apiResponse, err := b.Client.DefaultApi.GetProjects(paginationOptions)
 		if err != nil {
-			return nil, err
+			return pageMeta{}, err
 		}
 
-		err = mapstructure.Decode(apiResponse.Values, &orgsPage)
+		meta, err := decodePage(apiResponse.Values, &orgsPage)
 		if err != nil {
-			return nil, err
+			return meta, err
 		}
 
 		for _, project := range orgsPage.Values {
 			orgsList = append(orgsList, git.Organisation{Login: project.Key})
 		}
-
-		if orgsPage.IsLastPage {
-			break
-		}
-		paginationOptions["start"] = orgsPage.NextPageStart
+		return meta, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return orgsList, nil
 }
 
-func (b *ServerProvider) ListRepositories(org string) ([]*git.Repository, error) {
-	var reposPage reposPage
-	repos := []*git.Repository{}
-	paginationOptions := make(map[string]interface{})
-
-	paginationOptions["start"] = 0
-	paginationOptions["limit"] = 25
+func (b *ServerProvider) ListTeams(org string) ([]*git.Team, error) {
+	return nil, fmt.Errorf("list teams: %w", git.ErrNotSupported)
+}
 
-	for {
-		apiResponse, err := b.Client.DefaultApi.GetRepositoriesWithOptions(org, paginationOptions)
-		if err != nil {
-			return nil, err
-		}
+func (b *ServerProvider) ListRepositories(org string) ([]*git.Repository, error) {
+	return git.ListAllRepositories(git.ListOptions{PerPage: 25}, func(options git.ListOptions) (*git.RepositoryPage, error) {
+		return b.ListRepositoriesPaged(org, options)
+	})
+}
 
-		err = mapstructure.Decode(apiResponse.Values, &reposPage)
+// ListRepositoriesPaged lists a page of the repositories of a project. The cursor of a page is the start of
+// the page, which pages by number start from too
+func (b *ServerProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	paginationOptions := make(map[string]interface{})
+	if options.PerPage > 0 {
+		paginationOptions["limit"] = options.PerPage
+	}
+	if options.Cursor != "" {
+		start, err := strconv.Atoi(options.Cursor)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid cursor %s: %s", options.Cursor, err)
 		}
+		paginationOptions["start"] = start
+	} else if options.Page > 1 && options.PerPage > 0 {
+		paginationOptions["start"] = (options.Page - 1) * options.PerPage
+	}
 
-		for _, bRepo := range reposPage.Values {
-			repos = append(repos, BitbucketServerRepositoryToGitRepository(bRepo))
-		}
+	apiResponse, err := b.Client.DefaultApi.GetRepositoriesWithOptions(org, paginationOptions)
+	if err != nil {
+		return nil, err
+	}
+	var reposPage reposPage
+	meta, err := decodePage(apiResponse.Values, &reposPage)
+	if err != nil {
+		return nil, err
+	}
 
-		if reposPage.IsLastPage {
-			break
-		}
-		paginationOptions["start"] = reposPage.NextPageStart
+	page := &git.RepositoryPage{
+		Repositories: []*git.Repository{},
+		HasMore:      !meta.IsLastPage && meta.NextPageStart > meta.Start,
+	}
+	for _, bRepo := range reposPage.Values {
+		page.Repositories = append(page.Repositories, b.toGitRepository(bRepo))
 	}
+	if page.HasMore {
+		page.NextCursor = strconv.Itoa(meta.NextPageStart)
+	}
+	return page, nil
+}
 
-	return repos, nil
+// ListRepositoriesForCurrentUser returns the repositories of the personal project of the user, which has the key ~username
+func (b *ServerProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	return b.ListRepositories("~" + b.Username)
 }
 
 func (b *ServerProvider) CreateRepository(org, name string, private bool) (*git.Repository, error) {
@@ -210,7 +349,7 @@ func (b *ServerProvider) CreateRepository(org, name string, private bool) (*git.
 
 	repoRequest := map[string]interface{}{
 		"name":   name,
-		"public": !private,
+		"public": !b.Options.IsPrivate(private),
 	}
 
 	requestBody, err := json.Marshal(repoRequest)
@@ -228,7 +367,7 @@ func (b *ServerProvider) CreateRepository(org, name string, private bool) (*git.
 		return nil, err
 	}
 
-	return BitbucketServerRepositoryToGitRepository(repo), nil
+	return b.toGitRepository(repo), nil
 }
 
 func (b *ServerProvider) DeleteRepository(org, name string) error {
@@ -258,7 +397,7 @@ func (b *ServerProvider) RenameRepository(org, name, newName string) (*git.Repos
 		return nil, err
 	}
 
-	return BitbucketServerRepositoryToGitRepository(repo), nil
+	return b.toGitRepository(repo), nil
 }
 
 func (b *ServerProvider) ValidateRepositoryName(org, name string) error {
@@ -275,6 +414,18 @@ func (b *ServerProvider) ValidateRepositoryName(org, name string) error {
 	return err
 }
 
+func (b *ServerProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	return nil, fmt.Errorf("get merge config: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	return fmt.Errorf("set merge config: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	return fmt.Errorf("set repository topics: %w", git.ErrNotSupported)
+}
+
 func (b *ServerProvider) ForkRepository(originalOrg, name, destinationOrg string) (*git.Repository, error) {
 	var repo bitbucket.Repository
 	var apiResponse *bitbucket.APIResponse
@@ -296,22 +447,17 @@ func (b *ServerProvider) ForkRepository(originalOrg, name, destinationOrg string
 		return nil, err
 	}
 
-	// Wait up to 1 minute for the fork to be ready
-	for i := 0; i < 30; i++ {
-		time.Sleep(2 * time.Second)
-
+	err = b.ForkPollConfig.Poll(b.Options, func() error {
+		var err error
 		if destinationOrg == "" {
 			apiResponse, err = b.Client.DefaultApi.GetUserRepository(b.CurrentUsername(), name)
 		} else {
 			apiResponse, err = b.Client.DefaultApi.GetRepository(destinationOrg, name)
 		}
-
-		if err == nil {
-			break
-		}
-	}
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Gave up waiting for the fork of %s/%s to be ready: %s", originalOrg, name, err)
 	}
 
 	err = mapstructure.Decode(apiResponse.Values, &repo)
@@ -319,11 +465,24 @@ func (b *ServerProvider) ForkRepository(originalOrg, name, destinationOrg string
 		return nil, err
 	}
 
-	return BitbucketServerRepositoryToGitRepository(repo), nil
+	return b.toGitRepository(repo), nil
+}
+
+// SetForkPollConfig sets how often ForkRepository checks whether the fork is ready and how long it waits for it
+func (b *ServerProvider) SetForkPollConfig(config git.ForkPollConfig) {
+	b.ForkPollConfig = config
 }
 
 func (b *ServerProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.PullRequest, error) {
 	var bPullRequest, bPR bitbucket.PullRequest
+
+	projectKey := data.Repository.Project
+	if projectKey == "" {
+		projectKey = data.Repository.Organisation
+	}
+	if projectKey == "" {
+		return nil, fmt.Errorf("missing project key for repository %s", data.Repository.Name)
+	}
 	var options = map[string]interface{}{
 		"title":       data.Title,
 		"description": data.Body,
@@ -335,7 +494,7 @@ func (b *ServerProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 			"repository": map[string]interface{}{
 				"slug": data.Repository.Name,
 				"project": map[string]interface{}{
-					"key": data.Repository.Project,
+					"key": projectKey,
 				},
 			},
 		},
@@ -344,7 +503,7 @@ func (b *ServerProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 			"repository": map[string]interface{}{
 				"slug": data.Repository.Name,
 				"project": map[string]interface{}{
-					"key": data.Repository.Project,
+					"key": projectKey,
 				},
 			},
 		},
@@ -355,7 +514,7 @@ func (b *ServerProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 		return nil, err
 	}
 
-	apiResponse, err := b.Client.DefaultApi.CreatePullRequestWithOptions(data.Repository.Project, data.Repository.Name, requestBody)
+	apiResponse, err := b.Client.DefaultApi.CreatePullRequestWithOptions(projectKey, data.Repository.Name, requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -367,9 +526,11 @@ func (b *ServerProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 
 	// Wait up to 1 minute for the pull request to be ready
 	for i := 0; i < 30; i++ {
-		time.Sleep(2 * time.Second)
+		if err := b.Options.Sleep(2 * time.Second); err != nil {
+			return nil, err
+		}
 
-		apiResponse, err = b.Client.DefaultApi.GetPullRequest(data.Repository.Project, data.Repository.Name, bPullRequest.ID)
+		apiResponse, err = b.Client.DefaultApi.GetPullRequest(projectKey, data.Repository.Name, bPullRequest.ID)
 		if err == nil {
 			break
 		}
@@ -543,39 +704,197 @@ func convertBitBucketCommitToCommit(bCommit *bitbucket.Commit, repo *git.Reposit
 }
 
 func (b *ServerProvider) GetPullRequestCommits(owner string, repository *git.Repository, number int) ([]*git.Commit, error) {
-	var commitsPage commitsPage
 	commits := []*git.Commit{}
 	paginationOptions := make(map[string]interface{})
 
-	paginationOptions["start"] = 0
 	paginationOptions["limit"] = 25
-	for {
+	err := paginate(func(start int) (pageMeta, error) {
+		var commitsPage commitsPage
+		paginationOptions["start"] = start
 		apiResponse, err := b.Client.DefaultApi.GetPullRequestCommitsWithOptions(repository.Project, repository.Name, number, paginationOptions)
 		if err != nil {
-			return nil, err
+			return pageMeta{}, err
 		}
 
-		err = mapstructure.Decode(apiResponse.Values, &commitsPage)
+		meta, err := decodePage(apiResponse.Values, &commitsPage)
 		if err != nil {
-			return nil, err
+			return meta, err
 		}
 
 		for _, commit := range commitsPage.Values {
 			commits = append(commits, convertBitBucketCommitToCommit(&commit, repository))
 		}
+		return meta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if commitsPage.IsLastPage {
-			break
+	return commits, nil
+}
+
+// pullRequestStates are the bitbucket pull request states listed for each state of git pull requests
+var pullRequestStates = map[string][]string{
+	git.PullRequestStateOpen:   {"OPEN"},
+	git.PullRequestStateClosed: {"MERGED", "DECLINED"},
+	git.PullRequestStateAll:    {"ALL"},
+}
+
+func (b *ServerProvider) ListPullRequests(owner string, repository *git.Repository, state string) ([]*git.PullRequest, error) {
+	states, ok := pullRequestStates[state]
+	if !ok {
+		return nil, fmt.Errorf("invalid pull request state %s", state)
+	}
+	projectKey := repository.Project
+	if projectKey == "" {
+		projectKey = owner
+	}
+
+	answer := []*git.PullRequest{}
+	for _, bState := range states {
+		paginationOptions := map[string]interface{}{
+			"state": bState,
+			"limit": 25,
+		}
+		err := paginate(func(start int) (pageMeta, error) {
+			var prsPage pullRequestsPage
+			paginationOptions["start"] = start
+			apiResponse, err := b.Client.DefaultApi.GetPullRequestsPage(projectKey, repository.Name, paginationOptions)
+			if err != nil {
+				return pageMeta{}, err
+			}
+
+			meta, err := decodePage(apiResponse.Values, &prsPage)
+			if err != nil {
+				return meta, err
+			}
+
+			for _, bPR := range prsPage.Values {
+				answer = append(answer, toPullRequest(owner, bPR))
+			}
+			return meta, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		paginationOptions["start"] = commitsPage.NextPageStart
 	}
+	return answer, nil
+}
 
-	return commits, nil
+func (b *ServerProvider) ListPullRequestsByAuthor(owner string, repository *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	states, ok := pullRequestStates[state]
+	if !ok {
+		return nil, fmt.Errorf("invalid pull request state %s", state)
+	}
+	projectKey := repository.Project
+	if projectKey == "" {
+		projectKey = owner
+	}
+
+	// the client can't filter by participant, so page through the API directly
+	answer := []*git.PullRequest{}
+	for _, bState := range states {
+		err := paginate(func(start int) (pageMeta, error) {
+			var page struct {
+				pageMeta
+				pullRequestsPage
+			}
+			query := url.Values{
+				"state":      {bState},
+				"role.1":     {"AUTHOR"},
+				"username.1": {author},
+				"start":      {strconv.Itoa(start)},
+				"limit":      {"25"},
+			}
+			u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests?%s", b.URL, projectKey, repository.Name, query.Encode())
+			status, err := b.getJSON(u, &page)
+			if err != nil {
+				return pageMeta{}, err
+			}
+			if status >= 300 {
+				return pageMeta{}, fmt.Errorf("failed to list the pull requests of %s by %s: %d", repository.Name, author, status)
+			}
+
+			for _, bPR := range page.Values {
+				answer = append(answer, toPullRequest(owner, bPR))
+			}
+			return page.pageMeta, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return answer, nil
+}
+
+// GetPullRequestByBranch looks through the open pull requests as the client can't filter them by branch
+func (b *ServerProvider) GetPullRequestByBranch(owner string, repository *git.Repository, branch string) (*git.PullRequest, error) {
+	prs, err := b.ListPullRequests(owner, repository, git.PullRequestStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindPullRequestByBranch(prs, branch), nil
+}
+
+func toPullRequest(owner string, bPR bitbucket.PullRequest) *git.PullRequest {
+	number := bPR.ID
+	state := bPR.State
+	merged := state == "MERGED"
+	answer := &git.PullRequest{
+		Owner:         owner,
+		Repo:          bPR.ToRef.Repository.Slug,
+		Number:        &number,
+		State:         &state,
+		Merged:        &merged,
+		Title:         bPR.Title,
+		Body:          bPR.Description,
+		HeadRef:       &bPR.FromRef.DisplayID,
+		BaseRef:       &bPR.ToRef.DisplayID,
+		LastCommitSha: bPR.FromRef.LatestCommit,
+		Author: &git.User{
+			Login: bPR.Author.User.Slug,
+			Name:  bPR.Author.User.Name,
+			Email: bPR.Author.User.Email,
+		},
+	}
+	if len(bPR.Links.Self) > 0 {
+		answer.URL = bPR.Links.Self[0].Href
+	}
+	return answer
+}
+
+// ClosePullRequest declines the pull request, which is how bitbucket closes it without merging
+func (b *ServerProvider) ClosePullRequest(pr *git.PullRequest) error {
+	var currentPR bitbucket.PullRequest
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+
+	apiResponse, err := b.Client.DefaultApi.GetPullRequest(projectKey, repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	err = mapstructure.Decode(apiResponse.Values, &currentPR)
+	if err != nil {
+		return err
+	}
+
+	// the client can't decline pull requests so post to the API directly
+	u := util.UrlJoin(b.URL, "/rest/api/1.0/projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(*pr.Number), "decline")
+	resp, err := b.do(http.MethodPost, u+"?version="+strconv.Itoa(currentPR.Version), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decline pull request %d", *pr.Number)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to decline pull request %d: %s", *pr.Number, resp.Status)
+	}
+
+	declined := "DECLINED"
+	pr.State = &declined
+	return nil
 }
 
 func (b *ServerProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
 	var prCommits map[string]interface{}
-	var buildStatusesPage buildStatusesPage
 
 	projectKey, repo := parseBitBucketServerURL(pr.URL)
 	apiResponse, err := b.Client.DefaultApi.GetPullRequestCommits(projectKey, repo, *pr.Number)
@@ -584,64 +903,249 @@ func (b *ServerProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (strin
 	}
 	mapstructure.Decode(apiResponse.Values, &prCommits)
 	lastCommit := getLastCommitFromPRCommits(prCommits)
-	lastCommitSha := lastCommit.ID
 
-	apiResponse, err = b.Client.DefaultApi.GetCommitBuildStatuses(lastCommitSha)
+	statuses, err := b.ListCommitStatus(projectKey, repo, lastCommit.ID)
 	if err != nil {
 		return "", err
 	}
+	return git.OverallState(statuses), nil
+}
+
+func (b *ServerProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	activities := []pullRequestActivity{}
+
+	// the client always asks for the first page of activities, so page through the API directly
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	err := paginate(func(start int) (pageMeta, error) {
+		var page struct {
+			pageMeta
+			activitiesPage
+		}
+		u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/activities?start=%d&limit=25", b.URL, projectKey, repo, *pr.Number, start)
+		status, err := b.getJSON(u, &page)
+		if err != nil {
+			return pageMeta{}, err
+		}
+		if status >= 300 {
+			return pageMeta{}, fmt.Errorf("failed to list the activities of pull request %d: %d", *pr.Number, status)
+		}
+
+		activities = append(activities, page.Values...)
+		return page.pageMeta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	mapstructure.Decode(apiResponse.Values, &buildStatusesPage)
-	if buildStatusesPage.Size == 0 {
-		return "success", nil
+	// activities are returned newest first
+	answer := make([]*git.PullRequestEvent, 0, len(activities))
+	for i := len(activities) - 1; i >= 0; i-- {
+		answer = append(answer, convertBitbucketActivityToEvent(activities[i]))
 	}
+	return answer, nil
+}
 
-	for _, buildStatus := range buildStatusesPage.Values {
-		if time.Unix(buildStatus.DateAdded, 0).After(time.Unix(lastCommit.CommitterTimestamp, 0)) {
-			// var from BitBucketCloudProvider
-			return stateMap[buildStatus.State], nil
+// pullRequestDiff is the diff of a pull request, whose segments are lines added, removed or given as context. The
+// server leaves diffs out of large changes and then sets Truncated
+type pullRequestDiff struct {
+	Diffs []struct {
+		Hunks []struct {
+			Segments []struct {
+				Type  string            `json:"type"`
+				Lines []json.RawMessage `json:"lines"`
+			} `json:"segments"`
+		} `json:"hunks"`
+		Truncated bool `json:"truncated"`
+	} `json:"diffs"`
+	Truncated bool `json:"truncated"`
+}
+
+func (b *ServerProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	return nil, fmt.Errorf("get pull request approval state: %w", git.ErrNotSupported)
+}
+
+// GetPullRequestStats counts the lines of the diff of the pull request, as the server doesn't report its size
+func (b *ServerProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	var diff pullRequestDiff
+	u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/diff?contextLines=0&withComments=false", b.URL, projectKey, repo, *pr.Number)
+	status, err := b.getJSON(u, &diff)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("failed to get the diff of pull request %d: %d", *pr.Number, status)
+	}
+
+	answer := &git.DiffStat{
+		ChangedFiles: len(diff.Diffs),
+		Truncated:    diff.Truncated,
+	}
+	for _, d := range diff.Diffs {
+		answer.Truncated = answer.Truncated || d.Truncated
+		for _, hunk := range d.Hunks {
+			for _, segment := range hunk.Segments {
+				switch segment.Type {
+				case "ADDED":
+					answer.Additions += len(segment.Lines)
+				case "REMOVED":
+					answer.Deletions += len(segment.Lines)
+				}
+			}
 		}
 	}
+	return answer, nil
+}
 
-	return "success", nil
+func convertBitbucketActivityToEvent(activity pullRequestActivity) *git.PullRequestEvent {
+	action, ok := activityActionMap[activity.Action]
+	if !ok {
+		action = strings.ToLower(activity.Action)
+	}
+	createdAt := time.Unix(0, activity.CreatedDate*int64(time.Millisecond))
+	event := &git.PullRequestEvent{
+		ID:     strconv.Itoa(activity.ID),
+		Action: action,
+		Actor: &git.User{
+			Login: activity.User.Slug,
+			Name:  activity.User.DisplayName,
+			Email: activity.User.EmailAddress,
+		},
+		CreatedAt: &createdAt,
+		CommitSHA: activity.FromHash,
+	}
+	if activity.Comment != nil {
+		event.Body = activity.Comment.Text
+	}
+	if activity.Commit != nil {
+		event.CommitSHA = activity.Commit.ID
+	}
+	return event
 }
 
 func (b *ServerProvider) ListCommitStatus(org, repo, sha string) ([]*git.RepoStatus, error) {
-	var buildStatusesPage buildStatusesPage
 	statuses := []*git.RepoStatus{}
 
-	for {
-		apiResponse, err := b.Client.DefaultApi.GetCommitBuildStatuses(sha)
+	// the client always asks for the first page of build statuses, so page through the API directly
+	err := paginate(func(start int) (pageMeta, error) {
+		var page struct {
+			pageMeta
+			buildStatusesPage
+		}
+		u := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s?start=%d&limit=25", b.URL, sha, start)
+		status, err := b.getJSON(u, &page)
 		if err != nil {
-			return nil, err
+			return pageMeta{}, err
 		}
-
-		mapstructure.Decode(apiResponse.Values, &buildStatusesPage)
-
-		for _, buildStatus := range buildStatusesPage.Values {
-			statuses = append(statuses, convertBitBucketBuildStatusToGitStatus(&buildStatus))
+		if status >= 300 {
+			return pageMeta{}, fmt.Errorf("failed to list the build statuses of commit %s: %d", sha, status)
 		}
 
-		if buildStatusesPage.IsLastPage {
-			break
+		for _, buildStatus := range page.Values {
+			statuses = append(statuses, convertBitBucketBuildStatusToGitStatus(&buildStatus))
 		}
+		return page.pageMeta, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return statuses, nil
 }
 
+func (b *ServerProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	statuses, err := b.ListCommitStatus(org, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindRepoStatus(statuses, context), nil
+}
+
 func (b *ServerProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
-	return &git.RepoStatus{}, errors.New("TODO")
+	if err := status.NormalizeContext(git.MaxStatusContextLength); err != nil {
+		return nil, err
+	}
+	buildState, ok := buildStateMap[status.State]
+	if !ok {
+		return nil, fmt.Errorf("invalid commit status state %s for bitbucket", status.State)
+	}
+	targetURL := status.TargetURL
+	if targetURL == "" {
+		targetURL = status.URL
+	}
+	buildStatus := buildStatusRequest{
+		State:       buildState,
+		Key:         status.Context,
+		URL:         targetURL,
+		Description: status.Description,
+	}
+	body, err := json.Marshal(&buildStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	// the client only reads build statuses so post to the build status API directly
+	u := util.UrlJoin(b.URL, "/rest/build-status/1.0/commits", sha)
+	resp, err := b.do(http.MethodPost, u, body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update the status of commit %s", sha)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to update the status of commit %s: %s", sha, resp.Status)
+	}
+
+	return &git.RepoStatus{
+		ID:          buildStatus.Key,
+		Context:     buildStatus.Key,
+		URL:         buildStatus.URL,
+		State:       stateMap[buildStatus.State],
+		TargetURL:   buildStatus.URL,
+		Description: buildStatus.Description,
+	}, nil
+}
+
+// do sends a request with a JSON body to an API the client doesn't support, using the access token of the context
+func (b *ServerProvider) do(method string, u string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(b.Context)
+	req.Header.Set("Content-Type", "application/json")
+	if token, ok := b.Context.Value(bitbucket.ContextAccessToken).(string); ok && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return b.Options.NewHTTPClient().Do(req)
+}
+
+// getJSON decodes the response of a GET request to an API the client doesn't support into v. It returns
+// the status code of the response, leaving v untouched if it isn't successful
+func (b *ServerProvider) getJSON(u string, v interface{}) (int, error) {
+	resp, err := b.do(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(v)
 }
 
 func convertBitBucketBuildStatusToGitStatus(buildStatus *bitbucket.BuildStatus) *git.RepoStatus {
+	// dateAdded is in milliseconds since the epoch
+	dateAdded := time.Unix(0, buildStatus.DateAdded*int64(time.Millisecond))
 	return &git.RepoStatus{
-		ID:  buildStatus.Key,
-		URL: buildStatus.Url,
+		ID:      buildStatus.Key,
+		Context: buildStatus.Key,
+		URL:     buildStatus.Url,
 		// var from BitBucketCloudProvider
 		State:       stateMap[buildStatus.State],
 		TargetURL:   buildStatus.Url,
 		Description: buildStatus.Description,
+		CreatedAt:   &dateAdded,
 	}
 }
 
@@ -676,13 +1180,29 @@ func (b *ServerProvider) MergePullRequest(pr *git.PullRequest, message string) e
 }
 
 func (b *ServerProvider) CreateWebHook(data *git.WebhookArguments) error {
+	// the repository may only be given by its URL
+	if data.Repo != nil && data.Repo.Name == "" && data.Repo.URL != "" {
+		_, data.Repo.Name = parseBitBucketServerURL(data.Repo.URL)
+	}
+	if err := data.Validate(); err != nil {
+		return err
+	}
 	projectKey, repo := parseBitBucketServerURL(data.Repo.URL)
 
+	name := data.Name
+	if name == "" {
+		name = defaultWebHookName
+	}
+	events := data.Events
+	if len(events) == 0 {
+		events = defaultWebHookEvents
+	}
+
 	var options = map[string]interface{}{
 		"url":    data.URL,
-		"name":   "Jenkins X Web Hook",
+		"name":   name,
 		"active": true,
-		"events": []string{"repo:refs_changed", "repo:modified", "repo:forked", "repo:comment:added", "repo:comment:edited", "repo:comment:deleted", "pr:opened", "pr:reviewer:approved", "pr:reviewer:unapproved", "pr:reviewer:needs_work", "pr:merged", "pr:declined", "pr:deleted", "pr:comment:added", "pr:comment:edited", "pr:comment:deleted"},
+		"events": events,
 	}
 
 	if data.Secret != "" {
@@ -710,13 +1230,27 @@ func (p *ServerProvider) UpdateWebHook(data *git.WebhookArguments) error {
 	return fmt.Errorf("not implemented!")
 }
 
-func (b *ServerProvider) SearchIssues(org string, name string, query string) ([]*git.Issue, error) {
+func (p *ServerProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	return nil, fmt.Errorf("list webhook deliveries: %w", git.ErrNotSupported)
+}
 
-	gitIssues := []*git.Issue{}
+func (p *ServerProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return fmt.Errorf("redeliver webhook: %w", git.ErrNotSupported)
+}
 
-	log.Warn("Searching issues on bitbucket server is not supported at this moment")
+func (p *ServerProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	return fmt.Errorf("ping webhook: %w", git.ErrNotSupported)
+}
 
-	return gitIssues, nil
+// SearchIssues returns the Jira issues linked to the newest pull requests of the repository which match the
+// query. Bitbucket server has no issues of its own so it needs the jira-integration plugin
+func (b *ServerProvider) SearchIssues(org string, name string, query string) ([]*git.Issue, error) {
+	issues, err := b.linkedJiraIssues(org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return git.ParseIssueQuery(query).FilterIssues(issues), nil
 }
 
 func (b *ServerProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*git.Issue, error) {
@@ -727,10 +1261,20 @@ func (b *ServerProvider) SearchIssuesClosedSince(org string, name string, t time
 	return git.FilterIssuesClosedSince(issues, t), nil
 }
 
+// GetIssue returns the Jira issue with the given number which is linked to a pull request of the repository,
+// or nil if there is none. The number is the part of the Jira key after the project key
 func (b *ServerProvider) GetIssue(org string, name string, number int) (*git.Issue, error) {
+	issues, err := b.linkedJiraIssues(org, name)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Warn("Finding an issue on bitbucket server is not supported at this moment")
-	return &git.Issue{}, nil
+	for _, issue := range issues {
+		if issue.Number != nil && *issue.Number == number {
+			return issue, nil
+		}
+	}
+	return nil, nil
 }
 
 func (b *ServerProvider) IssueURL(org string, name string, number int, isPull bool) string {
@@ -747,9 +1291,7 @@ func (b *ServerProvider) IssueURL(org string, name string, number int, isPull bo
 }
 
 func (b *ServerProvider) CreateIssue(owner string, repo string, issue *git.Issue) (*git.Issue, error) {
-
-	log.Warn("Creating an issue on bitbucket server is not suuported at this moment")
-	return &git.Issue{}, nil
+	return nil, fmt.Errorf("create issue: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) AddPRComment(pr *git.PullRequest, comment string) error {
@@ -766,15 +1308,32 @@ func (b *ServerProvider) AddPRComment(pr *git.PullRequest, comment string) error
 	return err
 }
 
+// CreateIssueComment comments on the pull request with the given number. The issues of Bitbucket Server live
+// in Jira, so pull requests are the only numbered issues its REST API can comment on
 func (b *ServerProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
-	log.Warn("Bitbucket Server doesn't support adding issue comments via the REST API")
-	return nil
+	return b.AddPRComment(&git.PullRequest{
+		Owner:  owner,
+		Repo:   repo,
+		Number: &number,
+	}, comment)
+}
+
+func (b *ServerProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return fmt.Errorf("add labels to issue: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return fmt.Errorf("remove label from issue: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) HasIssues() bool {
 	return true
 }
 
+func (b *ServerProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	return fmt.Errorf("set issues enabled: %w", git.ErrNotSupported)
+}
+
 func (b *ServerProvider) IsGitHub() bool {
 	return false
 }
@@ -796,7 +1355,7 @@ func (b *ServerProvider) IsGerrit() bool {
 }
 
 func (b *ServerProvider) Kind() string {
-	return "bitbucketserver"
+	return git.KindBitBucketServer
 }
 
 // Exposed by Jenkins plugin; this one is for https://wiki.jenkins.io/display/JENKINS/BitBucket+Plugin
@@ -820,32 +1379,144 @@ func (b *ServerProvider) CurrentUsername() string {
 	return b.Username
 }
 
+// GetCurrentUser returns the user the token belongs to. The REST API has no current user resource so the
+// name is read from the whoami servlet, which answers with the plain name or nothing for anonymous requests
+func (b *ServerProvider) GetCurrentUser() (*git.User, error) {
+	resp, err := b.do(http.MethodGet, util.UrlJoin(b.URL, "/plugins/servlet/applinks/whoami"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the authenticated user due to: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Failed to get the authenticated user: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the authenticated user due to: %s", err)
+	}
+	login := strings.TrimSpace(string(data))
+	if login == "" {
+		return nil, fmt.Errorf("Failed to get the authenticated user as the request was anonymous")
+	}
+	user := b.UserInfo(login)
+	if user == nil {
+		return nil, fmt.Errorf("Failed to get the user %s", login)
+	}
+	return user, nil
+}
+
 func (b *ServerProvider) UserInfo(username string) *git.User {
+	user, err := b.getUser(username)
+	if err != nil {
+		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
+	}
+	return user
+}
+
+// getUser looks up a user, or returns nil if there is no such user
+func (b *ServerProvider) getUser(username string) (*git.User, error) {
 	var user bitbucket.UserWithLinks
 	apiResponse, err := b.Client.DefaultApi.GetUser(username)
+	if apiResponse != nil && apiResponse.Response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
 	if err != nil {
-		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
-		return nil
+		return nil, fmt.Errorf("get user %s: %w", username, err)
 	}
 	err = mapstructure.Decode(apiResponse.Values, &user)
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", username, err)
+	}
 
 	return &git.User{
 		Login: username,
 		Name:  user.DisplayName,
 		Email: user.Email,
 		URL:   user.Links.Self[0].Href,
-	}
+	}, nil
+}
+
+func (b *ServerProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return b.users.GetUsers(usernames, b.getUser)
+}
+
+func (b *ServerProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	return nil, fmt.Errorf("list milestones: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	return nil, fmt.Errorf("create milestone: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) CloseMilestone(org string, name string, id int64) error {
+	return fmt.Errorf("close milestone: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	return nil, fmt.Errorf("list contributors: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
-	log.Warn("Bitbucket Server doesn't support releases")
-	return nil
+	return fmt.Errorf("update release: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) ListReleases(org string, name string) ([]*git.Release, error) {
-	answer := []*git.Release{}
-	log.Warn("Bitbucket Server doesn't support releases")
-	return answer, nil
+	return nil, fmt.Errorf("list releases: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	return fmt.Errorf("create branch: %w", git.ErrNotSupported)
+}
+
+// DeleteBranch deletes the branch using the branch utils API, which the client doesn't support
+func (b *ServerProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := b.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	// an empty repository has no default branch
+	if repo.DefaultBranch != "" && branch == repo.DefaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, git.ErrDefaultBranch)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"name":   "refs/heads/" + branch,
+		"dryRun": false,
+	})
+	if err != nil {
+		return err
+	}
+	u := util.UrlJoin(b.URL, "/rest/branch-utils/1.0/projects", org, "repos", name, "branches")
+	resp, err := b.do(http.MethodDelete, u, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete branch %s of %s/%s", branch, org, name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete branch %s of %s/%s: %s", branch, org, name, resp.Status)
+	}
+	return nil
+}
+
+func (b *ServerProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	return fmt.Errorf("create tag: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	return nil, fmt.Errorf("get tag: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	return nil, fmt.Errorf("list repository tags: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	return 0, 0, fmt.Errorf("get ahead behind: %w", git.ErrNotSupported)
+}
+
+// SyncFork merges the upstream branch into the fork using a local clone
+func (b *ServerProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return git.SyncForkWithGit(b, b.Git, b.Options, b.token, org, name, upstreamOrg, upstreamName, branch)
 }
 
 func (b *ServerProvider) AddCollaborator(user string, organisation string, repo string) error {
@@ -853,18 +1524,28 @@ func (b *ServerProvider) AddCollaborator(user string, organisation string, repo
 	return nil
 }
 
-func (b *ServerProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+func (b *ServerProvider) ListInvitations() ([]*git.Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	return nil, fmt.Errorf("get file last commit: %w", git.ErrNotSupported)
 }
 
-func (b *ServerProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return &github.Response{}, nil
+func (b *ServerProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	return nil, fmt.Errorf("list branches containing commit: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on bitbucket")
+	return nil, fmt.Errorf("get content: %w", git.ErrNotSupported)
+}
+
+func (b *ServerProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	return "", fmt.Errorf("resolve ref: %w", git.ErrNotSupported)
 }
 
 func (b *ServerProvider) AccessTokenURL() string {
@@ -2,9 +2,16 @@ package bitbucketserver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	bitbucket "github.com/gfleury/go-bitbucket-v1"
 	"github.com/wbrefvem/go-gits/pkg/git"
@@ -16,6 +23,9 @@ import (
 const (
 	userName = "test-user"
 	orgname  = "test-org"
+
+	buildStatusSHA      = "a9e2b1f5cc0d4b9e8e6f3d2c1b0a99887766554f"
+	pagedBuildStatusSHA = "3f1c2b9a8e7d6c5b4a39281706f5e4d3c2b1a090"
 )
 
 type BitbucketServerProviderTestSuite struct {
@@ -23,6 +33,17 @@ type BitbucketServerProviderTestSuite struct {
 	mux      *http.ServeMux
 	server   *httptest.Server
 	provider *ServerProvider
+
+	// buildStatus is the last body posted to the build status API
+	buildStatus map[string]string
+	// webHook is the last body posted to the webhooks API
+	webHook map[string]interface{}
+	// comment is the last body posted to the pull request comments API
+	comment map[string]interface{}
+	// pullRequestsQuery is the query of the last request listing pull requests
+	pullRequestsQuery url.Values
+	// deletedBranch is the last body sent to the branch utils API
+	deletedBranch map[string]interface{}
 }
 
 var bitbucketServerRouter = util.Router{
@@ -33,6 +54,9 @@ var bitbucketServerRouter = util.Router{
 		"GET":  "repos.json",
 		"POST": "repos.test-repo123.json",
 	},
+	"/rest/api/1.0/projects/~test-user/repos": util.MethodMap{
+		"GET": "repos.json",
+	},
 	"/rest/api/1.0/users/test-user/repos/test-repo": util.MethodMap{
 		"GET": "repos.test-repo.json",
 	},
@@ -42,8 +66,8 @@ var bitbucketServerRouter = util.Router{
 		"PUT":    "repos.test-repo-renamed.json",
 		"DELETE": "repos.test-repo.nil.json",
 	},
-	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests": util.MethodMap{
-		"POST": "pr.json",
+	"/rest/api/1.0/projects/TEST-ORG/repos/locked-repo": util.MethodMap{
+		"GET": "repos.test-repo.json",
 	},
 	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1": util.MethodMap{
 		"GET": "pr.json",
@@ -52,18 +76,24 @@ var bitbucketServerRouter = util.Router{
 	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/commits": util.MethodMap{
 		"GET": "pr-commits.json",
 	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/activities": util.MethodMap{
+		"GET": "pr-activity.json",
+	},
 	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/merge": util.MethodMap{
 		"POST": "pr-merge-success.json",
 	},
-	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/comments": util.MethodMap{
-		"POST": "pr-comment.json",
-	},
-	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/webhooks": util.MethodMap{
-		"POST": "webhook.json",
-	},
 	"/rest/api/1.0/users/test-user": util.MethodMap{
 		"GET": "user.json",
 	},
+	"/rest/jira-integration/1.0/servers": util.MethodMap{
+		"GET": "jira-servers.json",
+	},
+	"/rest/jira-integration/1.0/issues": util.MethodMap{
+		"GET": "jira-issues.json",
+	},
+	"/rest/jira/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/issues": util.MethodMap{
+		"GET": "pr-jira-issues.json",
+	},
 	"/rest/build-status/1.0/commits/d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c": util.MethodMap{
 		"GET": "build-statuses.json",
 	},
@@ -76,8 +106,117 @@ func (suite *BitbucketServerProviderTestSuite) SetupSuite() {
 		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/bitbucket_server", methodMap))
 	}
 
+	suite.mux.HandleFunc("/rest/build-status/1.0/commits/"+buildStatusSHA, func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.Require().Equal("Bearer 0123456789abcdef", r.Header.Get("Authorization"))
+		suite.buildStatus = map[string]string{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.buildStatus))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.mux.HandleFunc("/plugins/servlet/applinks/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			return
+		}
+		w.Write([]byte(userName))
+	})
+
+	createdWebHook := util.GetMockAPIResponseFromFile("test_data/bitbucket_server", util.MethodMap{"POST": "webhook.json"})
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		suite.webHook = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.webHook))
+		createdWebHook(w, r)
+	})
+
+	createdComment := util.GetMockAPIResponseFromFile("test_data/bitbucket_server", util.MethodMap{"POST": "pr-comment.json"})
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		suite.comment = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.comment))
+		createdComment(w, r)
+	})
+
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"diffs": [
+			{"hunks": [{"segments": [
+				{"type": "REMOVED", "lines": [{"line": "Old text"}]},
+				{"type": "ADDED", "lines": [{"line": "New text"}, {"line": "More text"}]}]}]},
+			{"hunks": [{"segments": [{"type": "REMOVED", "lines": [{"line": "return nil"}]}]}]}],
+			"truncated": false}`)
+	})
+
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/branches/default", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "refs/heads/master", "displayId": "master", "type": "BRANCH", "isDefault": true}`)
+	})
+
+	// the branches of the locked repository can't be read
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/locked-repo/branches/default", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	// only the feature branch exists
+	suite.mux.HandleFunc("/rest/branch-utils/1.0/projects/TEST-ORG/repos/test-repo/branches", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		body := map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		if body["name"] != "refs/heads/feature" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		suite.deletedBranch = body
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	pullRequests := util.GetMockAPIResponseFromFile("test_data/bitbucket_server", util.MethodMap{
+		"GET":  "pull-requests.json",
+		"POST": "pr.json",
+	})
+	suite.mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		suite.pullRequestsQuery = r.URL.Query()
+		pullRequests(w, r)
+	})
+
+	// the repositories of this project come back as many at a time as the limit asks for
+	suite.mux.HandleFunc("/rest/api/1.0/projects/PAGED-ORG/repos", func(w http.ResponseWriter, r *http.Request) {
+		src, err := ioutil.ReadFile("test_data/bitbucket_server/repos.json")
+		suite.Require().Nil(err)
+		var repos struct {
+			Values []json.RawMessage `json:"values"`
+		}
+		suite.Require().Nil(json.Unmarshal(src, &repos))
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		suite.Require().Nil(err)
+		end := start + limit
+		if end > len(repos.Values) {
+			end = len(repos.Values)
+		}
+		suite.Require().Nil(json.NewEncoder(w).Encode(map[string]interface{}{
+			"size":          end - start,
+			"limit":         limit,
+			"start":         start,
+			"isLastPage":    end == len(repos.Values),
+			"nextPageStart": end,
+			"values":        repos.Values[start:end],
+		}))
+	})
+
+	// the build statuses of this commit come back a page at a time
+	firstPage := util.GetMockAPIResponseFromFile("test_data/bitbucket_server", util.MethodMap{"GET": "build-statuses.page-1.json"})
+	secondPage := util.GetMockAPIResponseFromFile("test_data/bitbucket_server", util.MethodMap{"GET": "build-statuses.page-2.json"})
+	suite.mux.HandleFunc("/rest/build-status/1.0/commits/"+pagedBuildStatusSHA, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start") == "1" {
+			secondPage(w, r)
+		} else {
+			firstPage(w, r)
+		}
+	})
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
 	git := git.NewGitCLI()
-	bp, err := NewBitbucketServerProvider("test-user", suite.server.URL, "0123456789abcdef", "bitbucketserver", git)
+	bp, err := NewProvider("test-user", suite.server.URL, "0123456789abcdef", "bitbucketserver", git)
 
 	suite.Require().NotNil(bp)
 	suite.Require().Nil(err)
@@ -87,9 +226,6 @@ func (suite *BitbucketServerProviderTestSuite) SetupSuite() {
 	suite.Require().True(ok)
 	suite.Require().NotNil(suite.provider)
 
-	suite.server = httptest.NewServer(suite.mux)
-	suite.Require().NotNil(suite.server)
-
 	cfg := bitbucket.NewConfiguration(suite.server.URL + "/rest")
 	ctx := context.Background()
 
@@ -101,6 +237,31 @@ func (suite *BitbucketServerProviderTestSuite) TestGetRepository() {
 	repo, err := suite.provider.GetRepository("TEST-ORG", "test-repo")
 	suite.Require().Nil(err)
 	suite.Require().NotNil(repo)
+	suite.Require().Equal("TEST-ORG", repo.Project)
+	suite.Require().Equal("TEST-ORG", repo.Organisation)
+	suite.Require().Equal("master", repo.DefaultBranch)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestGetRepositoryWithoutDefaultBranch() {
+	repo, err := suite.provider.GetRepository("TEST-ORG", "locked-repo")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Require().Equal("", repo.DefaultBranch)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestDeleteBranch() {
+	suite.deletedBranch = nil
+
+	err := suite.provider.DeleteBranch("TEST-ORG", "test-repo", "feature")
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"name": "refs/heads/feature", "dryRun": false}, suite.deletedBranch)
+
+	// a branch which is already gone is fine
+	err = suite.provider.DeleteBranch("TEST-ORG", "test-repo", "deleted")
+	suite.Require().Nil(err)
+
+	err = suite.provider.DeleteBranch("TEST-ORG", "test-repo", "master")
+	suite.Require().True(git.IsDefaultBranch(err))
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestListOrganizations() {
@@ -121,6 +282,36 @@ func (suite *BitbucketServerProviderTestSuite) TestListRepositories() {
 	}
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestListRepositoriesPaged() {
+	page, err := suite.provider.ListRepositoriesPaged("PAGED-ORG", git.ListOptions{PerPage: 1})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(page.Repositories, 1)
+	suite.Require().Equal("test-repo", page.Repositories[0].Name)
+	suite.Require().True(page.HasMore)
+	suite.Require().Equal("1", page.NextCursor)
+
+	page, err = suite.provider.ListRepositoriesPaged("PAGED-ORG", git.ListOptions{PerPage: 1, Cursor: page.NextCursor})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(page.Repositories, 1)
+	suite.Require().Equal("test-repo2", page.Repositories[0].Name)
+	suite.Require().False(page.HasMore)
+	suite.Require().Equal("", page.NextCursor)
+
+	page, err = suite.provider.ListRepositoriesPaged("PAGED-ORG", git.ListOptions{PerPage: 1, Page: 2})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("test-repo2", page.Repositories[0].Name)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestListRepositoriesForCurrentUser() {
+	repos, err := suite.provider.ListRepositoriesForCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(len(repos), 2)
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestCreateRepository() {
 	repo, err := suite.provider.CreateRepository("TEST-ORG", "test-repo123", false)
 
@@ -128,6 +319,39 @@ func (suite *BitbucketServerProviderTestSuite) TestCreateRepository() {
 	suite.Require().NotNil(repo)
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestUpdateCommitStatus() {
+	buildStates := map[string]string{
+		"success":     "SUCCESSFUL",
+		"failure":     "FAILED",
+		"error":       "FAILED",
+		"pending":     "INPROGRESS",
+		"in-progress": "INPROGRESS",
+		"stopped":     "STOPPED",
+	}
+
+	for state, buildState := range buildStates {
+		status, err := suite.provider.UpdateCommitStatus("TEST-ORG", "test-repo", buildStatusSHA, &git.RepoStatus{
+			State:       state,
+			Context:     "ci/build",
+			TargetURL:   "https://ci.example.com/builds/1",
+			Description: "The build finished",
+		})
+
+		suite.Require().Nil(err)
+		suite.Require().Equal(map[string]string{
+			"state":       buildState,
+			"key":         "ci/build",
+			"url":         "https://ci.example.com/builds/1",
+			"description": "The build finished",
+		}, suite.buildStatus, "build status posted for %s", state)
+		suite.Require().Equal(stateMap[buildState], status.State)
+		suite.Require().Equal("ci/build", status.Context)
+	}
+
+	_, err := suite.provider.UpdateCommitStatus("TEST-ORG", "test-repo", buildStatusSHA, &git.RepoStatus{State: "unknown", Context: "ci/build"})
+	suite.Require().NotNil(err)
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestDeleteRepository() {
 	err := suite.provider.DeleteRepository("TEST-ORG", "test-repo")
 	suite.Require().Nil(err)
@@ -165,6 +389,20 @@ func (suite *BitbucketServerProviderTestSuite) TestForkRepository() {
 	suite.Require().Equal(fork.Name, "test-repo")
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestForkRepositoryGivesUp() {
+	defer suite.provider.SetForkPollConfig(suite.provider.ForkPollConfig)
+	suite.provider.SetForkPollConfig(git.ForkPollConfig{
+		Interval: time.Millisecond,
+		Timeout:  10 * time.Millisecond,
+	})
+
+	fork, err := suite.provider.ForkRepository("TEST-ORG", "test-repo", "MISSING-ORG")
+
+	suite.Require().Nil(fork)
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "timed out after 10ms")
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestCreatePullRequest() {
 	args := git.PullRequestArguments{
 		Repository: &git.Repository{
@@ -202,6 +440,47 @@ func (suite *BitbucketServerProviderTestSuite) TestUpdatePullRequestStatus() {
 	suite.Require().Equal("d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", pr.LastCommitSha)
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestListPullRequests() {
+	prs, err := suite.provider.ListPullRequests("test-user", &git.Repository{Name: "test-repo", Project: "TEST-ORG"}, git.PullRequestStateOpen)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 1)
+	suite.Require().Equal(1, *prs[0].Number)
+	suite.Require().Equal("OPEN", *prs[0].State)
+	suite.Require().Equal("OPEN", suite.pullRequestsQuery.Get("state"))
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestListPullRequestsByAuthor() {
+	prs, err := suite.provider.ListPullRequestsByAuthor(
+		"test-user",
+		&git.Repository{Name: "test-repo", Project: "TEST-ORG"},
+		userName,
+		git.PullRequestStateOpen,
+	)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 1)
+	suite.Require().Equal(1, *prs[0].Number)
+	suite.Require().Equal(userName, prs[0].Author.Login)
+	suite.Require().Equal("OPEN", suite.pullRequestsQuery.Get("state"))
+	suite.Require().Equal("AUTHOR", suite.pullRequestsQuery.Get("role.1"))
+	suite.Require().Equal(userName, suite.pullRequestsQuery.Get("username.1"))
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestGetPullRequestByBranch() {
+	repo := &git.Repository{Name: "test-repo", Project: "TEST-ORG"}
+	pr, err := suite.provider.GetPullRequestByBranch("test-user", repo, "feat/world")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(1, *pr.Number)
+
+	pr, err = suite.provider.GetPullRequestByBranch("test-user", repo, "feat/other")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(pr)
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestGetPullRequest() {
 
 	pr, err := suite.provider.GetPullRequest(
@@ -238,7 +517,38 @@ func (suite *BitbucketServerProviderTestSuite) TestPullRequestLastCommitStatus()
 
 	suite.Require().Nil(err)
 	suite.Require().NotEmpty(lastCommitStatus)
-	suite.Require().Equal(lastCommitStatus, "in-progress")
+	suite.Require().Equal(lastCommitStatus, "pending")
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestGetPullRequestStats() {
+	prNumber := 1
+	pr := &git.PullRequest{
+		URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1/overview",
+		Repo:   "test-repo",
+		Number: &prNumber,
+	}
+	stats, err := suite.provider.GetPullRequestStats(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.DiffStat{Additions: 2, Deletions: 2, ChangedFiles: 2}, stats)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestListPullRequestActivity() {
+	prNumber := 1
+	pr := &git.PullRequest{
+		URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1/overview",
+		Repo:   "test-repo",
+		Number: &prNumber,
+	}
+	events, err := suite.provider.ListPullRequestActivity(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(events, 2)
+	suite.Require().Equal("opened", events[0].Action)
+	suite.Require().Equal("merged", events[1].Action)
+	suite.Require().Equal("test-user", events[1].Actor.Login)
+	suite.Require().Equal("629d1f00fa39fe8470e9a64482789b7f97e45f51", events[1].CommitSHA)
+	suite.Require().True(events[0].CreatedAt.Before(*events[1].CreatedAt))
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestListCommitStatuses() {
@@ -258,6 +568,28 @@ func (suite *BitbucketServerProviderTestSuite) TestListCommitStatuses() {
 	}
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestGetCommitStatus() {
+	status, err := suite.provider.GetCommitStatus("TEST-ORG", "test-repo", "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", "Test-Master")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("Test-Master", status.Context)
+	suite.Require().Equal("success", status.State)
+
+	status, err = suite.provider.GetCommitStatus("TEST-ORG", "test-repo", "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", "missing")
+	suite.Require().Nil(err)
+	suite.Require().Nil(status)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestListCommitStatusesPages() {
+	buildStatuses, err := suite.provider.ListCommitStatus("TEST-ORG", "test-repo", pagedBuildStatusSHA)
+	suite.Require().Nil(err)
+	suite.Require().Len(buildStatuses, 2)
+	suite.Require().Equal("REPO-LINT", buildStatuses[0].ID)
+	suite.Require().Equal("failure", buildStatuses[0].State)
+	suite.Require().Equal("REPO-TEST", buildStatuses[1].ID)
+	suite.Require().Equal("success", buildStatuses[1].State)
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestMergePullRequest() {
 
 	id := 1
@@ -281,6 +613,134 @@ func (suite *BitbucketServerProviderTestSuite) TestCreateWebHook() {
 	err := suite.provider.CreateWebHook(data)
 
 	suite.Require().Nil(err)
+	suite.Require().Equal(defaultWebHookName, suite.webHook["name"])
+	suite.Require().Equal("https://my-jenkins.example.com/bitbucket-webhook/", suite.webHook["url"])
+	suite.Require().Len(suite.webHook["events"], len(defaultWebHookEvents))
+	suite.Require().Equal(map[string]interface{}{"secret": "someSecret"}, suite.webHook["configuration"])
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestCreateWebHookWithEvents() {
+
+	data := &git.WebhookArguments{
+		Repo:   &git.Repository{URL: "https://auth.example.com/projects/TEST-ORG/repos/test-repo"},
+		URL:    "https://my-jenkins.example.com/bitbucket-webhook/",
+		Name:   "Merged Pull Requests",
+		Events: []string{"pr:merged"},
+	}
+	err := suite.provider.CreateWebHook(data)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("Merged Pull Requests", suite.webHook["name"])
+	suite.Require().Equal([]interface{}{"pr:merged"}, suite.webHook["events"])
+	suite.Require().NotContains(suite.webHook, "configuration")
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestCreateWebHookWithInvalidArguments() {
+	err := suite.provider.CreateWebHook(&git.WebhookArguments{
+		Repo: &git.Repository{URL: "https://auth.example.com/projects/TEST-ORG/repos/test-repo"},
+		URL:  "/bitbucket-webhook/",
+	})
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "URL /bitbucket-webhook/ is not an absolute URL")
+
+	err = suite.provider.CreateWebHook(&git.WebhookArguments{
+		URL: "https://my-jenkins.example.com/bitbucket-webhook/",
+	})
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "missing property Repo")
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestSearchIssues() {
+	issues, err := suite.provider.SearchIssues("TEST-ORG", "test-repo", "")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 2)
+	suite.Require().Equal("TEST-12", issues[0].Key)
+	suite.Require().Equal(12, *issues[0].Number)
+	suite.Require().Equal("https://jira.example.com/browse/TEST-12", issues[0].URL)
+	suite.Require().Equal("closed", *issues[0].State)
+	suite.Require().NotNil(issues[0].ClosedAt)
+	suite.Require().Equal("test-user", issues[0].Assignees[0].Login)
+	suite.Require().Equal("open", *issues[1].State)
+	suite.Require().Nil(issues[1].ClosedAt)
+
+	issues, err = suite.provider.SearchIssues("TEST-ORG", "test-repo", "document")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal("TEST-15", issues[0].Key)
+
+	issues, err = suite.provider.SearchIssues("TEST-ORG", "test-repo", "is:closed")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal("TEST-12", issues[0].Key)
+
+	issues, err = suite.provider.SearchIssues("TEST-ORG", "test-repo", "test-15")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal("TEST-15", issues[0].Key)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestGetIssue() {
+	issue, err := suite.provider.GetIssue("TEST-ORG", "test-repo", 15)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(issue)
+	suite.Require().Equal("Document the greeting", issue.Title)
+
+	issue, err = suite.provider.GetIssue("TEST-ORG", "test-repo", 99)
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(issue)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestIssuesWithoutJiraIntegration() {
+	// a server without the plugin answers 404 to the jira-integration API
+	server := httptest.NewServer(http.NewServeMux())
+	defer server.Close()
+	bp, err := NewProvider("test-user", server.URL, "0123456789abcdef", "bitbucketserver", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	_, err = bp.SearchIssues("TEST-ORG", "test-repo", "")
+	suite.Require().True(errors.Is(err, git.ErrNotSupported))
+
+	_, err = bp.GetIssue("TEST-ORG", "test-repo", 12)
+	suite.Require().True(errors.Is(err, git.ErrNotSupported))
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(userName, user.Login)
+	suite.Require().Equal("Test User", user.Name)
+	suite.Require().Equal("test-user@example.com", user.Email)
+
+	// without a token the server doesn't know who is asking
+	bp, err := NewProvider("test-user", suite.server.URL, "", "bitbucketserver", git.NewGitCLI())
+	suite.Require().Nil(err)
+	_, err = bp.GetCurrentUser()
+	suite.Require().NotNil(err)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestUnsupportedOperations() {
+	_, err := suite.provider.CreateIssue("TEST-ORG", "test-repo", &git.Issue{Title: "Broken"})
+	suite.Require().True(errors.Is(err, git.ErrNotSupported))
+
+	err = suite.provider.UpdateRelease("TEST-ORG", "test-repo", "v1.0.0", &git.Release{})
+	suite.Require().True(errors.Is(err, git.ErrNotSupported))
+
+	_, err = suite.provider.ListReleases("TEST-ORG", "test-repo")
+	suite.Require().True(errors.Is(err, git.ErrNotSupported))
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestKind() {
+	suite.Require().Equal(git.KindBitBucketServer, suite.provider.Kind())
+	suite.Require().Contains(git.KindGits, suite.provider.Kind())
+	suite.Require().True(suite.provider.IsBitbucketServer())
+	suite.Require().False(suite.provider.IsBitbucketCloud())
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestUserInfo() {
@@ -301,16 +761,13 @@ func (suite *BitbucketServerProviderTestSuite) TestAddCollaborator() {
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
-	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	_, err := suite.provider.ListInvitations()
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	err := suite.provider.AcceptInvitation(1)
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestAddPRComment() {
@@ -324,6 +781,14 @@ func (suite *BitbucketServerProviderTestSuite) TestAddPRComment() {
 	err := suite.provider.AddPRComment(pr, "This is a new comment.")
 
 	suite.Require().Nil(err)
+	suite.Require().Equal("This is a new comment.", suite.comment["text"])
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestCreateIssueComment() {
+	err := suite.provider.CreateIssueComment("TEST-ORG", "test-repo", 1, "This is an issue comment.")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("This is an issue comment.", suite.comment["text"])
 }
 
 func TestBitbucketServerProviderTestSuite(t *testing.T) {
@@ -0,0 +1,209 @@
+package bitbucketserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wbrefvem/go-gits/pkg/git"
+)
+
+// jiraDateLayout is the layout of the dates of Jira issues
+const jiraDateLayout = "2006-01-02T15:04:05.000-0700"
+
+// jiraIssueFields are the fields of the Jira issues requested from the jira-integration plugin
+const jiraIssueFields = "summary,description,status,created,updated,resolutiondate,assignee,reporter"
+
+// maxJiraPullRequests is how many of the newest pull requests are searched for linked Jira issues
+const maxJiraPullRequests = 100
+
+// jiraIssueLink is a Jira issue linked to a pull request
+type jiraIssueLink struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+type jiraUser struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type jiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// jiraIssue is an issue of a Jira server linked to bitbucket server, as returned by the jira-integration plugin
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary        string     `json:"summary"`
+		Description    string     `json:"description"`
+		Status         jiraStatus `json:"status"`
+		Created        string     `json:"created"`
+		Updated        string     `json:"updated"`
+		ResolutionDate string     `json:"resolutiondate"`
+		Assignee       *jiraUser  `json:"assignee"`
+		Reporter       *jiraUser  `json:"reporter"`
+	} `json:"fields"`
+}
+
+// hasJiraIntegration returns true if the jira-integration plugin is installed and linked to a Jira server
+func (b *ServerProvider) hasJiraIntegration() (bool, error) {
+	servers := []interface{}{}
+	status, err := b.getJSON(b.URL+"/rest/jira-integration/1.0/servers", &servers)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if status >= 300 {
+		return false, fmt.Errorf("failed to list the Jira servers linked to bitbucket: %d", status)
+	}
+	return len(servers) > 0, nil
+}
+
+// linkedJiraIssues returns the Jira issues linked to the pull requests of a repository. Only the newest
+// maxJiraPullRequests pull requests are looked at, as each of them takes a request
+func (b *ServerProvider) linkedJiraIssues(org string, name string) ([]*git.Issue, error) {
+	installed, err := b.hasJiraIntegration()
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		return nil, fmt.Errorf("issues of bitbucket server without the jira-integration plugin: %w", git.ErrNotSupported)
+	}
+
+	numbers, err := b.recentPullRequestNumbers(org, name, maxJiraPullRequests)
+	if err != nil {
+		return nil, err
+	}
+	links := map[string]string{}
+	keys := []string{}
+	for _, number := range numbers {
+		prLinks := []jiraIssueLink{}
+		u := fmt.Sprintf("%s/rest/jira/1.0/projects/%s/repos/%s/pull-requests/%d/issues", b.URL, org, name, number)
+		status, err := b.getJSON(u, &prLinks)
+		if err != nil {
+			return nil, err
+		}
+		if status >= 300 {
+			return nil, fmt.Errorf("failed to list the Jira issues of pull request %d: %d", number, status)
+		}
+		for _, link := range prLinks {
+			if _, ok := links[link.Key]; !ok {
+				keys = append(keys, link.Key)
+				links[link.Key] = link.URL
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return []*git.Issue{}, nil
+	}
+
+	params := url.Values{}
+	for _, key := range keys {
+		params.Add("issueKey", key)
+	}
+	params.Set("fields", jiraIssueFields)
+	issues := []jiraIssue{}
+	status, err := b.getJSON(b.URL+"/rest/jira-integration/1.0/issues?"+params.Encode(), &issues)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("failed to get Jira issues %s: %d", strings.Join(keys, ", "), status)
+	}
+
+	answer := []*git.Issue{}
+	for _, issue := range issues {
+		answer = append(answer, toJiraIssue(org, name, issue, links[issue.Key]))
+	}
+	return answer, nil
+}
+
+// recentPullRequestNumbers returns the numbers of at most max pull requests of the repository, whatever
+// their state, newest first
+func (b *ServerProvider) recentPullRequestNumbers(org string, name string, max int) ([]int, error) {
+	numbers := []int{}
+	err := paginate(func(start int) (pageMeta, error) {
+		var page struct {
+			pageMeta
+			Values []struct {
+				ID int `json:"id"`
+			} `json:"values"`
+		}
+		u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests?state=ALL&order=NEWEST&start=%d&limit=25", b.URL, org, name, start)
+		status, err := b.getJSON(u, &page)
+		if err != nil {
+			return pageMeta{}, err
+		}
+		if status >= 300 {
+			return pageMeta{}, fmt.Errorf("failed to list the pull requests of %s/%s: %d", org, name, status)
+		}
+
+		for _, pr := range page.Values {
+			if len(numbers) == max {
+				return pageMeta{IsLastPage: true}, nil
+			}
+			numbers = append(numbers, pr.ID)
+		}
+		return page.pageMeta, nil
+	})
+	return numbers, err
+}
+
+func toJiraIssue(org string, name string, issue jiraIssue, issueURL string) *git.Issue {
+	state := "open"
+	if issue.Fields.Status.StatusCategory.Key == "done" {
+		state = "closed"
+	}
+	answer := &git.Issue{
+		URL:       issueURL,
+		Owner:     org,
+		Repo:      name,
+		Key:       issue.Key,
+		Title:     issue.Fields.Summary,
+		Body:      issue.Fields.Description,
+		State:     &state,
+		CreatedAt: parseJiraDate(issue.Fields.Created),
+		UpdatedAt: parseJiraDate(issue.Fields.Updated),
+		ClosedAt:  parseJiraDate(issue.Fields.ResolutionDate),
+	}
+	if i := strings.LastIndex(issue.Key, "-"); i >= 0 {
+		if number, err := strconv.Atoi(issue.Key[i+1:]); err == nil {
+			answer.Number = &number
+		}
+	}
+	if issue.Fields.Reporter != nil {
+		answer.User = toJiraUser(issue.Fields.Reporter)
+	}
+	if issue.Fields.Assignee != nil {
+		answer.Assignees = []git.User{*toJiraUser(issue.Fields.Assignee)}
+	}
+	return answer
+}
+
+func toJiraUser(user *jiraUser) *git.User {
+	return &git.User{
+		Login: user.Name,
+		Name:  user.DisplayName,
+		Email: user.EmailAddress,
+	}
+}
+
+// parseJiraDate returns nil for blank or invalid dates
+func parseJiraDate(value string) *time.Time {
+	t, err := time.Parse(jiraDateLayout, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
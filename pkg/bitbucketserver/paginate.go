@@ -0,0 +1,40 @@
+package bitbucketserver
+
+import (
+	"github.com/mitchellh/mapstructure"
+)
+
+// pageMeta is the paging information returned with each page of a bitbucket server list
+type pageMeta struct {
+	Size          int  `json:"size"`
+	Limit         int  `json:"limit"`
+	Start         int  `json:"start"`
+	NextPageStart int  `json:"nextPageStart"`
+	IsLastPage    bool `json:"isLastPage"`
+}
+
+// paginate calls fetchPage with the start of each page until it returns the last page. It also stops if a
+// page doesn't move the start on, as some API calls of the client can't be given a start
+func paginate(fetchPage func(start int) (pageMeta, error)) error {
+	start := 0
+	for {
+		meta, err := fetchPage(start)
+		if err != nil {
+			return err
+		}
+		if meta.IsLastPage || meta.NextPageStart <= start {
+			return nil
+		}
+		start = meta.NextPageStart
+	}
+}
+
+// decodePage decodes the values of an API response into the page and returns its paging information
+func decodePage(values map[string]interface{}, page interface{}) (pageMeta, error) {
+	var meta pageMeta
+	err := mapstructure.Decode(values, &meta)
+	if err != nil {
+		return meta, err
+	}
+	return meta, mapstructure.Decode(values, page)
+}
@@ -0,0 +1,55 @@
+package bitbucketserver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	starts := []int{}
+	err := paginate(func(start int) (pageMeta, error) {
+		starts = append(starts, start)
+		return pageMeta{Start: start, NextPageStart: start + 25, IsLastPage: start == 50}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 25, 50}, starts)
+
+	// a page which doesn't move the start on is the last one
+	calls := 0
+	err = paginate(func(start int) (pageMeta, error) {
+		calls++
+		return pageMeta{}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	err = paginate(func(start int) (pageMeta, error) {
+		return pageMeta{}, errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+}
+
+func TestDecodePage(t *testing.T) {
+	t.Parallel()
+
+	var page projectsPage
+	meta, err := decodePage(map[string]interface{}{
+		"size":          1,
+		"limit":         25,
+		"start":         0,
+		"nextPageStart": 25,
+		"isLastPage":    false,
+		"values": []interface{}{
+			map[string]interface{}{"key": "TEST-ORG"},
+		},
+	}, &page)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, meta.NextPageStart)
+	assert.False(t, meta.IsLastPage)
+	assert.Len(t, page.Values, 1)
+	assert.Equal(t, "TEST-ORG", page.Values[0].Key)
+}
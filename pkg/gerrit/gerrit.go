@@ -3,29 +3,66 @@ package gerrit
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/andygrunwald/go-gerrit"
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
 	"github.com/wbrefvem/go-gits/pkg/git"
 )
 
+const pageSize = 100
+
+// sshPort is the port Gerrit listens for SSH on unless it is configured otherwise
+const sshPort = "29418"
+
 type GerritProvider struct {
 	Client   *gerrit.Client
 	Username string
 	Context  context.Context
 
-	Git git.Gitter
+	URL     string
+	Git     git.Gitter
+	Options git.ProviderOptions
+
+	token string
 }
 
-func NewProvider(git git.Gitter) (git.Provider, error) {
-	ctx := context.Background()
+func init() {
+	git.RegisterProviderFactory(git.KindGerrit, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, gitter, options...)
+	})
+}
+
+// NewProvider returns a provider authenticating with the HTTP password of the user as the token. Without a username
+// the server is used anonymously, which only gives access to public projects
+func NewProvider(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	providerOptions := git.NewProviderOptions(options...)
+	serverURL = strings.TrimSuffix(serverURL, "/")
+
+	client, err := gerrit.NewClient(serverURL, providerOptions.NewHTTPClient())
+	if err != nil {
+		return nil, err
+	}
+	if username != "" {
+		client.Authentication.SetBasicAuth(username, token)
+	}
 
 	provider := GerritProvider{
-		Context: ctx,
-		Git:     git,
+		Client:   client,
+		Username: username,
+		Context:  providerOptions.RequestContext(),
+		URL:      serverURL,
+		Git:      gitter,
+		Options:  providerOptions,
+		token:    token,
 	}
 
 	return &provider, nil
@@ -51,35 +88,85 @@ func buildEncodedProjectName(org, name string) string {
 	return fullNamePathEscaped
 }
 
+// projectName returns the name of the project of the repository, which is prefixed by the org unless it already is
+func projectName(org, name string) string {
+	if org == "" || strings.HasPrefix(name, org+"/") {
+		return name
+	}
+	return org + "/" + name
+}
+
+// changeURL returns the URL of the page of the change
+func (p *GerritProvider) changeURL(project string, number int) string {
+	return fmt.Sprintf("%s/c/%s/+/%d", p.URL, project, number)
+}
+
 func (p *GerritProvider) projectInfoToGitRepository(project *gerrit.ProjectInfo) *git.Repository {
 	return &git.Repository{
-		Name: project.Name,
+		Name:     project.Name,
+		CloneURL: fmt.Sprintf("%s/%s", p.URL, project.Name),
+		SSHURL:   p.sshURL(project.Name),
+	}
+}
+
+// sshURL returns the URL to clone the project over SSH on the default port, or blank if the server URL has no host
+func (p *GerritProvider) sshURL(project string) string {
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Hostname() == "" {
+		return ""
 	}
+	return fmt.Sprintf("ssh://%s:%s/%s", u.Hostname(), sshPort, project)
 }
 
+// ListRepositories returns the projects under the org/ namespace, or every project if org is blank.
+// The repositories are named without the org prefix
 func (p *GerritProvider) ListRepositories(org string) ([]*git.Repository, error) {
+	prefix := ""
+	if org != "" {
+		prefix = org + "/"
+	}
 	options := &gerrit.ProjectOptions{
 		Description: true,
-		Prefix:      url.PathEscape(org),
-	}
-
-	gerritProjects, _, err := p.Client.Projects.ListProjects(options)
-	if err != nil {
-		return nil, err
+		Prefix:      prefix,
 	}
+	options.Limit = pageSize
 
 	repos := []*git.Repository{}
-
-	for name, project := range *gerritProjects {
-		project.Name = name
-		repo := p.projectInfoToGitRepository(&project)
-
-		repos = append(repos, repo)
+	for start := 0; ; start += pageSize {
+		options.Skip = strconv.Itoa(start)
+		gerritProjects, _, err := p.Client.Projects.ListProjects(options)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, project := range *gerritProjects {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			project.Name = name
+			repo := p.projectInfoToGitRepository(&project)
+			repo.Name = strings.TrimPrefix(name, prefix)
+			repo.Organisation = org
+
+			repos = append(repos, repo)
+		}
+
+		if len(*gerritProjects) < pageSize {
+			break
+		}
 	}
 
 	return repos, nil
 }
 
+func (p *GerritProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	return nil, fmt.Errorf("list repositories paged: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	return nil, fmt.Errorf("list repositories for current user: %w", git.ErrNotSupported)
+}
+
 func (p *GerritProvider) CreateRepository(org string, name string, private bool) (*git.Repository, error) {
 	input := &gerrit.ProjectInput{
 		SubmitType:      "INHERIT",
@@ -100,7 +187,10 @@ func (p *GerritProvider) CreateRepository(org string, name string, private bool)
 func (p *GerritProvider) GetRepository(org string, name string) (*git.Repository, error) {
 	fullName := buildEncodedProjectName(org, name)
 
-	project, _, err := p.Client.Projects.GetProject(fullName)
+	project, resp, err := p.Client.Projects.GetProject(fullName)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -108,23 +198,108 @@ func (p *GerritProvider) GetRepository(org string, name string) (*git.Repository
 }
 
 func (p *GerritProvider) DeleteRepository(org string, name string) error {
-	return nil
+	return fmt.Errorf("delete repository: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*git.Repository, error) {
-	return nil, nil
+	return nil, fmt.Errorf("fork repository: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) RenameRepository(org string, name string, newName string) (*git.Repository, error) {
-	return nil, nil
+	return nil, fmt.Errorf("rename repository: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) ValidateRepositoryName(org string, name string) error {
 	return nil
 }
 
+func (p *GerritProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	return nil, fmt.Errorf("get merge config: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	return fmt.Errorf("set merge config: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	return fmt.Errorf("set repository topics: %w", git.ErrNotSupported)
+}
+
+// CreatePullRequest creates a change by pushing the head branch to refs/for/<base>, with the head branch as the
+// topic so the change can be found again. Gerrit takes the subject of the change from the commit message, so the
+// title and body of the arguments aren't used, and the commit needs a Change-Id if the project requires one
 func (p *GerritProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.PullRequest, error) {
-	return nil, nil
+	if p.Git == nil {
+		return nil, fmt.Errorf("create pull request without a git client: %w", git.ErrNotSupported)
+	}
+	repo := data.Repository
+	project := projectName(repo.Organisation, repo.Name)
+	cloneURL := repo.CloneURL
+	if cloneURL == "" {
+		cloneURL = fmt.Sprintf("%s/%s", p.URL, project)
+	}
+	pushURL, err := p.Git.CreatePushURL(cloneURL, p.Options.PushUsername(p.Kind(), p.Username, p.token), p.token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the push URL of %s", cloneURL)
+	}
+
+	dir, err := ioutil.TempDir("", "gerrit-change-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	// only the head branch is needed to push it, so it is fetched into an empty repository rather than cloned
+	err = p.Git.Init(dir)
+	if err != nil {
+		return nil, err
+	}
+	err = p.Git.AddRemote(dir, "origin", pushURL)
+	if err != nil {
+		return nil, err
+	}
+	err = p.Git.FetchBranch(dir, "origin", data.Head)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch branch %s of %s", data.Head, project)
+	}
+	err = p.Git.ForcePushBranch(dir, "FETCH_HEAD", fmt.Sprintf("refs/for/%s%%topic=%s", data.Base, data.Head))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to push branch %s of %s for review on %s", data.Head, project, data.Base)
+	}
+
+	options := &gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s branch:%s topic:\"%s\" status:open", project, data.Base, data.Head)},
+			Limit: 1,
+		},
+		ChangeOptions: gerrit.ChangeOptions{
+			AdditionalFields: []string{"CURRENT_REVISION"},
+		},
+	}
+	changes, _, err := p.Client.Changes.QueryChanges(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query the change of branch %s of %s", data.Head, project)
+	}
+	if changes == nil || len(*changes) == 0 {
+		return nil, fmt.Errorf("no open change of branch %s found in %s after pushing it", data.Head, project)
+	}
+	change := (*changes)[0]
+
+	number := change.Number
+	state := git.PullRequestStateOpen
+	head := data.Head
+	base := data.Base
+	return &git.PullRequest{
+		URL:           p.changeURL(project, number),
+		Owner:         repo.Organisation,
+		Repo:          repo.Name,
+		Number:        &number,
+		State:         &state,
+		HeadRef:       &head,
+		BaseRef:       &base,
+		LastCommitSha: change.CurrentRevision,
+		Title:         change.Subject,
+	}, nil
 }
 
 func (p *GerritProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
@@ -139,25 +314,206 @@ func (p *GerritProvider) GetPullRequestCommits(owner string, repo *git.Repositor
 	return nil, nil
 }
 
+func (p *GerritProvider) ListPullRequests(owner string, repo *git.Repository, state string) ([]*git.PullRequest, error) {
+	return nil, fmt.Errorf("list pull requests: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListPullRequestsByAuthor(owner string, repo *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	return nil, fmt.Errorf("list pull requests by author: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetPullRequestByBranch(owner string, repo *git.Repository, branch string) (*git.PullRequest, error) {
+	return nil, fmt.Errorf("get pull request by branch: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ClosePullRequest(pr *git.PullRequest) error {
+	return fmt.Errorf("close pull request: %w", git.ErrNotSupported)
+}
+
+// PullRequestLastCommitStatus returns the overall state of the label votes on the current patch set of the change
 func (p *GerritProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
-	return "", nil
+	if pr.LastCommitSha == "" {
+		return "", fmt.Errorf("missing LastCommitSha for change %#v", pr)
+	}
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
+	if err != nil {
+		return "", err
+	}
+	return git.OverallState(statuses), nil
 }
 
+// ListCommitStatus returns a status for each label of the change whose current patch set is the commit, as gerrit
+// reports checks by voting on labels such as Verified. A label is a success once someone gives it the highest vote,
+// e.g. Verified +1 or Code-Review +2, a failure once someone gives it the lowest, and pending otherwise. Commits which
+// aren't the current patch set of a change have no statuses
 func (p *GerritProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
-	return nil, nil
+	project := projectName(org, repo)
+	changes, err := p.changesOfCommit(project, sha, "LABELS", "CURRENT_REVISION")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []*git.RepoStatus{}
+	for _, change := range changes {
+		if change.CurrentRevision != sha {
+			continue
+		}
+		names := make([]string, 0, len(change.Labels))
+		for name := range change.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			statuses = append(statuses, labelToRepoStatus(name, change.Labels[name], p.changeURL(project, change.Number)))
+		}
+	}
+	return statuses, nil
+}
+
+// changesOfCommit returns the changes of the project which have a patch set of the commit, with the additional
+// fields asked for
+func (p *GerritProvider) changesOfCommit(project string, sha string, fields ...string) ([]gerrit.ChangeInfo, error) {
+	options := &gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s commit:%s", project, sha)},
+		},
+		ChangeOptions: gerrit.ChangeOptions{
+			AdditionalFields: fields,
+		},
+	}
+	changes, _, err := p.Client.Changes.QueryChanges(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query the change of commit %s in %s", sha, project)
+	}
+	if changes == nil {
+		return []gerrit.ChangeInfo{}, nil
+	}
+	return *changes, nil
+}
+
+// labelToRepoStatus returns the status of the check a label of a change stands for
+func labelToRepoStatus(name string, label gerrit.LabelInfo, url string) *git.RepoStatus {
+	status := &git.RepoStatus{
+		ID:        name,
+		Context:   name,
+		URL:       url,
+		TargetURL: url,
+		State:     "pending",
+	}
+	switch {
+	case label.Rejected.AccountID != 0:
+		status.State = "failure"
+		status.Description = fmt.Sprintf("%s rejected by %s", name, label.Rejected.Name)
+	case label.Blocking:
+		status.State = "failure"
+		status.Description = fmt.Sprintf("%s is blocking", name)
+	case label.Approved.AccountID != 0:
+		status.State = "success"
+		status.Description = fmt.Sprintf("%s approved by %s", name, label.Approved.Name)
+	}
+	return status
 }
 
-// UpdateCommitStatus updates the status of a specified commit in a specified repo.
+func (p *GerritProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	return nil, fmt.Errorf("get commit status: %w", git.ErrNotSupported)
+}
+
+// verifiedVotes are the votes on the Verified label for the states of a commit status
+var verifiedVotes = map[string]string{
+	"success": "1",
+	"failure": "-1",
+	"error":   "-1",
+}
+
+// UpdateCommitStatus reviews the patch set of the commit, voting Verified +1 for a success, -1 for a failure or error
+// and 0 otherwise. The context and description of the status are the message of the review. Gerrit only has the one
+// Verified label, so statuses with different contexts still replace each other's vote
 func (p *GerritProvider) UpdateCommitStatus(org, repo, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
-	return nil, nil
+	if err := status.NormalizeContext(git.MaxStatusContextLength); err != nil {
+		return nil, err
+	}
+	project := projectName(org, repo)
+	changes, err := p.changesOfCommit(project, sha)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no change of commit %s found in %s", sha, project)
+	}
+	change := changes[0]
+
+	vote, ok := verifiedVotes[status.State]
+	if !ok {
+		vote = "0"
+	}
+	message := status.Context
+	if status.Description != "" {
+		message = fmt.Sprintf("%s: %s", status.Context, status.Description)
+	}
+	review := &gerrit.ReviewInput{
+		Message: message,
+		Labels:  map[string]string{"Verified": vote},
+	}
+	_, _, err = p.Client.Changes.SetReview(strconv.Itoa(change.Number), sha, review)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to review commit %s of change %d", sha, change.Number)
+	}
+
+	return &git.RepoStatus{
+		ID:          "Verified",
+		Context:     status.Context,
+		URL:         p.changeURL(project, change.Number),
+		TargetURL:   status.TargetURL,
+		State:       status.State,
+		Description: status.Description,
+	}, nil
 }
 
+// MergePullRequest submits the change numbered by the pull request. Gerrit merges the commit as it was reviewed, so
+// the message isn't used. Changes which can't be submitted yet fail with the labels they still need
 func (p *GerritProvider) MergePullRequest(pr *git.PullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for change %#v", pr)
+	}
+	changeID := strconv.Itoa(*pr.Number)
+
+	change, _, err := p.Client.Changes.GetChange(changeID, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"LABELS", "SUBMITTABLE"},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get change %s", changeID)
+	}
+	if !change.Submittable {
+		unmet := unmetLabels(change)
+		if len(unmet) == 0 {
+			return fmt.Errorf("change %s can't be submitted", changeID)
+		}
+		return fmt.Errorf("change %s can't be submitted as it needs the labels %s", changeID, strings.Join(unmet, ", "))
+	}
+
+	_, _, err = p.Client.Changes.SubmitChange(changeID, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to submit change %s", changeID)
+	}
+	merged := true
+	pr.Merged = &merged
 	return nil
 }
 
+// unmetLabels returns the sorted names of the labels of the change which block it or have yet to be approved
+func unmetLabels(change *gerrit.ChangeInfo) []string {
+	answer := []string{}
+	for name, label := range change.Labels {
+		if label.Blocking || (!label.Optional && label.Approved.AccountID == 0) {
+			answer = append(answer, name)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
 func (p *GerritProvider) CreateWebHook(data *git.WebhookArguments) error {
-	return nil
+	return data.Validate()
 }
 
 // UpdateWebHook update a webhook with the data specified.
@@ -165,6 +521,18 @@ func (p *GerritProvider) UpdateWebHook(data *git.WebhookArguments) error {
 	return nil
 }
 
+func (p *GerritProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	return nil, fmt.Errorf("list webhook deliveries: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return fmt.Errorf("redeliver webhook: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	return fmt.Errorf("ping webhook: %w", git.ErrNotSupported)
+}
+
 // ListWebHooks lists all webhooks for the specified repo.
 func (p *GerritProvider) ListWebHooks(org, repo string) ([]*git.WebhookArguments, error) {
 	return nil, nil
@@ -175,6 +543,10 @@ func (p *GerritProvider) ListOrganisations() ([]git.Organisation, error) {
 	return nil, nil
 }
 
+func (p *GerritProvider) ListTeams(org string) ([]*git.Team, error) {
+	return nil, fmt.Errorf("list teams: %w", git.ErrNotSupported)
+}
+
 func (p *GerritProvider) IsGitHub() bool {
 	return false
 }
@@ -196,12 +568,11 @@ func (p *GerritProvider) IsGerrit() bool {
 }
 
 func (p *GerritProvider) Kind() string {
-	return "gerrit"
+	return git.KindGerrit
 }
 
 func (p *GerritProvider) GetIssue(org string, name string, number int) (*git.Issue, error) {
-	log.Warn("Gerrit does not support issue tracking")
-	return nil, nil
+	return nil, fmt.Errorf("get issue: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) IssueURL(org string, name string, number int, isPull bool) string {
@@ -210,18 +581,15 @@ func (p *GerritProvider) IssueURL(org string, name string, number int, isPull bo
 }
 
 func (p *GerritProvider) SearchIssues(org string, name string, query string) ([]*git.Issue, error) {
-	log.Warn("Gerrit does not support issue tracking")
-	return nil, nil
+	return nil, fmt.Errorf("search issues: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*git.Issue, error) {
-	log.Warn("Gerrit does not support issue tracking")
-	return nil, nil
+	return nil, fmt.Errorf("search issues closed since: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) CreateIssue(owner string, repo string, issue *git.Issue) (*git.Issue, error) {
-	log.Warn("Gerrit does not support issue tracking")
-	return nil, nil
+	return nil, fmt.Errorf("create issue: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) HasIssues() bool {
@@ -229,13 +597,40 @@ func (p *GerritProvider) HasIssues() bool {
 	return false
 }
 
+func (p *GerritProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	return fmt.Errorf("set issues enabled: %w", git.ErrNotSupported)
+}
+
 func (p *GerritProvider) AddPRComment(pr *git.PullRequest, comment string) error {
 	return nil
 }
 
 func (p *GerritProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
-	log.Warn("Gerrit does not support issue tracking")
-	return nil
+	return fmt.Errorf("create issue comment: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return fmt.Errorf("add labels to issue: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return fmt.Errorf("remove label from issue: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	return nil, fmt.Errorf("list milestones: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	return nil, fmt.Errorf("create milestone: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) CloseMilestone(org string, name string, id int64) error {
+	return fmt.Errorf("close milestone: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	return nil, fmt.Errorf("list contributors: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
@@ -255,7 +650,7 @@ func (p *GerritProvider) Label() string {
 }
 
 func (p *GerritProvider) ServerURL() string {
-	return ""
+	return p.URL
 }
 
 func (p *GerritProvider) BranchArchiveURL(org string, name string, branch string) string {
@@ -263,32 +658,102 @@ func (p *GerritProvider) BranchArchiveURL(org string, name string, branch string
 }
 
 func (p *GerritProvider) CurrentUsername() string {
-	return ""
+	return p.Username
 }
 
 func (p *GerritProvider) UserInfo(username string) *git.User {
 	return nil
 }
 
+func (p *GerritProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return nil, fmt.Errorf("get users: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetCurrentUser() (*git.User, error) {
+	if p.Client == nil {
+		return nil, fmt.Errorf("no gerrit client configured")
+	}
+	account, _, err := p.Client.Accounts.GetAccount("self")
+	if err != nil {
+		return nil, err
+	}
+	return &git.User{
+		Login: account.Username,
+		Name:  account.Name,
+		Email: account.Email,
+	}, nil
+}
+
+func (p *GerritProvider) DeleteBranch(org string, name string, branch string) error {
+	return fmt.Errorf("delete branch: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	return fmt.Errorf("create branch: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	return fmt.Errorf("create tag: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	return nil, fmt.Errorf("get tag: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	return nil, fmt.Errorf("list repository tags: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	return 0, 0, fmt.Errorf("get ahead behind: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return fmt.Errorf("sync fork: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	return nil, fmt.Errorf("list pull request activity: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	return nil, fmt.Errorf("get pull request approval state: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	return nil, fmt.Errorf("get pull request stats: %w", git.ErrNotSupported)
+}
+
 func (p *GerritProvider) AddCollaborator(user string, organisation string, repo string) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gerrit. Please add user: %v as a collaborator to this project.\n", user)
 	return nil
 }
 
-func (p *GerritProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gerrit.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+func (p *GerritProvider) ListInvitations() ([]*git.Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation: %w", git.ErrNotSupported)
 }
 
-func (p *GerritProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gerrit.\n")
-	return &github.Response{}, nil
+func (p *GerritProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	return nil, fmt.Errorf("get file last commit: %w", git.ErrNotSupported)
+}
+
+func (p *GerritProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	return nil, fmt.Errorf("list branches containing commit: %w", git.ErrNotSupported)
 }
 
 func (p *GerritProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on gerrit")
+	return nil, fmt.Errorf("get content: %w", git.ErrNotSupported)
 }
 
+func (p *GerritProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	return "", fmt.Errorf("resolve ref: %w", git.ErrNotSupported)
+}
+
+// AccessTokenURL returns the settings page where users generate the HTTP password used as the token
 func (p *GerritProvider) AccessTokenURL() string {
-	return ""
+	return fmt.Sprintf("%s/settings/#HTTPCredentials", p.URL)
 }
@@ -1,10 +1,13 @@
 package gerrit
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/jx/pkg/util"
@@ -18,11 +21,35 @@ type GerritProviderTestSuite struct {
 	mux      *http.ServeMux
 	server   *httptest.Server
 	provider *GerritProvider
+
+	// changesQuery is the query of the last request to the changes API
+	changesQuery url.Values
+	// submitted are the changes which were submitted
+	submitted []string
+	// review is the last review posted
+	review map[string]interface{}
+}
+
+// pushRecordingGitter is a fake git client recording the branches fetched and pushed
+type pushRecordingGitter struct {
+	*git.GitFake
+	fetched []string
+	pushed  []string
+}
+
+func (g *pushRecordingGitter) FetchBranch(dir string, repo string, refspec string) error {
+	g.fetched = append(g.fetched, repo+" "+refspec)
+	return nil
+}
+
+func (g *pushRecordingGitter) ForcePushBranch(dir string, localBranch string, remoteBranch string) error {
+	g.pushed = append(g.pushed, localBranch+":"+remoteBranch)
+	return nil
 }
 
 var gerritRouter = util.Router{
-	"/a/projects/": util.MethodMap{
-		"GET": "list-projects.json",
+	"/a/accounts/self": util.MethodMap{
+		"GET": "accounts.self.json",
 	},
 	"/a/projects/test-org%2Ftest-user/": util.MethodMap{
 		"PUT": "create-project.json",
@@ -37,9 +64,66 @@ func (suite *GerritProviderTestSuite) SetupSuite() {
 	for path, methodMap := range gerritRouter {
 		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/gerrit", methodMap))
 	}
+	// the projects listed depend on the prefix
+	listProjects := util.GetMockAPIResponseFromFile("test_data/gerrit", util.MethodMap{"GET": "list-projects.json"})
+	listOrgProjects := util.GetMockAPIResponseFromFile("test_data/gerrit", util.MethodMap{"GET": "list-projects.test-org.json"})
+	suite.mux.HandleFunc("/a/projects/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("p") == "test-org/" {
+			listOrgProjects(w, r)
+		} else {
+			listProjects(w, r)
+		}
+	})
+
+	// change 3965 has every label it needs, change 3966 still needs a review
+	suite.mux.HandleFunc("/a/changes/3965", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `)]}'
+{"project": "test-org/test-repo", "branch": "master", "status": "NEW", "_number": 3965, "submittable": true,
+  "labels": {"Code-Review": {"approved": {"_account_id": 1000096}}, "Verified": {"approved": {"_account_id": 1000097}}}}`)
+	})
+	suite.mux.HandleFunc("/a/changes/3966", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `)]}'
+{"project": "test-org/test-repo", "branch": "master", "status": "NEW", "_number": 3966,
+  "labels": {"Code-Review": {}, "Verified": {"approved": {"_account_id": 1000097}}, "Lint": {"optional": true}}}`)
+	})
+	suite.mux.HandleFunc("/a/changes/3966/revisions/184ebe53805e102605d11f6b143486d15c23a09c/review", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.review = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.review))
+		fmt.Fprint(w, `)]}'
+{"labels": {}}`)
+	})
+	suite.mux.HandleFunc("/a/changes/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/submit") {
+			suite.Require().Equal(http.MethodPost, r.Method)
+			change := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/a/changes/"), "/submit")
+			suite.submitted = append(suite.submitted, change)
+			fmt.Fprintf(w, `)]}'
+{"_number": %s, "status": "MERGED"}`, change)
+			return
+		}
+		suite.changesQuery = r.URL.Query()
+		if strings.Contains(suite.changesQuery.Get("q"), "commit:") {
+			fmt.Fprint(w, `)]}'
+[{"project": "test-org/test-repo", "branch": "master", "status": "NEW", "_number": 3966,
+  "current_revision": "184ebe53805e102605d11f6b143486d15c23a09c",
+  "labels": {
+    "Code-Review": {"recommended": {"_account_id": 1000096, "name": "Re Viewer"}},
+    "Lint": {"rejected": {"_account_id": 1000098, "name": "Lint Bot"}, "blocking": true},
+    "Verified": {"approved": {"_account_id": 1000097, "name": "CI Bot"}}}},
+ {"project": "test-org/test-repo", "branch": "release", "status": "NEW", "_number": 3967,
+  "current_revision": "2b1a0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b",
+  "labels": {"Verified": {"rejected": {"_account_id": 1000097, "name": "CI Bot"}}}}]`)
+			return
+		}
+		fmt.Fprint(w, `)]}'
+[{"id": "test-org%2Ftest-repo~master~I8473b95934b5732ac55d26311a706c9c2bde9940", "project": "test-org/test-repo",
+  "branch": "master", "topic": "feature", "subject": "Add a feature", "status": "NEW", "_number": 3965,
+  "current_revision": "184ebe53805e102605d11f6b143486d15c23a09c"}]`)
+	})
 
 	gitter := git.NewGitCLI()
-	provider, err := NewGerritProvider(gitter)
+	provider, err := NewProvider("test-user", suite.server.URL, "test", gitter)
 
 	suite.Require().NotNil(provider)
 	suite.Require().Nil(err)
@@ -51,6 +135,39 @@ func (suite *GerritProviderTestSuite) SetupSuite() {
 	suite.Require().NotNil(suite.provider.Client)
 }
 
+func (suite *GerritProviderTestSuite) TestServerURLAndUsername() {
+	suite.Require().Equal(suite.server.URL, suite.provider.ServerURL())
+	suite.Require().Equal("test-user", suite.provider.CurrentUsername())
+}
+
+func (suite *GerritProviderTestSuite) TestAccessTokenURL() {
+	suite.Require().Equal(suite.server.URL+"/settings/#HTTPCredentials", suite.provider.AccessTokenURL())
+}
+
+func (suite *GerritProviderTestSuite) TestNewProvider() {
+	provider, err := NewProvider("test-user", "https://gerrit.example.com/", "secret", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	gerritProvider, ok := provider.(*GerritProvider)
+	suite.Require().True(ok)
+	suite.Require().NotNil(gerritProvider.Client)
+	suite.Require().True(gerritProvider.Client.Authentication.HasAuth())
+	suite.Require().Equal("https://gerrit.example.com", provider.ServerURL())
+	suite.Require().Equal("test-user", provider.CurrentUsername())
+	suite.Require().Equal("https://gerrit.example.com/settings/#HTTPCredentials", provider.AccessTokenURL())
+
+	// without a username the server is used anonymously
+	provider, err = NewProvider("", "https://gerrit.example.com", "", git.NewGitCLI())
+	suite.Require().Nil(err)
+	suite.Require().False(provider.(*GerritProvider).Client.Authentication.HasAuth())
+}
+
+func (suite *GerritProviderTestSuite) TestKind() {
+	suite.Require().Equal(git.KindGerrit, suite.provider.Kind())
+	suite.Require().Contains(git.KindGits, suite.provider.Kind())
+	suite.Require().True(suite.provider.IsGerrit())
+}
+
 func (suite *GerritProviderTestSuite) TestListRepositories() {
 	repos, err := suite.provider.ListRepositories("")
 
@@ -71,6 +188,23 @@ func (suite *GerritProviderTestSuite) TestListRepositories() {
 
 }
 
+func (suite *GerritProviderTestSuite) TestListRepositoriesInOrg() {
+	repos, err := suite.provider.ListRepositories("test-org")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(2, len(repos))
+
+	var repoNames []string
+	for _, repo := range repos {
+		suite.Require().Equal("test-org", repo.Organisation)
+		repoNames = append(repoNames, repo.Name)
+	}
+	sort.Strings(repoNames)
+
+	suite.Require().Equal("another-repo", repoNames[0])
+	suite.Require().Equal("test-repo", repoNames[1])
+}
+
 func (suite *GerritProviderTestSuite) TestCreateRepository() {
 	repo, err := suite.provider.CreateRepository("test-org", "test-user", false)
 	suite.T().Log(err)
@@ -78,7 +212,16 @@ func (suite *GerritProviderTestSuite) TestCreateRepository() {
 	suite.Require().Nil(err)
 	suite.Require().Equal("test-org/test-repo", repo.Name)
 	suite.Require().Equal(fmt.Sprintf("%s/test-org/test-repo", suite.server.URL), repo.CloneURL)
-	suite.Require().Equal(fmt.Sprintf("%s:test-org/test-repo", suite.server.URL), repo.SSHURL)
+	suite.Require().Equal("ssh://127.0.0.1:29418/test-org/test-repo", repo.SSHURL)
+}
+
+func (suite *GerritProviderTestSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("test-user", user.Login)
+	suite.Require().Equal("Test User", user.Name)
+	suite.Require().Equal("test-user@example.com", user.Email)
 }
 
 func TestGerritProviderTestSuite(t *testing.T) {
@@ -89,6 +232,161 @@ func TestGerritProviderTestSuite(t *testing.T) {
 	}
 }
 
+func (suite *GerritProviderTestSuite) TestGetCommitStatus() {
+	status, err := suite.provider.GetCommitStatus("test-org", "test-user", "6dcb09b5", "ci")
+	suite.Require().Nil(status)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GerritProviderTestSuite) TestRepositoryChangesNotSupported() {
+	err := suite.provider.DeleteRepository("test-org", "test-repo")
+	suite.Require().True(git.IsNotSupported(err))
+
+	repo, err := suite.provider.ForkRepository("test-org", "test-repo", "other-org")
+	suite.Require().Nil(repo)
+	suite.Require().True(git.IsNotSupported(err))
+
+	repo, err = suite.provider.RenameRepository("test-org", "test-repo", "renamed-repo")
+	suite.Require().Nil(repo)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GerritProviderTestSuite) TestIssuesNotSupported() {
+	_, err := suite.provider.GetIssue("test-org", "test-user", 1)
+	suite.Require().True(git.IsNotSupported(err))
+
+	_, err = suite.provider.SearchIssues("test-org", "test-user", "")
+	suite.Require().True(git.IsNotSupported(err))
+
+	_, err = suite.provider.CreateIssue("test-org", "test-user", &git.Issue{Title: "Broken"})
+	suite.Require().True(git.IsNotSupported(err))
+
+	err = suite.provider.CreateIssueComment("test-org", "test-user", 1, "hello")
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GerritProviderTestSuite) TestCreatePullRequest() {
+	gitter := &pushRecordingGitter{GitFake: &git.GitFake{}}
+	provider := *suite.provider
+	provider.Git = gitter
+
+	pr, err := provider.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Add a feature",
+		Head:       "feature",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: "test-org", Name: "test-repo"},
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"origin feature"}, gitter.fetched)
+	suite.Require().Equal([]string{"FETCH_HEAD:refs/for/master%topic=feature"}, gitter.pushed)
+	suite.Require().Len(gitter.Remotes, 1)
+	suite.Require().Equal(fmt.Sprintf("http://test-user:test@%s/test-org/test-repo", strings.TrimPrefix(suite.server.URL, "http://")), gitter.Remotes[0].URL)
+	suite.Require().Equal(`project:test-org/test-repo branch:master topic:"feature" status:open`, suite.changesQuery.Get("q"))
+
+	suite.Require().Equal(3965, *pr.Number)
+	suite.Require().Equal(suite.server.URL+"/c/test-org/test-repo/+/3965", pr.URL)
+	suite.Require().Equal("184ebe53805e102605d11f6b143486d15c23a09c", pr.LastCommitSha)
+	suite.Require().Equal("feature", *pr.HeadRef)
+	suite.Require().Equal("master", *pr.BaseRef)
+	suite.Require().Equal(git.PullRequestStateOpen, *pr.State)
+}
+
+func (suite *GerritProviderTestSuite) TestCreatePullRequestWithoutGit() {
+	provider := *suite.provider
+	provider.Git = nil
+
+	_, err := provider.CreatePullRequest(&git.PullRequestArguments{
+		Head:       "feature",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: "test-org", Name: "test-repo"},
+	})
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GerritProviderTestSuite) TestListCommitStatus() {
+	statuses, err := suite.provider.ListCommitStatus("test-org", "test-repo", "184ebe53805e102605d11f6b143486d15c23a09c")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("project:test-org/test-repo commit:184ebe53805e102605d11f6b143486d15c23a09c", suite.changesQuery.Get("q"))
+	// the change whose older patch set is the commit is left out
+	suite.Require().Len(statuses, 3)
+	suite.Require().Equal("Code-Review", statuses[0].Context)
+	suite.Require().Equal("pending", statuses[0].State)
+	suite.Require().Equal("Lint", statuses[1].Context)
+	suite.Require().Equal("failure", statuses[1].State)
+	suite.Require().Equal("Lint rejected by Lint Bot", statuses[1].Description)
+	suite.Require().Equal("Verified", statuses[2].Context)
+	suite.Require().Equal("success", statuses[2].State)
+	suite.Require().Equal(suite.server.URL+"/c/test-org/test-repo/+/3966", statuses[2].TargetURL)
+
+	suite.Require().Equal("failure", git.OverallState(statuses))
+}
+
+func (suite *GerritProviderTestSuite) TestPullRequestLastCommitStatus() {
+	pr := &git.PullRequest{
+		Owner:         "test-org",
+		Repo:          "test-repo",
+		LastCommitSha: "184ebe53805e102605d11f6b143486d15c23a09c",
+	}
+	state, err := suite.provider.PullRequestLastCommitStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("failure", state)
+}
+
+func (suite *GerritProviderTestSuite) TestUpdateCommitStatus() {
+	sha := "184ebe53805e102605d11f6b143486d15c23a09c"
+	votes := map[string]string{
+		"success": "1",
+		"failure": "-1",
+		"error":   "-1",
+		"pending": "0",
+	}
+	for state, vote := range votes {
+		status, err := suite.provider.UpdateCommitStatus("test-org", "test-repo", sha, &git.RepoStatus{
+			Context:     "ci/build",
+			State:       state,
+			Description: "The build finished",
+		})
+
+		suite.Require().Nil(err, state)
+		suite.Require().Equal("ci/build: The build finished", suite.review["message"], state)
+		suite.Require().Equal(map[string]interface{}{"Verified": vote}, suite.review["labels"], state)
+		suite.Require().Equal(state, status.State)
+		suite.Require().Equal("ci/build", status.Context)
+		suite.Require().Equal(suite.server.URL+"/c/test-org/test-repo/+/3966", status.URL)
+	}
+
+	_, err := suite.provider.UpdateCommitStatus("test-org", "test-repo", sha, &git.RepoStatus{State: "success"})
+	suite.Require().NotNil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestMergePullRequest() {
+	suite.submitted = nil
+	number := 3965
+	pr := &git.PullRequest{Owner: "test-org", Repo: "test-repo", Number: &number}
+
+	err := suite.provider.MergePullRequest(pr, "Merge the feature")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"3965"}, suite.submitted)
+	suite.Require().True(*pr.Merged)
+}
+
+func (suite *GerritProviderTestSuite) TestMergePullRequestNotSubmittable() {
+	suite.submitted = nil
+	number := 3966
+	pr := &git.PullRequest{Owner: "test-org", Repo: "test-repo", Number: &number}
+
+	err := suite.provider.MergePullRequest(pr, "Merge the feature")
+
+	suite.Require().NotNil(err)
+	suite.Require().Equal("change 3966 can't be submitted as it needs the labels Code-Review", err.Error())
+	suite.Require().Empty(suite.submitted)
+	suite.Require().Nil(pr.Merged)
+}
+
 func (suite *GerritProviderTestSuite) TearDownSuite() {
 	suite.server.Close()
 }
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"time"
+)
+
+// ApprovalState is who approved a pull request and whether it has the approvals it needs to be merged
+type ApprovalState struct {
+	// Approved is true when the pull request has every approval it needs
+	Approved bool
+	// ApprovalsLeft is the number of approvals the pull request still needs
+	ApprovalsLeft int
+	ApprovedBy    []*User
+	// LastApprovedAt is when the pull request was last approved, or nil if it never was
+	LastApprovedAt *time.Time
+	// ResetOnPush is true when pushing commits to the pull request removes its approvals, which GitLab projects can
+	// be configured to do
+	ResetOnPush bool
+}
+
+// ApprovalOutdated returns true if a commit of the pull request was made after its last approval. Only commits
+// whose CommittedAt is known are compared
+func ApprovalOutdated(state *ApprovalState, commits []*Commit) bool {
+	if state == nil || state.LastApprovedAt == nil {
+		return false
+	}
+	for _, commit := range commits {
+		if commit.CommittedAt != nil && commit.CommittedAt.After(*state.LastApprovedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsReapproval returns true if commits were pushed to the pull request after it was approved and the provider
+// removes approvals on push, as on GitLab projects with "reset approvals on push" enabled. Such a pull request is not
+// merged until it is approved again, so auto-merge should ask for a new approval rather than wait for it
+func NeedsReapproval(provider Provider, pr *PullRequest) (bool, error) {
+	state, err := provider.GetPullRequestApprovalState(pr)
+	if err != nil {
+		return false, err
+	}
+	if !state.ResetOnPush {
+		return false, nil
+	}
+	commits, err := provider.GetPullRequestCommits(pr.Owner, &Repository{Name: pr.Repo}, *pr.Number)
+	if err != nil {
+		return false, err
+	}
+	return ApprovalOutdated(state, commits), nil
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalOutdated(t *testing.T) {
+	t.Parallel()
+
+	approvedAt := time.Date(2018, 6, 4, 11, 0, 0, 0, time.UTC)
+	before := approvedAt.Add(-time.Hour)
+	after := approvedAt.Add(time.Hour)
+	state := &ApprovalState{LastApprovedAt: &approvedAt}
+
+	assert.False(t, ApprovalOutdated(state, []*Commit{{SHA: "a1", CommittedAt: &before}}))
+	assert.True(t, ApprovalOutdated(state, []*Commit{{SHA: "a1", CommittedAt: &before}, {SHA: "a2", CommittedAt: &after}}))
+	// commits without a date can't outdate the approval
+	assert.False(t, ApprovalOutdated(state, []*Commit{{SHA: "a1"}}))
+	// neither can anything outdate a missing approval
+	assert.False(t, ApprovalOutdated(&ApprovalState{}, []*Commit{{SHA: "a2", CommittedAt: &after}}))
+}
+
+func TestNeedsReapproval(t *testing.T) {
+	t.Parallel()
+
+	approvedAt := time.Date(2018, 6, 4, 11, 0, 0, 0, time.UTC)
+	pushedAt := approvedAt.Add(time.Hour)
+	provider := NewFakeProvider(NewFakeRepository("org", "repo"))
+	repo := provider.Repositories["org"][0]
+	pr, err := provider.CreatePullRequest(&PullRequestArguments{
+		Repository: &Repository{Organisation: "org", Name: "repo"},
+		Title:      "Add a feature",
+	})
+	assert.NoError(t, err)
+	fakePR := repo.PullRequests[*pr.Number]
+	fakePR.Commits = []*FakeCommit{{Commit: &Commit{SHA: "a2", CommittedAt: &pushedAt}}}
+	fakePR.ApprovalState = &ApprovalState{LastApprovedAt: &approvedAt}
+
+	needed, err := NeedsReapproval(provider, pr)
+	assert.NoError(t, err)
+	assert.False(t, needed)
+
+	fakePR.ApprovalState.ResetOnPush = true
+	needed, err = NeedsReapproval(provider, pr)
+	assert.NoError(t, err)
+	assert.True(t, needed)
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersPaths are where GetCodeOwners looks for the CODEOWNERS file, in the order GitHub does
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwnerRule is a line of a CODEOWNERS file, giving the owners of the paths matching the pattern. A rule without
+// owners leaves the paths it matches without an owner
+type CodeOwnerRule struct {
+	Pattern string
+	// Owners are the users, teams and emails the rule lists, e.g. "@octocat", "@org/team" or "dev@example.com"
+	Owners []string
+	// Line is the number of the line of the rule in the file
+	Line int
+
+	regexp *regexp.Regexp
+}
+
+// Matches returns true if the path of a file, relative to the root of the repository, matches the pattern of the rule
+func (r *CodeOwnerRule) Matches(path string) bool {
+	return r.regexp.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// GetCodeOwners reads the CODEOWNERS file of the repository at the ref from the first of CodeOwnersPaths which has
+// one, and parses it into rules. A repository without a CODEOWNERS file has no rules, while a missing repository gives
+// an error wrapping ErrNotFound
+func GetCodeOwners(provider Provider, org string, name string, ref string) ([]*CodeOwnerRule, error) {
+	for _, path := range CodeOwnersPaths {
+		file, err := provider.GetContent(org, name, path, ref)
+		if err != nil {
+			if IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s from %s/%s: %w", path, org, name, err)
+		}
+		if file == nil {
+			continue
+		}
+		rules, err := ParseCodeOwners(file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s of %s/%s: %w", path, org, name, err)
+		}
+		return rules, nil
+	}
+	// a missing repository is reported as a missing file by some providers, so it is told apart here
+	if _, err := provider.GetRepository(org, name); err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", org, name, err)
+	}
+	return []*CodeOwnerRule{}, nil
+}
+
+// ParseCodeOwners parses the rules of a CODEOWNERS file. Patterns follow the gitignore syntax CODEOWNERS uses,
+// except for negation and character ranges which CODEOWNERS doesn't support
+func ParseCodeOwners(content string) ([]*CodeOwnerRule, error) {
+	rules := []*CodeOwnerRule{}
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		rule := &CodeOwnerRule{
+			Pattern: strings.Replace(fields[0], `\#`, "#", -1),
+			Owners:  []string{},
+			Line:    i + 1,
+		}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		if strings.HasPrefix(rule.Pattern, "!") || strings.ContainsAny(rule.Pattern, "[]") {
+			return nil, fmt.Errorf("line %d: pattern %s is not supported in CODEOWNERS", rule.Line, rule.Pattern)
+		}
+		expr, err := regexp.Compile(codeOwnersPatternToRegexp(rule.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %s: %w", rule.Line, rule.Pattern, err)
+		}
+		rule.regexp = expr
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FindCodeOwners returns the owners of the path given by the last rule matching it, as later rules take precedence,
+// or nil if no rule matches
+func FindCodeOwners(rules []*CodeOwnerRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Matches(path) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPatternToRegexp translates a pattern into a regular expression matching the paths of files. Patterns
+// with a slash other than a trailing one are relative to the root, others match at any depth. A pattern matches the
+// files under the directories it matches too, unless its last part has a wildcard: docs/* only matches the files
+// directly in docs, as on GitHub
+func codeOwnersPatternToRegexp(pattern string) string {
+	directory := strings.HasSuffix(pattern, "/")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	expr := "^"
+	if !anchored {
+		expr += "(.*/)?"
+	}
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			expr += "(.*/)?"
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			expr += ".*"
+			i++
+		case c == '*':
+			expr += "[^/]*"
+		case c == '?':
+			expr += "[^/]"
+		default:
+			expr += regexp.QuoteMeta(string(c))
+		}
+	}
+
+	lastPart := trimmed[strings.LastIndex(trimmed, "/")+1:]
+	switch {
+	case directory:
+		expr += "/.*"
+	case !strings.ContainsAny(lastPart, "*?"):
+		expr += "(/.*)?"
+	}
+	return expr + "$"
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCodeOwners = `# default owners
+*                   @global-owner
+
+*.js                @js-owner  # inline comment
+**/logs             @logs-owner
+/build/logs/        @doctocat
+docs/*              docs@example.com
+apps/               @octocat
+/scripts/**/test    @test-owner
+/empty
+`
+
+func TestParseCodeOwners(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseCodeOwners(testCodeOwners)
+	require.NoError(t, err)
+	require.Len(t, rules, 8)
+
+	assert.Equal(t, "*.js", rules[1].Pattern)
+	assert.Equal(t, []string{"@js-owner"}, rules[1].Owners)
+	assert.Equal(t, 4, rules[1].Line)
+	assert.Equal(t, []string{}, rules[7].Owners)
+
+	_, err = ParseCodeOwners("!vendor/ @someone")
+	assert.Error(t, err)
+	_, err = ParseCodeOwners("*.[ch] @someone")
+	assert.Error(t, err)
+}
+
+func TestFindCodeOwners(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseCodeOwners(testCodeOwners)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		path   string
+		owners []string
+	}{
+		{"README.md", []string{"@global-owner"}},
+		{"src/app.js", []string{"@js-owner"}},
+		{"build/logs/today.log", []string{"@doctocat"}},
+		{"src/build/logs/today.log", []string{"@logs-owner"}},
+		{"docs/getting-started.md", []string{"docs@example.com"}},
+		{"docs/build-app/troubleshooting.md", []string{"@global-owner"}},
+		// a slash in the middle anchors the pattern to the root
+		{"src/docs/index.md", []string{"@global-owner"}},
+		{"apps/web/main.go", []string{"@octocat"}},
+		{"src/apps/main.go", []string{"@octocat"}},
+		{"scripts/test", []string{"@test-owner"}},
+		{"scripts/ci/unit/test", []string{"@test-owner"}},
+		{"/deploy/logs/app.log", []string{"@logs-owner"}},
+		{"empty/file.txt", []string{}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.owners, FindCodeOwners(rules, tc.path), tc.path)
+	}
+	assert.Nil(t, FindCodeOwners(rules[1:2], "main.go"))
+}
+
+func TestGetCodeOwners(t *testing.T) {
+	t.Parallel()
+
+	repo := NewFakeRepository("test-org", "test-repo")
+	provider := NewFakeProvider(repo)
+
+	rules, err := GetCodeOwners(provider, "test-org", "test-repo", "master")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	repo.Files = map[string]string{
+		"docs/CODEOWNERS": "* @docs-owner\n",
+		"CODEOWNERS":      "* @root-owner\n",
+	}
+	rules, err = GetCodeOwners(provider, "test-org", "test-repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@root-owner"}, FindCodeOwners(rules, "main.go"))
+
+	repo.Files[".github/CODEOWNERS"] = "* @github-owner\n"
+	rules, err = GetCodeOwners(provider, "test-org", "test-repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@github-owner"}, FindCodeOwners(rules, "main.go"))
+
+	_, err = GetCodeOwners(provider, "test-org", "missing", "master")
+	assert.True(t, IsNotFound(err))
+}
@@ -11,6 +11,8 @@ const (
 	KindGitlab = "gitlab"
 	// KindGitHub git kind for github
 	KindGitHub = "github"
+	// KindGerrit git kind for gerrit
+	KindGerrit = "gerrit"
 	// KindGitFake git kind for fake git
 	KindGitFake = "fakegit"
 	// KindUnknown git kind for unknown git
@@ -24,5 +26,5 @@ const (
 )
 
 var (
-	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
+	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGerrit, KindGitea, KindGitHub, KindGitlab}
 )
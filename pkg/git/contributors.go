@@ -0,0 +1,20 @@
+package git
+
+import (
+	"sort"
+)
+
+// Contributor is a user who has committed to a repository
+type Contributor struct {
+	User *User
+	// Contributions counts the commits of the user
+	Contributions int
+}
+
+// SortContributors sorts contributors by their contributions, most first, keeping the order of contributors
+// with as many contributions
+func SortContributors(contributors []*Contributor) {
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].Contributions > contributors[j].Contributions
+	})
+}
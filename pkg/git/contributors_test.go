@@ -0,0 +1,25 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortContributors(t *testing.T) {
+	t.Parallel()
+
+	contributors := []*Contributor{
+		{User: &User{Login: "few"}, Contributions: 1},
+		{User: &User{Login: "most"}, Contributions: 12},
+		{User: &User{Login: "some"}, Contributions: 5},
+		{User: &User{Login: "other"}, Contributions: 5},
+	}
+	SortContributors(contributors)
+
+	logins := []string{}
+	for _, contributor := range contributors {
+		logins = append(logins, contributor.User.Login)
+	}
+	assert.Equal(t, []string{"most", "some", "other", "few"}, logins)
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// GitUsernameEnvVar is the environment variable holding the git username for any kind of git provider
+	GitUsernameEnvVar = "GIT_USERNAME"
+	// GitAPITokenEnvVar is the environment variable holding the API token for any kind of git provider
+	GitAPITokenEnvVar = "GIT_API_TOKEN"
+)
+
+// tokenUsernames are the usernames which authenticate git over HTTPS with just a token, keyed by the kind of git
+// provider. e.g. GitHub app tokens need x-access-token and GitLab CI job tokens need oauth2
+var tokenUsernames = map[string]string{
+	KindGitHub:         "x-access-token",
+	KindGitlab:         "oauth2",
+	KindBitBucketCloud: "x-token-auth",
+}
+
+// TokenUsername returns the username to use with a token when there is no username for the kind of git provider, or
+// an empty string if the kind has none
+func TokenUsername(kind string) string {
+	return tokenUsernames[kind]
+}
+
+// PushUsername returns the username to push to a provider of the given kind with. When there is a token but no
+// username it is the TokenUsername of the options, or else of the kind, as git can't send a password without a
+// username
+func (o ProviderOptions) PushUsername(kind string, username string, token string) string {
+	if username != "" || token == "" {
+		return username
+	}
+	if o.TokenUsername != "" {
+		return o.TokenUsername
+	}
+	return TokenUsername(kind)
+}
+
+// createPushURL returns the clone URL with the username and token as its user info
+func createPushURL(cloneURL, username, token string) (string, error) {
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		// already a git/ssh url?
+		return cloneURL, nil
+	}
+	if username != "" || token != "" {
+		u.User = url.UserPassword(username, token)
+		return u.String(), nil
+	}
+	return cloneURL, nil
+}
+
+// CredentialEnvVars returns the environment variables checked for the username and API token of the given
+// kind of git provider, in the order they are checked. e.g. GITHUB_USERNAME and GITHUB_API_TOKEN are checked
+// before GIT_USERNAME and GIT_API_TOKEN for github
+func CredentialEnvVars(kind string) []string {
+	answer := []string{}
+	if kind != "" {
+		prefix := strings.ToUpper(kind)
+		answer = append(answer, prefix+"_USERNAME", prefix+"_API_TOKEN")
+	}
+	return append(answer, GitUsernameEnvVar, GitAPITokenEnvVar)
+}
+
+// MissingCredentialsError returns the error to give when no user authentication was found for the git server
+// and it can't be asked for, e.g. in batch mode. It names the server and the environment variables checked so
+// CI users know what to set
+func MissingCredentialsError(kind string, serverURL string) error {
+	envVars := CredentialEnvVars(kind)
+	if len(envVars) == 2 {
+		return fmt.Errorf("no user authentication found for the git server %s: set the %s and %s environment variables",
+			serverURL, envVars[0], envVars[1])
+	}
+	return fmt.Errorf("no user authentication found for the git server %s: set the %s and %s environment variables, or %s and %s",
+		serverURL, envVars[0], envVars[1], envVars[2], envVars[3])
+}
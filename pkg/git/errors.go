@@ -0,0 +1,36 @@
+package git
+
+import "errors"
+
+// ErrNotSupported is returned, usually wrapped, by providers for operations their server can't do
+var ErrNotSupported = errors.New("operation not supported by this provider")
+
+// ErrNotFound is returned, usually wrapped, by providers when the repository or other resource asked for doesn't
+// exist
+var ErrNotFound = errors.New("not found")
+
+// ErrDefaultBranch is returned, usually wrapped, when asked to delete the default branch of a repository
+var ErrDefaultBranch = errors.New("the default branch of a repository can't be deleted")
+
+// ErrAlreadyExists is returned, usually wrapped, by providers when asked to create a resource which already exists
+var ErrAlreadyExists = errors.New("already exists")
+
+// IsNotSupported returns true if the error says the provider doesn't support the operation
+func IsNotSupported(err error) bool {
+	return errors.Is(err, ErrNotSupported)
+}
+
+// IsNotFound returns true if the error says the resource asked for doesn't exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsDefaultBranch returns true if the error says the branch is the default branch of the repository
+func IsDefaultBranch(err error) bool {
+	return errors.Is(err, ErrDefaultBranch)
+}
+
+// IsAlreadyExists returns true if the error says the resource asked to be created already exists
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists)
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNotSupported(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsNotSupported(ErrNotSupported))
+	assert.True(t, IsNotSupported(fmt.Errorf("list releases: %w", ErrNotSupported)))
+	assert.False(t, IsNotSupported(errors.New("list releases failed")))
+	assert.False(t, IsNotSupported(nil))
+}
+
+func TestIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsNotFound(ErrNotFound))
+	assert.True(t, IsNotFound(fmt.Errorf("repository org/name: %w", ErrNotFound)))
+	assert.False(t, IsNotFound(ErrNotSupported))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestIsDefaultBranch(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsDefaultBranch(fmt.Errorf("delete branch master of org/name: %w", ErrDefaultBranch)))
+	assert.False(t, IsDefaultBranch(ErrNotFound))
+	assert.False(t, IsDefaultBranch(nil))
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsAlreadyExists(fmt.Errorf("create branch feature of org/name: %w", ErrAlreadyExists)))
+	assert.False(t, IsAlreadyExists(ErrNotFound))
+	assert.False(t, IsAlreadyExists(nil))
+}
+
+func TestFakeProviderDeleteBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := NewFakeRepository("test-org", "test-repo")
+	provider := NewFakeProvider(repo)
+
+	assert.NoError(t, provider.DeleteBranch("test-org", "test-repo", "feature"))
+	assert.Equal(t, []string{"feature"}, repo.DeletedBranches)
+	assert.True(t, IsDefaultBranch(provider.DeleteBranch("test-org", "test-repo", "master")))
+	assert.True(t, IsNotFound(provider.DeleteBranch("test-org", "missing", "feature")))
+}
+
+func TestFakeProviderCreateBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := NewFakeRepository("test-org", "test-repo")
+	repo.Tags = map[string]*GitTag{"v1.0": {Name: "v1.0", SHA: "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}}
+	provider := NewFakeProvider(repo)
+
+	assert.NoError(t, provider.CreateBranch("test-org", "test-repo", "feature", "v1.0"))
+	assert.Equal(t, map[string]string{"feature": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}, repo.Branches)
+	sha, err := provider.ResolveRef("test-org", "test-repo", "feature")
+	assert.NoError(t, err)
+	assert.Equal(t, "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", sha)
+
+	assert.True(t, IsAlreadyExists(provider.CreateBranch("test-org", "test-repo", "feature", "v1.0")))
+	assert.True(t, IsAlreadyExists(provider.CreateBranch("test-org", "test-repo", "master", "v1.0")))
+
+	// a deleted branch can be created again
+	assert.NoError(t, provider.DeleteBranch("test-org", "test-repo", "feature"))
+	assert.Empty(t, repo.Branches)
+	assert.NoError(t, provider.CreateBranch("test-org", "test-repo", "feature", "v1.0"))
+}
+
+func TestFakeProviderSetRepositoryTopics(t *testing.T) {
+	t.Parallel()
+
+	repo := NewFakeRepository("test-org", "test-repo")
+	provider := NewFakeProvider(repo)
+
+	assert.NoError(t, provider.SetRepositoryTopics("test-org", "test-repo", []string{"git", "go"}))
+	found, err := provider.GetRepository("test-org", "test-repo")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"git", "go"}, found.Topics)
+	assert.True(t, IsNotFound(provider.SetRepositoryTopics("test-org", "missing", nil)))
+}
+
+func TestFakeProviderIssueLabels(t *testing.T) {
+	t.Parallel()
+
+	repo := NewFakeRepository("test-org", "test-repo")
+	provider := NewFakeProvider(repo)
+	issue, err := provider.CreateIssue("test-org", "test-repo", &Issue{Title: "Something is broken"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, provider.AddLabelsToIssue("test-org", "test-repo", *issue.Number, []string{"bug", "triage"}))
+	assert.NoError(t, provider.AddLabelsToIssue("test-org", "test-repo", *issue.Number, []string{"bug"}))
+	assert.Equal(t, ToLabels([]string{"bug", "triage"}), issue.Labels)
+
+	assert.NoError(t, provider.RemoveLabelFromIssue("test-org", "test-repo", *issue.Number, "triage"))
+	assert.NoError(t, provider.RemoveLabelFromIssue("test-org", "test-repo", *issue.Number, "missing"))
+	assert.Equal(t, ToLabels([]string{"bug"}), issue.Labels)
+
+	assert.True(t, IsNotFound(provider.AddLabelsToIssue("test-org", "test-repo", 42, []string{"bug"})))
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultForkPollConfig is how providers poll for forks unless they are configured otherwise
+var DefaultForkPollConfig = ForkPollConfig{
+	Interval: 5 * time.Second,
+	Timeout:  time.Minute,
+}
+
+// ForkPollConfig is how often a provider checks whether a fork it has asked for is ready, and how long it waits
+// for it before giving up. Zero durations take the values of DefaultForkPollConfig
+type ForkPollConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Poll calls ready after every interval until it succeeds. Once the timeout has passed it returns the last error
+// of ready, and it returns early with the error of the context of the options once that is done
+func (c ForkPollConfig) Poll(options ProviderOptions, ready func() error) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultForkPollConfig.Interval
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultForkPollConfig.Timeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := options.Sleep(interval); err != nil {
+			return err
+		}
+		err := ready()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s: %s", timeout, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForkPollConfigPoll(t *testing.T) {
+	t.Parallel()
+
+	config := ForkPollConfig{Interval: time.Millisecond, Timeout: time.Second}
+	calls := 0
+	err := config.Poll(NewProviderOptions(), func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	config = ForkPollConfig{Interval: time.Millisecond, Timeout: 10 * time.Millisecond}
+	err = config.Poll(NewProviderOptions(), func() error {
+		return errors.New("not found")
+	})
+	assert.EqualError(t, err, "timed out after 10ms: not found")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ForkPollConfig{}.Poll(NewProviderOptions(WithContext(ctx)), func() error {
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
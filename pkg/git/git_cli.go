@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +19,13 @@ import (
 
 const (
 	replaceInvalidBranchChars = '_'
+
+	// defaultBranchName is used when nothing valid is left of the name to convert
+	defaultBranchName = "branch"
+
+	// gitLogFormat prints the fields of a commit separated by unit separators, ending with the message and a record
+	// separator as messages may contain any other character
+	gitLogFormat = "%H%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%ct%x1f%B%x1e"
 )
 
 // GitCLI implements common git actions based on git CLI
@@ -67,7 +74,26 @@ func (g *GitCLI) Clone(url string, dir string) error {
 
 // Clone clones a single branch of the given git URL into the given directory
 func (g *GitCLI) ShallowCloneBranch(url string, branch string, dir string) error {
-	return g.gitCmd(dir, "clone", "--depth", "1", "--single-branch", "--branch", branch, url, ".")
+	return g.ShallowCloneBranchWithDepth(url, branch, dir, 1)
+}
+
+// ShallowCloneBranchWithDepth clones the last depth commits of a single branch of the given git URL into the given
+// directory
+func (g *GitCLI) ShallowCloneBranchWithDepth(url string, branch string, dir string, depth int) error {
+	if depth < 1 {
+		return fmt.Errorf("invalid clone depth %d: the depth must be at least 1", depth)
+	}
+	return g.gitCmd(dir, "clone", "--depth="+strconv.Itoa(depth), "--single-branch", "--branch", branch, url, ".")
+}
+
+// CloneBare clones the given git URL into the given directory without a working tree
+func (g *GitCLI) CloneBare(url string, dir string) error {
+	return g.gitCmd(dir, "clone", "--bare", url, ".")
+}
+
+// Mirror pushes every ref of the repository at the given directory to the origin, deleting the refs it doesn't have
+func (g *GitCLI) Mirror(dir string) error {
+	return g.WriteOperation(dir, "push", "--mirror", "origin")
 }
 
 // Pull pulls the Git repository in the given directory
@@ -98,6 +124,20 @@ func (g *GitCLI) CloneOrPull(url string, dir string) error {
 	return g.Clone(url, dir)
 }
 
+// EnsureClone clones the given git URL into a directory under baseDir derived from the URL,
+// or pulls if it has already been cloned there, returning the directory
+func (g *GitCLI) EnsureClone(url string, baseDir string) (string, error) {
+	dir, err := cloneDir(url, baseDir)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+	return dir, g.CloneOrPull(url, dir)
+}
+
 // PullUpstream pulls the remote upstream branch into master branch into the given directory
 func (g *GitCLI) PullUpstream(dir string) error {
 	return g.gitCmd(dir, "pull", "-r", "upstream", "master")
@@ -283,16 +323,7 @@ func (g *GitCLI) gitCmdWithOutput(dir string, args ...string) (string, error) {
 
 // CreatePushURL creates the Git repository URL with the username and password encoded for HTTPS based URLs
 func (g *GitCLI) CreatePushURL(cloneURL, username, token string) (string, error) {
-	u, err := url.Parse(cloneURL)
-	if err != nil {
-		// already a git/ssh url?
-		return cloneURL, nil
-	}
-	if username != "" || token != "" {
-		u.User = url.UserPassword(username, token)
-		return u.String(), nil
-	}
-	return cloneURL, nil
+	return createPushURL(cloneURL, username, token)
 }
 
 // RepoName formats the repository names based on the organization
@@ -340,43 +371,69 @@ func (g *GitCLI) Info(dir string) (*Repository, error) {
 // ConvertToValidBranchName converts the given branch name into a valid git branch string
 // replacing any dodgy characters
 func (g *GitCLI) ConvertToValidBranchName(name string) string {
-	name = strings.TrimSuffix(name, "/")
-	name = strings.TrimSuffix(name, ".lock")
+	return convertToValidBranchName(name)
+}
+
+// convertToValidBranchName applies the rules of git check-ref-format --branch to the name. Characters git
+// rejects are replaced and the sequences and components it rejects are dropped, falling back to
+// defaultBranchName if nothing is left
+func convertToValidBranchName(name string) string {
+	name = strings.Replace(name, "@{", string(replaceInvalidBranchChars), -1)
 	var buffer bytes.Buffer
 
 	last := ' '
 	for _, ch := range name {
-		if ch <= 32 {
+		if ch <= 32 || ch == 127 {
 			ch = replaceInvalidBranchChars
 		}
 		switch ch {
-		case '~':
-			ch = replaceInvalidBranchChars
-		case '^':
-			ch = replaceInvalidBranchChars
-		case ':':
-			ch = replaceInvalidBranchChars
-		case ' ':
-			ch = replaceInvalidBranchChars
-		case '\n':
-			ch = replaceInvalidBranchChars
-		case '\r':
-			ch = replaceInvalidBranchChars
-		case '\t':
+		case '~', '^', ':', '?', '*', '[', '\\':
 			ch = replaceInvalidBranchChars
 		}
 		if ch != replaceInvalidBranchChars || last != replaceInvalidBranchChars {
-			buffer.WriteString(string(ch))
+			buffer.WriteRune(ch)
 		}
 		last = ch
 	}
-	return buffer.String()
+	name = buffer.String()
+	for strings.Contains(name, "..") {
+		name = strings.Replace(name, "..", ".", -1)
+	}
+
+	// components may not be empty, start with a dot or end with .lock and the name may not end with a dot
+	// or, to stop it being taken for an option, start with a dash
+	components := []string{}
+	for _, component := range strings.Split(name, "/") {
+		for {
+			trimmed := strings.TrimSuffix(strings.Trim(component, "."), ".lock")
+			if len(components) == 0 {
+				trimmed = strings.TrimLeft(trimmed, "-")
+			}
+			if trimmed == component {
+				break
+			}
+			component = trimmed
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+	name = strings.Join(components, "/")
+	if name == "@" || strings.Trim(name, string(replaceInvalidBranchChars)+"/") == "" {
+		return defaultBranchName
+	}
+	return name
 }
 
 func (g *GitCLI) FetchBranch(dir string, repo string, refspec string) error {
 	return g.gitCmd(dir, "fetch", repo, refspec)
 }
 
+// Merge merges the given commit-ish into the current branch
+func (g *GitCLI) Merge(dir string, commitish string) error {
+	return g.gitCmd(dir, "merge", commitish)
+}
+
 // GetAuthorEmailForCommit returns the author email from commit message with the given SHA
 func (g *GitCLI) GetAuthorEmailForCommit(dir string, sha string) (string, error) {
 	text, err := g.gitCmdWithOutput(dir, "show", "-s", "--format=%aE", sha)
@@ -525,6 +582,65 @@ func (g *GitCLI) GetLatestCommitMessage(dir string) (string, error) {
 	return g.gitCmdWithOutput(dir, "log", "-1", "--pretty=%B")
 }
 
+// GetCommitsBetween returns the commits listed by git log fromRef..toRef
+func (g *GitCLI) GetCommitsBetween(dir string, fromRef string, toRef string) ([]Commit, error) {
+	text, err := g.gitCmdWithOutput(dir, "log", "--format="+gitLogFormat, fromRef+".."+toRef)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list the commits between %s and %s", fromRef, toRef)
+	}
+	return parseGitLog(text)
+}
+
+// parseGitLog parses the output of git log with the gitLogFormat
+func parseGitLog(text string) ([]Commit, error) {
+	commits := []Commit{}
+	for _, record := range strings.Split(text, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 7)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unexpected git log output %q", record)
+		}
+		seconds, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit time %q of commit %s", fields[5], fields[0])
+		}
+		committedAt := time.Unix(seconds, 0).UTC()
+		commits = append(commits, Commit{
+			SHA:         fields[0],
+			Author:      &User{Name: fields[1], Email: fields[2]},
+			Committer:   &User{Name: fields[3], Email: fields[4]},
+			CommittedAt: &committedAt,
+			Message:     strings.TrimRight(fields[6], "\n"),
+		})
+	}
+	return commits, nil
+}
+
+// ListChangedFilesInCommit returns the files changed by the commit using git diff-tree. The paths are separated with
+// NULs so that git doesn't quote unusual ones, and the files of a root commit are listed too
+func (g *GitCLI) ListChangedFilesInCommit(dir string, sha string) ([]string, error) {
+	text, err := g.gitCmdWithOutput(dir, "diff-tree", "--no-commit-id", "--name-only", "-r", "-z", "--root", sha)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list the files changed in commit %s", sha)
+	}
+	return parseChangedFiles(text), nil
+}
+
+// parseChangedFiles parses the NUL separated paths printed by git diff-tree -z --name-only
+func parseChangedFiles(text string) []string {
+	files := []string{}
+	for _, file := range strings.Split(text, "\x00") {
+		file = strings.TrimLeft(file, "\n")
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 // FetchTags fetches all the tags
 func (g *GitCLI) FetchTags(dir string) error {
 	return g.gitCmd("", "fetch", "--tags", "-v")
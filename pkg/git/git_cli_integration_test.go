@@ -0,0 +1,126 @@
+// +build integration
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitCLICloneBareAndMirror(t *testing.T) {
+	gitter := NewGitCLI()
+	tmpDir, err := ioutil.TempDir("", "git-mirror")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	dir := func(name string) string {
+		d := filepath.Join(tmpDir, name)
+		require.NoError(t, os.Mkdir(d, 0755))
+		return d
+	}
+
+	source := dir("source")
+	require.NoError(t, gitter.Init(source))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(source, "README.md"), []byte("hello"), 0644))
+	require.NoError(t, gitter.gitCmd(source, "add", "README.md"))
+	require.NoError(t, gitter.gitCmd(source, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "first"))
+	require.NoError(t, gitter.gitCmd(source, "branch", "feature"))
+	require.NoError(t, gitter.gitCmd(source, "tag", "v1.0"))
+
+	bare := dir("bare")
+	require.NoError(t, gitter.CloneBare(source, bare))
+	_, err = os.Stat(filepath.Join(bare, "README.md"))
+	assert.True(t, os.IsNotExist(err), "a bare clone has no working tree")
+
+	mirror := dir("mirror")
+	require.NoError(t, gitter.gitCmd(mirror, "init", "--bare"))
+	require.NoError(t, gitter.SetRemoteURL(bare, "origin", mirror))
+	require.NoError(t, gitter.Mirror(bare))
+
+	sourceRefs, err := gitter.gitCmdWithOutput(source, "show-ref")
+	require.NoError(t, err)
+	mirrorRefs, err := gitter.gitCmdWithOutput(mirror, "show-ref")
+	require.NoError(t, err)
+	assert.Equal(t, sourceRefs, mirrorRefs)
+	assert.Contains(t, mirrorRefs, "refs/heads/feature")
+	assert.Contains(t, mirrorRefs, "refs/tags/v1.0")
+}
+
+func TestGitCLIGetCommitsBetween(t *testing.T) {
+	gitter := NewGitCLI()
+	dir, err := ioutil.TempDir("", "git-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, gitter.Init(dir))
+	for _, message := range []string{"first", "second", "third\n\nwith a body"} {
+		require.NoError(t, gitter.gitCmd(dir, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--allow-empty", "-m", message))
+	}
+
+	commits, err := gitter.GetCommitsBetween(dir, "HEAD~2", "HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "third\n\nwith a body", commits[0].Message)
+	assert.Equal(t, "second", commits[1].Message)
+	assert.Equal(t, "test@example.com", commits[1].Author.Email)
+	assert.Len(t, commits[1].SHA, 40)
+}
+
+func TestGitCLIShallowCloneBranchWithDepth(t *testing.T) {
+	gitter := NewGitCLI()
+	tmpDir, err := ioutil.TempDir("", "git-shallow")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	source := filepath.Join(tmpDir, "source")
+	clone := filepath.Join(tmpDir, "clone")
+	require.NoError(t, os.Mkdir(source, 0755))
+	require.NoError(t, os.Mkdir(clone, 0755))
+	require.NoError(t, gitter.Init(source))
+	for _, message := range []string{"first", "second", "third"} {
+		require.NoError(t, gitter.gitCmd(source, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--allow-empty", "-m", message))
+	}
+	branch, err := gitter.Branch(source)
+	require.NoError(t, err)
+
+	// a local path is cloned with hard links rather than shallowly unless it is a file:// URL
+	require.NoError(t, gitter.ShallowCloneBranchWithDepth("file://"+source, branch, clone, 2))
+	count, err := gitter.gitCmdWithOutput(clone, "rev-list", "--count", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, "2", count)
+}
+
+func TestGitCLIListChangedFilesInCommit(t *testing.T) {
+	gitter := NewGitCLI()
+	dir, err := ioutil.TempDir("", "git-diff-tree")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	commit := func(message string) string {
+		require.NoError(t, gitter.gitCmd(dir, "add", "-A"))
+		require.NoError(t, gitter.gitCmd(dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", message))
+		sha, err := gitter.gitCmdWithOutput(dir, "rev-parse", "HEAD")
+		require.NoError(t, err)
+		return sha
+	}
+
+	require.NoError(t, gitter.Init(dir))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "old name.md"), []byte("hello"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "removed.go"), []byte("package main"), 0644))
+	first := commit("first")
+	require.NoError(t, os.Rename(filepath.Join(dir, "old name.md"), filepath.Join(dir, "new.md")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "removed.go")))
+	second := commit("second")
+
+	files, err := gitter.ListChangedFilesInCommit(dir, first)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old name.md", "removed.go"}, files)
+	files, err = gitter.ListChangedFilesInCommit(dir, second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.md", "old name.md", "removed.go"}, files)
+}
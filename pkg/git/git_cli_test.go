@@ -2,6 +2,7 @@ package git
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,82 @@ func Test(t *testing.T) {
 		assert.Equal(t, data.expected, actual, "Convert to valid branch name for %s", data.input)
 	}
 }
+
+func TestConvertToValidBranchNameRefFormat(t *testing.T) {
+	t.Parallel()
+	testCases := []brancbNameData{
+		{".hidden", "hidden"},
+		{"feature/.hidden", "feature/hidden"},
+		{"-foo", "foo"},
+		{"-/foo", "foo"},
+		{"foo..bar", "foo.bar"},
+		{"foo...bar", "foo.bar"},
+		{"foo@{bar}", "foo_bar}"},
+		{"foo@bar", "foo@bar"},
+		{"foo//bar", "foo/bar"},
+		{"/foo/", "foo"},
+		{"foo//", "foo"},
+		{"foo.", "foo"},
+		{"foo/.", "foo"},
+		{"a.lock.", "a"},
+		{"foo/bar.lock/baz", "foo/bar/baz"},
+		{"what?*[x]\\y", "what_x]_y"},
+		{"a\x7fb", "a_b"},
+		{"fix: the bug", "fix_the_bug"},
+		{"feature/ünïcödé-分支", "feature/ünïcödé-分支"},
+		{"改善", "改善"},
+		{"", "branch"},
+		{"@", "branch"},
+		{"..", "branch"},
+		{"/", "branch"},
+		{"???", "branch"},
+		{" \t\n", "branch"},
+		{"~/^", "branch"},
+	}
+	cli := &GitCLI{}
+	fake := &GitFake{}
+	for _, data := range testCases {
+		assert.Equal(t, data.expected, cli.ConvertToValidBranchName(data.input), "Convert to valid branch name for %q", data.input)
+		assert.Equal(t, data.expected, fake.ConvertToValidBranchName(data.input), "Convert to valid branch name for %q with GitFake", data.input)
+	}
+}
+
+func TestParseGitLog(t *testing.T) {
+	t.Parallel()
+
+	// the output of git log --format=gitLogFormat, trimmed as the command output is
+	text := "6dcb09b5b57875f334f61aebed695e2e4193db5e\x1fJane Doe\x1fjane@example.com\x1fGitHub\x1fnoreply@github.com\x1f1542708765\x1f" +
+		"Fix the build\n\nThe message has a body\nfixes #12\n\x1e\n" +
+		"c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c\x1fJohn Doe\x1fjohn@example.com\x1fJohn Doe\x1fjohn@example.com\x1f1542622365\x1f" +
+		"Add a feature\n\x1e"
+
+	commits, err := parseGitLog(text)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 2)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", commits[0].SHA)
+	assert.Equal(t, "Fix the build\n\nThe message has a body\nfixes #12", commits[0].Message)
+	assert.Equal(t, &User{Name: "Jane Doe", Email: "jane@example.com"}, commits[0].Author)
+	assert.Equal(t, &User{Name: "GitHub", Email: "noreply@github.com"}, commits[0].Committer)
+	assert.Equal(t, time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *commits[0].CommittedAt)
+	assert.Equal(t, "c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c", commits[1].SHA)
+	assert.Equal(t, "Add a feature", commits[1].Message)
+
+	commits, err = parseGitLog("")
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+
+	_, err = parseGitLog("6dcb09b5b57875f334f61aebed695e2e4193db5e\x1fJane Doe")
+	assert.Error(t, err)
+	_, err = parseGitLog("6dcb09b5b57875f334f61aebed695e2e4193db5e\x1fa\x1fb\x1fc\x1fd\x1fyesterday\x1fmessage\x1e")
+	assert.Error(t, err)
+}
+
+func TestParseChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	// a modified file, a file renamed without rename detection, a deleted file and paths git would otherwise quote
+	text := "README.md\x00docs/new.md\x00docs/old.md\x00pkg/removed.go\x00with space.txt\x00\u00fcnic\u00f6de.md\x00"
+	assert.Equal(t, []string{"README.md", "docs/new.md", "docs/old.md", "pkg/removed.go", "with space.txt", "ünicöde.md"},
+		parseChangedFiles(text))
+	assert.Empty(t, parseChangedFiles(""))
+}
@@ -1,11 +1,9 @@
 package git
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
-	"net/url"
 	"strings"
 	"time"
 
@@ -23,12 +21,18 @@ type GitRemote struct {
 type GitTag struct {
 	Name    string
 	Message string
+	// SHA is the commit the tag points at
+	SHA string
+	// Date is when the commit the tag points at was made, or nil if the provider doesn't report it
+	Date *time.Time
 }
 
 // GitFake provides a fake Gitter
 type GitFake struct {
-	Remotes        []GitRemote
-	Branches       []string
+	Remotes  []GitRemote
+	Branches []string
+	// BranchOps records the branch operations in order, e.g. "create:feature" or "checkout:master"
+	BranchOps      []string
 	BranchesRemote []string
 	CurrentBranch  string
 	RepoInfo       Repository
@@ -39,7 +43,15 @@ type GitFake struct {
 	Changes        bool
 	GitTags        []GitTag
 	Revision       string
-	serverURL      string
+	// RevisionsByDate are the commit dates of the revisions returned by GetRevisionBeforeDate.
+	// When empty Revision is returned for any date
+	RevisionsByDate map[time.Time]string
+	ClonedDirs      []string
+	// ShallowCloneDepth is the depth of the last shallow clone
+	ShallowCloneDepth int
+	// FilesChanged are the files returned by ListChangedFilesInCommit, keyed by SHA
+	FilesChanged map[string][]string
+	serverURL    string
 }
 
 // NewGitFake creates a new fake Gitter
@@ -134,6 +146,25 @@ func (g *GitFake) Clone(url string, directory string) error {
 
 // ShallowCloneBranch shallow clone of a branch
 func (g *GitFake) ShallowCloneBranch(url string, branch string, directory string) error {
+	return g.ShallowCloneBranchWithDepth(url, branch, directory, 1)
+}
+
+// ShallowCloneBranchWithDepth records the depth of the shallow clone
+func (g *GitFake) ShallowCloneBranchWithDepth(url string, branch string, directory string, depth int) error {
+	if depth < 1 {
+		return fmt.Errorf("invalid clone depth %d: the depth must be at least 1", depth)
+	}
+	g.ShallowCloneDepth = depth
+	return nil
+}
+
+// CloneBare performs a bare clone
+func (g *GitFake) CloneBare(url string, directory string) error {
+	return nil
+}
+
+// Mirror performs a mirror push
+func (g *GitFake) Mirror(dir string) error {
 	return nil
 }
 
@@ -154,15 +185,7 @@ func (g *GitFake) PushTag(dir string, tag string) error {
 
 // CreatePushURL creates a Push URL
 func (g *GitFake) CreatePushURL(cloneURL, username, token string) (string, error) {
-	u, err := url.Parse(cloneURL)
-	if err != nil {
-		return cloneURL, nil
-	}
-	if username != "" || token != "" {
-		u.User = url.UserPassword(username, token)
-		return u.String(), nil
-	}
-	return cloneURL, nil
+	return createPushURL(cloneURL, username, token)
 }
 
 // ForcePushBranch force push a branch
@@ -175,6 +198,16 @@ func (g *GitFake) CloneOrPull(url string, directory string) error {
 	return nil
 }
 
+// EnsureClone records the directory the repo would be cloned into
+func (g *GitFake) EnsureClone(url string, baseDir string) (string, error) {
+	dir, err := cloneDir(url, baseDir)
+	if err != nil {
+		return "", err
+	}
+	g.ClonedDirs = append(g.ClonedDirs, dir)
+	return dir, nil
+}
+
 // Pull git pulls
 func (g *GitFake) Pull(dir string) error {
 	return nil
@@ -293,62 +326,45 @@ func (g *GitFake) Branch(dir string) (string, error) {
 
 // CreateBranch creates a branch
 func (g *GitFake) CreateBranch(dir string, branch string) error {
-	g.Branches = append(g.Branches, branch)
+	g.addBranch(branch)
+	g.BranchOps = append(g.BranchOps, "create:"+branch)
 	return nil
 }
 
 // CheckoutRemoteBranch checkout remote branch
 func (g *GitFake) CheckoutRemoteBranch(dir string, branch string) error {
 	g.CurrentBranch = branch
-	g.Branches = append(g.Branches, branch)
+	g.addBranch(branch)
+	g.BranchOps = append(g.BranchOps, "checkout-remote:"+branch)
 	return nil
 }
 
 // Checkout checkout the branch
 func (g *GitFake) Checkout(dir string, branch string) error {
 	g.CurrentBranch = branch
+	g.BranchOps = append(g.BranchOps, "checkout:"+branch)
 	return nil
 }
 
 // CheckoutOrphan checkout the orphan
 func (g *GitFake) CheckoutOrphan(dir string, branch string) error {
 	g.CurrentBranch = branch
+	g.BranchOps = append(g.BranchOps, "checkout-orphan:"+branch)
 	return nil
 }
 
-// ConvertToValidBranchName converts the name to a valid branch name
-func (g *GitFake) ConvertToValidBranchName(name string) string {
-	name = strings.TrimSuffix(name, "/")
-	name = strings.TrimSuffix(name, ".lock")
-	var buffer bytes.Buffer
-
-	last := ' '
-	for _, ch := range name {
-		if ch <= 32 {
-			ch = replaceInvalidBranchChars
-		}
-		switch ch {
-		case '~':
-			ch = replaceInvalidBranchChars
-		case '^':
-			ch = replaceInvalidBranchChars
-		case ':':
-			ch = replaceInvalidBranchChars
-		case ' ':
-			ch = replaceInvalidBranchChars
-		case '\n':
-			ch = replaceInvalidBranchChars
-		case '\r':
-			ch = replaceInvalidBranchChars
-		case '\t':
-			ch = replaceInvalidBranchChars
-		}
-		if ch != replaceInvalidBranchChars || last != replaceInvalidBranchChars {
-			buffer.WriteString(string(ch))
+func (g *GitFake) addBranch(branch string) {
+	for _, b := range g.Branches {
+		if b == branch {
+			return
 		}
-		last = ch
 	}
-	return buffer.String()
+	g.Branches = append(g.Branches, branch)
+}
+
+// ConvertToValidBranchName converts the name to a valid branch name
+func (g *GitFake) ConvertToValidBranchName(name string) string {
+	return convertToValidBranchName(name)
 }
 
 // FetchBranch fetch branch
@@ -356,6 +372,11 @@ func (g *GitFake) FetchBranch(dir string, repo string, refspec string) error {
 	return nil
 }
 
+// Merge merges a commit-ish into the current branch
+func (g *GitFake) Merge(dir string, commitish string) error {
+	return nil
+}
+
 // Stash git stash
 func (g *GitFake) Stash(dir string) error {
 	return nil
@@ -437,6 +458,44 @@ func (g *GitFake) GetLatestCommitMessage(dir string) (string, error) {
 	return g.Commits[len-1].Message, nil
 }
 
+// GetCommitsBetween returns the commits after the one with the SHA fromRef up to the one with the SHA toRef, newest
+// first. An empty fromRef starts from the first commit and an empty toRef or HEAD ends with the last
+func (g *GitFake) GetCommitsBetween(dir string, fromRef string, toRef string) ([]Commit, error) {
+	if toRef == "HEAD" {
+		toRef = ""
+	}
+	from := -1
+	to := len(g.Commits) - 1
+	for i, commit := range g.Commits {
+		if fromRef != "" && commit.SHA == fromRef {
+			from = i
+		}
+		if toRef != "" && commit.SHA == toRef {
+			to = i
+		}
+	}
+	if fromRef != "" && from < 0 {
+		return nil, fmt.Errorf("No commit found with SHA %s", fromRef)
+	}
+	if toRef != "" && (to < 0 || g.Commits[to].SHA != toRef) {
+		return nil, fmt.Errorf("No commit found with SHA %s", toRef)
+	}
+	commits := []Commit{}
+	for i := to; i > from; i-- {
+		commits = append(commits, g.Commits[i])
+	}
+	return commits, nil
+}
+
+// ListChangedFilesInCommit returns the FilesChanged of the commit
+func (g *GitFake) ListChangedFilesInCommit(dir string, sha string) ([]string, error) {
+	files, ok := g.FilesChanged[sha]
+	if !ok {
+		return nil, fmt.Errorf("No commit found with SHA %s", sha)
+	}
+	return files, nil
+}
+
 // FetchTags fetches tags
 func (g *GitFake) FetchTags(dir string) error {
 	return nil
@@ -461,14 +520,35 @@ func (g *GitFake) CreateTag(dir string, tag string, msg string) error {
 	return nil
 }
 
-// GetRevisionBeforeDate get the revision before the date
+// GetRevisionBeforeDate get the latest revision in RevisionsByDate committed at or before the date
 func (g *GitFake) GetRevisionBeforeDate(dir string, t time.Time) (string, error) {
-	return g.Revision, nil
+	if len(g.RevisionsByDate) == 0 {
+		return g.Revision, nil
+	}
+	answer := ""
+	var latest time.Time
+	for date, revision := range g.RevisionsByDate {
+		if date.After(t) {
+			continue
+		}
+		if answer == "" || date.After(latest) {
+			answer = revision
+			latest = date
+		}
+	}
+	return answer, nil
 }
 
 // GetRevisionBeforeDateText get the revision before the date text
 func (g *GitFake) GetRevisionBeforeDateText(dir string, dateText string) (string, error) {
-	return g.Revision, nil
+	if len(g.RevisionsByDate) == 0 {
+		return g.Revision, nil
+	}
+	t, err := util.ParseDate(dateText)
+	if err != nil {
+		return "", err
+	}
+	return g.GetRevisionBeforeDate(dir, t)
 }
 
 // Diff performs a git diff
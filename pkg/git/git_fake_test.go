@@ -0,0 +1,114 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitFakeGetRevisionBeforeDate(t *testing.T) {
+	t.Parallel()
+	first := time.Date(2018, 1, 10, 12, 0, 0, 0, time.UTC)
+	second := time.Date(2018, 2, 10, 12, 0, 0, 0, time.UTC)
+	third := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	gitter := &GitFake{
+		RevisionsByDate: map[time.Time]string{
+			first:  "aaa111",
+			second: "bbb222",
+			third:  "ccc333",
+		},
+	}
+
+	revision, err := gitter.GetRevisionBeforeDate("", first.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "", revision)
+
+	revision, err = gitter.GetRevisionBeforeDate("", second)
+	assert.NoError(t, err)
+	assert.Equal(t, "bbb222", revision)
+
+	revision, err = gitter.GetRevisionBeforeDate("", third.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "ccc333", revision)
+
+	revision, err = gitter.GetRevisionBeforeDateText("", "February 20 2018")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbb222", revision)
+}
+
+func TestGitFakeGetRevisionBeforeDateWithoutDates(t *testing.T) {
+	t.Parallel()
+	gitter := &GitFake{Revision: "abc123"}
+
+	revision, err := gitter.GetRevisionBeforeDate("", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", revision)
+}
+
+func TestGitFakeBranchOps(t *testing.T) {
+	t.Parallel()
+	gitter := &GitFake{}
+
+	assert.NoError(t, gitter.CreateBranch("", "feature"))
+	assert.NoError(t, gitter.Checkout("", "feature"))
+	assert.NoError(t, gitter.CheckoutRemoteBranch("", "feature"))
+	assert.NoError(t, gitter.Checkout("", "master"))
+
+	assert.Equal(t, []string{"feature"}, gitter.Branches)
+	assert.Equal(t, "master", gitter.CurrentBranch)
+	assert.Equal(t, []string{"create:feature", "checkout:feature", "checkout-remote:feature", "checkout:master"}, gitter.BranchOps)
+}
+
+func TestGitFakeCloneBareAndMirror(t *testing.T) {
+	t.Parallel()
+	var gitter Gitter = &GitFake{}
+
+	assert.NoError(t, gitter.CloneBare("https://github.com/org/repo.git", "repo.git"))
+	assert.NoError(t, gitter.Mirror("repo.git"))
+}
+
+func TestGitFakeGetCommitsBetween(t *testing.T) {
+	t.Parallel()
+	gitter := &GitFake{Commits: []Commit{{SHA: "a"}, {SHA: "b"}, {SHA: "c"}, {SHA: "d"}}}
+
+	commits, err := gitter.GetCommitsBetween("", "a", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, []Commit{{SHA: "c"}, {SHA: "b"}}, commits)
+
+	commits, err = gitter.GetCommitsBetween("", "b", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []Commit{{SHA: "d"}, {SHA: "c"}}, commits)
+
+	commits, err = gitter.GetCommitsBetween("", "c", "a")
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+
+	_, err = gitter.GetCommitsBetween("", "missing", "d")
+	assert.Error(t, err)
+	_, err = gitter.GetCommitsBetween("", "a", "missing")
+	assert.Error(t, err)
+}
+
+func TestGitFakeShallowCloneBranchWithDepth(t *testing.T) {
+	t.Parallel()
+	gitter := &GitFake{}
+
+	assert.NoError(t, gitter.ShallowCloneBranchWithDepth("https://github.com/org/repo.git", "master", "repo", 10))
+	assert.Equal(t, 10, gitter.ShallowCloneDepth)
+	assert.NoError(t, gitter.ShallowCloneBranch("https://github.com/org/repo.git", "master", "repo"))
+	assert.Equal(t, 1, gitter.ShallowCloneDepth)
+	assert.Error(t, gitter.ShallowCloneBranchWithDepth("https://github.com/org/repo.git", "master", "repo", 0))
+}
+
+func TestGitFakeListChangedFilesInCommit(t *testing.T) {
+	t.Parallel()
+	gitter := &GitFake{FilesChanged: map[string][]string{"abc123": {"README.md", "main.go"}}}
+
+	files, err := gitter.ListChangedFilesInCommit("", "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "main.go"}, files)
+	_, err = gitter.ListChangedFilesInCommit("", "missing")
+	assert.Error(t, err)
+}
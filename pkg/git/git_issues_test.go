@@ -0,0 +1,44 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterIssuesClosedSince(t *testing.T) {
+	t.Parallel()
+	open := "open"
+	closed := "closed"
+	opened := "opened"
+	since := time.Date(2018, 6, 1, 9, 0, 0, 0, time.UTC)
+	before := since.Add(-time.Hour)
+	after := since.Add(time.Hour)
+
+	// 10:00 in UTC+2 is 08:00 UTC so it is before the window even though the wall clock is later
+	plusTwo := time.FixedZone("UTC+2", 2*60*60)
+	beforeInOtherZone := time.Date(2018, 6, 1, 10, 0, 0, 0, plusTwo)
+	afterInOtherZone := time.Date(2018, 6, 1, 12, 0, 0, 0, plusTwo)
+
+	issues := []*Issue{
+		{Key: "closed-after", State: &closed, ClosedAt: &after},
+		{Key: "closed-at", State: &closed, ClosedAt: &since},
+		{Key: "closed-before", State: &closed, ClosedAt: &before},
+		{Key: "closed-before-other-zone", State: &closed, ClosedAt: &beforeInOtherZone},
+		{Key: "closed-after-other-zone", State: &closed, ClosedAt: &afterInOtherZone},
+		{Key: "closed-without-date", State: &closed},
+		{Key: "reopened", State: &open, ClosedAt: &after},
+		{Key: "gitlab-opened", State: &opened, ClosedAt: &after},
+		{Key: "without-state", ClosedAt: &after},
+		nil,
+	}
+
+	keys := []string{}
+	for _, issue := range FilterIssuesClosedSince(issues, since) {
+		keys = append(keys, issue.Key)
+	}
+	assert.Equal(t, []string{"closed-after", "closed-at", "closed-after-other-zone"}, keys)
+
+	assert.Empty(t, FilterIssuesClosedSince(nil, since))
+}
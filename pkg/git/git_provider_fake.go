@@ -1,11 +1,20 @@
 package git
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 )
 
 // GitFakeProvider provides a fake git provider
@@ -13,6 +22,9 @@ type GitFakeProvider struct {
 	User          User
 	Organisations map[string]*FakeOrganisation
 	WebHooks      []*WebhookArguments
+	// RepositoryData holds the pull requests, issues, statuses and releases of the repositories, keyed by
+	// org/name
+	RepositoryData map[string]*GitFakeRepositoryData
 
 	serverURL string
 	Username  string
@@ -25,6 +37,29 @@ type GitFakeProvider struct {
 type FakeOrganisation struct {
 	Organisation Organisation
 	Repositories []*Repository
+	Teams        []*Team
+}
+
+// GitFakeRepositoryData is what the fake git provider stores for a repository. Pull requests and issues share
+// their numbers as on GitHub
+type GitFakeRepositoryData struct {
+	PullRequests map[int]*PullRequest
+	Issues       map[int]*Issue
+	// Comments are the comments added to the pull requests and issues, keyed by number
+	Comments map[int][]string
+	// Statuses are the statuses of the commits, keyed by SHA
+	Statuses map[string][]*RepoStatus
+	// Releases are keyed by tag
+	Releases map[string]*Release
+	// Branches are the SHAs of the branches of the repository other than the default one, keyed by name
+	Branches map[string]string
+	// Tags are keyed by name
+	Tags         map[string]*GitTag
+	Milestones   []*Milestone
+	Contributors []*Contributor
+	MergeConfig  MergeConfig
+
+	lastNumber int
 }
 
 // NewFakeGitProvider creates a new fake git provider
@@ -32,11 +67,12 @@ func NewFakeGitProvider(username, providerName string, git Gitter) (Provider, er
 	User := User{}
 	serverURL := FakeGitURL
 	answer := &GitFakeProvider{
-		User:          User,
-		Organisations: map[string]*FakeOrganisation{},
-		Git:           git,
-		Username:      username,
-		URL:           serverURL,
+		User:           User,
+		Organisations:  map[string]*FakeOrganisation{},
+		RepositoryData: map[string]*GitFakeRepositoryData{},
+		Git:            git,
+		Username:       username,
+		URL:            serverURL,
 	}
 	return answer, nil
 }
@@ -50,6 +86,15 @@ func (g *GitFakeProvider) ListOrganisations() ([]Organisation, error) {
 	return answer, nil
 }
 
+// ListTeams returns the teams of the fake organisation
+func (g *GitFakeProvider) ListTeams(org string) ([]*Team, error) {
+	fakeOrg, ok := g.Organisations[org]
+	if !ok {
+		return nil, fmt.Errorf("organisation '%s' not found", org)
+	}
+	return fakeOrg.Teams, nil
+}
+
 // ListRepositories list the repos for an org
 func (g *GitFakeProvider) ListRepositories(org string) ([]*Repository, error) {
 	organisation := g.Organisations[org]
@@ -59,6 +104,20 @@ func (g *GitFakeProvider) ListRepositories(org string) ([]*Repository, error) {
 	return organisation.Repositories, nil
 }
 
+// ListRepositoriesPaged list the repositories of the organisation in a single page
+func (g *GitFakeProvider) ListRepositoriesPaged(org string, options ListOptions) (*RepositoryPage, error) {
+	repos, err := g.ListRepositories(org)
+	if err != nil {
+		return nil, err
+	}
+	return &RepositoryPage{Repositories: repos}, nil
+}
+
+// ListRepositoriesForCurrentUser list the repositories of the fake user
+func (g *GitFakeProvider) ListRepositoriesForCurrentUser() ([]*Repository, error) {
+	return g.ListRepositories(g.Username)
+}
+
 // CreateRepository create a repo in an org
 func (g *GitFakeProvider) CreateRepository(org string, name string, private bool) (*Repository, error) {
 	organisation := g.Organisations[org]
@@ -107,79 +166,313 @@ func (g *GitFakeProvider) DeleteRepository(org string, name string) error {
 	return g.notFound()
 }
 
-// ForkRepository fork a repo
+// ForkRepository copies the repository into the destination organisation, creating the organisation if needed
 func (g *GitFakeProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*Repository, error) {
-	panic("implement me")
+	original, err := g.GetRepository(originalOrg, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.GetRepository(destinationOrg, name); err == nil {
+		return nil, fmt.Errorf("fork of %s/%s in %s: %w", originalOrg, name, destinationOrg, ErrAlreadyExists)
+	}
+	fork, err := g.CreateRepository(destinationOrg, name, original.Private)
+	if err != nil {
+		return nil, err
+	}
+	*fork = *original
+	fork.Organisation = destinationOrg
+	fork.Fork = true
+	return fork, nil
 }
 
-// RenameRepository rename a repo
+// RenameRepository renames a repo, moving what is stored for it to the new name
 func (g *GitFakeProvider) RenameRepository(org string, name string, newName string) (*Repository, error) {
-	panic("implement me")
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.GetRepository(org, newName); err == nil {
+		return nil, fmt.Errorf("repository %s/%s: %w", org, newName, ErrAlreadyExists)
+	}
+	repo.Name = newName
+	if data, ok := g.RepositoryData[org+"/"+name]; ok {
+		delete(g.RepositoryData, org+"/"+name)
+		g.RepositoryData[org+"/"+newName] = data
+	}
+	return repo, nil
 }
 
-// ValidateRepositoryName validate a repo name can be used
+// ValidateRepositoryName returns an error if the organisation already has a repository with the name
 func (g *GitFakeProvider) ValidateRepositoryName(org string, name string) error {
-	panic("implement me")
+	if _, err := g.GetRepository(org, name); err == nil {
+		return fmt.Errorf("repository %s/%s: %w", org, name, ErrAlreadyExists)
+	}
+	return nil
+}
+
+// GetMergeConfig returns the merge config stored for the repository
+func (g *GitFakeProvider) GetMergeConfig(org string, name string) (*MergeConfig, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	config := data.MergeConfig
+	return &config, nil
+}
+
+// SetMergeConfig stores the merge config of the repository
+func (g *GitFakeProvider) SetMergeConfig(org string, name string, config *MergeConfig) error {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return err
+	}
+	data.MergeConfig = *config
+	return nil
+}
+
+// SetRepositoryTopics replaces the topics of the repository
+func (g *GitFakeProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	repo.Topics = append([]string{}, topics...)
+	return nil
 }
 
-// CreatePullRequest create a PR
+// CreatePullRequest opens a pull request, numbering it after the last pull request or issue of the repository
 func (g *GitFakeProvider) CreatePullRequest(data *PullRequestArguments) (*PullRequest, error) {
-	panic("implement me")
+	org := data.Repository.Organisation
+	name := data.Repository.Name
+	repo, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	repo.lastNumber++
+	number := repo.lastNumber
+	state := PullRequestStateOpen
+	merged := false
+	mergeable := true
+	head := data.Head
+	base := data.Base
+	author := g.User
+	pr := &PullRequest{
+		URL:       g.IssueURL(org, name, number, true),
+		Author:    &author,
+		Owner:     org,
+		Repo:      name,
+		Number:    &number,
+		Mergeable: &mergeable,
+		Merged:    &merged,
+		HeadRef:   &head,
+		BaseRef:   &base,
+		State:     &state,
+		Title:     data.Title,
+		Body:      data.Body,
+	}
+	repo.PullRequests[number] = pr
+	copied := *pr
+	return &copied, nil
 }
 
-// UpdatePullRequestStatus update the status of a PR
+// UpdatePullRequestStatus refreshes the pull request with what is stored for it
 func (g *GitFakeProvider) UpdatePullRequestStatus(pr *PullRequest) error {
-	panic("implement me")
+	stored, err := g.pullRequest(pr)
+	if err != nil {
+		return err
+	}
+	*pr = *stored
+	return nil
 }
 
-// GetPullRequest get a PR
+// GetPullRequest returns a copy of the stored pull request
 func (g *GitFakeProvider) GetPullRequest(owner string, repo *Repository, number int) (*PullRequest, error) {
-	panic("implement me")
+	data, err := g.repositoryData(owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+	pr, ok := data.PullRequests[number]
+	if !ok {
+		return nil, fmt.Errorf("pull request %d of %s/%s: %w", number, owner, repo.Name, ErrNotFound)
+	}
+	copied := *pr
+	return &copied, nil
 }
 
-// GetPullRequestCommits get the commits for a PR
+// GetPullRequestCommits returns no commits as the fake doesn't track them
 func (g *GitFakeProvider) GetPullRequestCommits(owner string, repo *Repository, number int) ([]*Commit, error) {
-	panic("implement me")
+	if _, err := g.GetPullRequest(owner, repo, number); err != nil {
+		return nil, err
+	}
+	return []*Commit{}, nil
 }
 
-// PullRequestLastCommitStatus get the status of the last PR's commit
+// ListPullRequests list the PRs of a repository in a state, ordered by number
+func (g *GitFakeProvider) ListPullRequests(owner string, repo *Repository, state string) ([]*PullRequest, error) {
+	data, err := g.repositoryData(owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+	numbers := []int{}
+	for number := range data.PullRequests {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	answer := []*PullRequest{}
+	for _, number := range numbers {
+		pr := *data.PullRequests[number]
+		if state == PullRequestStateAll || (state == PullRequestStateOpen) == !pr.IsClosed() {
+			answer = append(answer, &pr)
+		}
+	}
+	return answer, nil
+}
+
+// ListPullRequestsByAuthor list the PRs of a repository in a state opened by an author
+func (g *GitFakeProvider) ListPullRequestsByAuthor(owner string, repo *Repository, author string, state string) ([]*PullRequest, error) {
+	prs, err := g.ListPullRequests(owner, repo, state)
+	if err != nil {
+		return nil, err
+	}
+	return FilterPullRequestsByAuthor(prs, author), nil
+}
+
+// GetPullRequestByBranch get the open PR from a branch
+func (g *GitFakeProvider) GetPullRequestByBranch(owner string, repo *Repository, branch string) (*PullRequest, error) {
+	prs, err := g.ListPullRequests(owner, repo, PullRequestStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	return FindPullRequestByBranch(prs, branch), nil
+}
+
+// ClosePullRequest close a PR without merging it
+func (g *GitFakeProvider) ClosePullRequest(pr *PullRequest) error {
+	data, err := g.repositoryData(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	if _, err := g.pullRequest(pr); err != nil {
+		return err
+	}
+	stored := data.PullRequests[*pr.Number]
+	state := PullRequestStateClosed
+	now := time.Now()
+	stored.State = &state
+	stored.ClosedAt = &now
+	*pr = *stored
+	return nil
+}
+
+// PullRequestLastCommitStatus returns the overall state of the statuses of the last commit of the PR
 func (g *GitFakeProvider) PullRequestLastCommitStatus(pr *PullRequest) (string, error) {
-	panic("implement me")
+	stored, err := g.pullRequest(pr)
+	if err != nil {
+		return "", err
+	}
+	if stored.LastCommitSha == "" {
+		return "", fmt.Errorf("pull request %d of %s/%s has no commits", *pr.Number, pr.Owner, pr.Repo)
+	}
+	statuses, err := g.ListCommitStatus(pr.Owner, pr.Repo, stored.LastCommitSha)
+	if err != nil {
+		return "", err
+	}
+	return OverallState(statuses), nil
 }
 
 // ListCommitStatus list the status of a commit
 func (g *GitFakeProvider) ListCommitStatus(org string, repo string, sha string) ([]*RepoStatus, error) {
-	panic("implement me")
+	data, err := g.repositoryData(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*RepoStatus{}, data.Statuses[sha]...), nil
+}
+
+// GetCommitStatus get the latest status of a commit for a context
+func (g *GitFakeProvider) GetCommitStatus(org string, repo string, sha string, context string) (*RepoStatus, error) {
+	statuses, err := g.ListCommitStatus(org, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	return FindRepoStatus(statuses, context), nil
 }
 
-// UpdateCommitStatus update the status of a commit
+// UpdateCommitStatus replaces the status of the commit with the same context, or adds it if there is none
 func (g *GitFakeProvider) UpdateCommitStatus(org string, repo string, sha string, status *RepoStatus) (*RepoStatus, error) {
-	panic("implement me")
+	if err := status.NormalizeContext(MaxStatusContextLength); err != nil {
+		return &RepoStatus{}, err
+	}
+	data, err := g.repositoryData(org, repo)
+	if err != nil {
+		return &RepoStatus{}, err
+	}
+	for i, s := range data.Statuses[sha] {
+		if s.Context == status.Context {
+			data.Statuses[sha][i] = status
+			return status, nil
+		}
+	}
+	data.Statuses[sha] = append(data.Statuses[sha], status)
+	return status, nil
 }
 
-// MergePullRequest merge a PR
+// MergePullRequest merges an open PR
 func (g *GitFakeProvider) MergePullRequest(pr *PullRequest, message string) error {
-	panic("implement me")
+	data, err := g.repositoryData(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	if _, err := g.pullRequest(pr); err != nil {
+		return err
+	}
+	stored := data.PullRequests[*pr.Number]
+	if stored.IsClosed() {
+		return fmt.Errorf("pull request %d of %s/%s is already closed", *pr.Number, pr.Owner, pr.Repo)
+	}
+	state := PullRequestStateClosed
+	merged := true
+	now := time.Now()
+	stored.State = &state
+	stored.Merged = &merged
+	stored.MergedAt = &now
+	stored.ClosedAt = &now
+	*pr = *stored
+	return nil
 }
 
 // CreateWebHook create a webhook
 func (g *GitFakeProvider) CreateWebHook(data *WebhookArguments) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
 	log.Infof("Created fake WebHook at %s with repo %#v\n", data.URL, data.Repo)
+	data.ID = int64(len(g.WebHooks) + 1)
 	g.WebHooks = append(g.WebHooks, data)
 	return nil
 }
 
-// ListWebHooks list webhooks
+// ListWebHooks list the webhooks of the repository
 func (g *GitFakeProvider) ListWebHooks(org string, repo string) ([]*WebhookArguments, error) {
-	return g.WebHooks, nil
+	webHooks := []*WebhookArguments{}
+	for _, wh := range g.WebHooks {
+		if wh.Repo != nil && wh.Repo.Organisation == org && wh.Repo.Name == repo {
+			webHooks = append(webHooks, wh)
+		}
+	}
+	return webHooks, nil
 }
 
-// UpdateWebHook update webhook details
+// UpdateWebHook update webhook details, finding the webhook by ID or, when there is no ID, by URL
 func (g *GitFakeProvider) UpdateWebHook(data *WebhookArguments) error {
 	repo := data.Repo
 	if repo != nil {
 		for idx, wh := range g.WebHooks {
-			if wh.Repo != nil && wh.Repo.Organisation == repo.Organisation && wh.Repo.Name == repo.Name {
+			if wh.Repo == nil || wh.Repo.Organisation != repo.Organisation || wh.Repo.Name != repo.Name {
+				continue
+			}
+			if (data.ID != 0 && wh.ID == data.ID) || (data.ID == 0 && wh.URL == data.URL) {
 				g.WebHooks[idx] = data
 			}
 		}
@@ -187,6 +480,64 @@ func (g *GitFakeProvider) UpdateWebHook(data *WebhookArguments) error {
 	return nil
 }
 
+// ListWebHookDeliveries lists the deliveries of a webhook
+func (g *GitFakeProvider) ListWebHookDeliveries(org string, repo string, hookID int64) ([]*WebHookDelivery, error) {
+	return []*WebHookDelivery{}, nil
+}
+
+// RedeliverWebHook redelivers a webhook delivery
+func (g *GitFakeProvider) RedeliverWebHook(org string, repo string, hookID int64, deliveryID int64) error {
+	return nil
+}
+
+// PingWebHook pings a webhook
+func (g *GitFakeProvider) PingWebHook(org string, repo string, hookID int64) error {
+	return nil
+}
+
+// TriggerWebHook delivers the event to every webhook of the repository the way GitHub does, POSTing the payload with
+// the event in the X-GitHub-Event header and, when the webhook has a secret, the HMAC of the payload in the
+// X-Hub-Signature and X-Hub-Signature-256 headers
+func (g *GitFakeProvider) TriggerWebHook(repo *Repository, event string, payload []byte) error {
+	delivered := 0
+	for _, hook := range g.WebHooks {
+		if hook.Repo == nil || hook.Repo.Organisation != repo.Organisation || hook.Repo.Name != repo.Name {
+			continue
+		}
+		req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-GitHub-Delivery", strconv.Itoa(delivered+1))
+		if hook.Secret != "" {
+			req.Header.Set("X-Hub-Signature", "sha1="+signPayload(sha1.New, hook.Secret, payload))
+			req.Header.Set("X-Hub-Signature-256", "sha256="+signPayload(sha256.New, hook.Secret, payload))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("deliver %s event to %s: %s", event, hook.URL, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("deliver %s event to %s: status %d", event, hook.URL, resp.StatusCode)
+		}
+		delivered++
+	}
+	if delivered == 0 {
+		return fmt.Errorf("webhook of %s/%s: %w", repo.Organisation, repo.Name, ErrNotFound)
+	}
+	return nil
+}
+
+// signPayload returns the hex encoded HMAC of the payload keyed with the secret
+func signPayload(newHash func() hash.Hash, secret string, payload []byte) string {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // IsGitHub returns true if github
 func (g *GitFakeProvider) IsGitHub() bool {
 	return false
@@ -219,57 +570,297 @@ func (g *GitFakeProvider) Kind() string {
 
 // GetIssue get an issue
 func (g *GitFakeProvider) GetIssue(org string, name string, number int) (*Issue, error) {
-	panic("implement me")
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	issue, ok := data.Issues[number]
+	if !ok {
+		return nil, fmt.Errorf("issue %d of %s/%s: %w", number, org, name, ErrNotFound)
+	}
+	return issue, nil
 }
 
 // IssueURL get an issue URL
 func (g *GitFakeProvider) IssueURL(org string, name string, number int, isPull bool) string {
-	panic("implement me")
+	path := "issues"
+	if isPull {
+		path = "pull"
+	}
+	return util.UrlJoin(g.URL, org, name, path, strconv.Itoa(number))
 }
 
-// SearchIssues search issues
+// SearchIssues returns the issues matching the query, ordered by number
 func (g *GitFakeProvider) SearchIssues(org string, name string, query string) ([]*Issue, error) {
-	panic("implement me")
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	numbers := []int{}
+	for number := range data.Issues {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	issues := []*Issue{}
+	for _, number := range numbers {
+		issues = append(issues, data.Issues[number])
+	}
+	return ParseIssueQuery(query).FilterIssues(issues), nil
 }
 
 // SearchIssuesClosedSince search issues closed since
 func (g *GitFakeProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*Issue, error) {
-	panic("implement me")
+	issues, err := g.SearchIssues(org, name, "")
+	if err != nil {
+		return nil, err
+	}
+	answer := []*Issue{}
+	for _, issue := range issues {
+		if issue.ClosedAt != nil && issue.ClosedAt.After(t) {
+			answer = append(answer, issue)
+		}
+	}
+	return answer, nil
 }
 
-// CreateIssue create an issue
+// CreateIssue opens an issue, numbering it after the last pull request or issue of the repository
 func (g *GitFakeProvider) CreateIssue(owner string, repo string, issue *Issue) (*Issue, error) {
-	panic("implement me")
+	data, err := g.repositoryData(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	data.lastNumber++
+	number := data.lastNumber
+	state := "open"
+	now := time.Now()
+	user := g.User
+	issue.Number = &number
+	issue.Owner = owner
+	issue.Repo = repo
+	issue.URL = g.IssueURL(owner, repo, number, false)
+	issue.State = &state
+	issue.CreatedAt = &now
+	issue.User = &user
+	data.Issues[number] = issue
+	return issue, nil
 }
 
 // HasIssues returns true if has issues
 func (g *GitFakeProvider) HasIssues() bool {
-	panic("implement me")
+	return true
+}
+
+// SetIssuesEnabled turns the issue tracker of the fake repository on or off
+func (g *GitFakeProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	repo.HasIssuesEnabled = enabled
+	return nil
 }
 
 // AddPRComment add a comment to a PR
 func (g *GitFakeProvider) AddPRComment(pr *PullRequest, comment string) error {
-	panic("implement me")
+	if _, err := g.pullRequest(pr); err != nil {
+		return err
+	}
+	data := g.RepositoryData[pr.Owner+"/"+pr.Repo]
+	data.Comments[*pr.Number] = append(data.Comments[*pr.Number], comment)
+	return nil
 }
 
 // CreateIssueComment create a comment on an issue
 func (g *GitFakeProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
-	panic("implement me")
+	if _, err := g.GetIssue(owner, repo, number); err != nil {
+		return err
+	}
+	data := g.RepositoryData[owner+"/"+repo]
+	data.Comments[number] = append(data.Comments[number], comment)
+	return nil
 }
 
-// UpdateRelease update a release
+// AddLabelsToIssue adds the labels the issue or pull request with the number doesn't have yet
+func (g *GitFakeProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return g.editLabels(owner, repo, number, func(existing []Label) []Label {
+		for _, label := range labels {
+			if !hasLabel(existing, label) {
+				existing = append(existing, Label{Name: label})
+			}
+		}
+		return existing
+	})
+}
+
+// RemoveLabelFromIssue removes the label from the issue or pull request with the number
+func (g *GitFakeProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return g.editLabels(owner, repo, number, func(existing []Label) []Label {
+		answer := []Label{}
+		for _, l := range existing {
+			if l.Name != label {
+				answer = append(answer, l)
+			}
+		}
+		return answer
+	})
+}
+
+// editLabels replaces the labels of the stored issue or pull request with the number with the ones returned by edit
+func (g *GitFakeProvider) editLabels(owner string, repo string, number int, edit func([]Label) []Label) error {
+	data, err := g.repositoryData(owner, repo)
+	if err != nil {
+		return err
+	}
+	if issue, ok := data.Issues[number]; ok {
+		issue.Labels = edit(issue.Labels)
+		return nil
+	}
+	if pr, ok := data.PullRequests[number]; ok {
+		pr.Labels = edit(pr.Labels)
+		return nil
+	}
+	return fmt.Errorf("issue %s/%s#%d: %w", owner, repo, number, ErrNotFound)
+}
+
+// GetPRComments returns the comments added to the pull request, or nil if there is no such pull request
+func (g *GitFakeProvider) GetPRComments(owner string, repo string, number int) []string {
+	data := g.RepositoryData[owner+"/"+repo]
+	if data == nil || data.PullRequests[number] == nil {
+		return nil
+	}
+	return data.Comments[number]
+}
+
+// GetIssueComments returns the comments added to the issue, or nil if there is no such issue
+func (g *GitFakeProvider) GetIssueComments(owner string, repo string, number int) []string {
+	data := g.RepositoryData[owner+"/"+repo]
+	if data == nil || data.Issues[number] == nil {
+		return nil
+	}
+	return data.Comments[number]
+}
+
+// ListMilestones list the milestones of the repository
+func (g *GitFakeProvider) ListMilestones(org string, name string) ([]*Milestone, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*Milestone{}, data.Milestones...), nil
+}
+
+// CreateMilestone adds an open milestone to the repository, numbering milestones from 1 as GitHub does
+func (g *GitFakeProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*Milestone, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	milestone := &Milestone{
+		ID:    int64(len(data.Milestones) + 1),
+		Title: title,
+		State: MilestoneStateOpen,
+		DueOn: due,
+	}
+	data.Milestones = append(data.Milestones, milestone)
+	return milestone, nil
+}
+
+// CloseMilestone close a milestone
+func (g *GitFakeProvider) CloseMilestone(org string, name string, id int64) error {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return err
+	}
+	for _, milestone := range data.Milestones {
+		if milestone.ID == id {
+			milestone.State = MilestoneStateClosed
+			return nil
+		}
+	}
+	return fmt.Errorf("milestone %d of %s/%s: %w", id, org, name, ErrNotFound)
+}
+
+// ListContributors returns the contributors stored for the repository, most contributions first
+func (g *GitFakeProvider) ListContributors(org string, name string) ([]*Contributor, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := append([]*Contributor{}, data.Contributors...)
+	SortContributors(answer)
+	return answer, nil
+}
+
+// UpdateRelease creates or replaces the release of the tag
 func (g *GitFakeProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *Release) error {
-	panic("implement me")
+	data, err := g.repositoryData(owner, repo)
+	if err != nil {
+		return err
+	}
+	if releaseInfo.TagName == "" {
+		releaseInfo.TagName = tag
+	}
+	data.Releases[tag] = releaseInfo
+	return nil
 }
 
-// ListReleases list the releases
+// ListReleases returns the releases ordered by tag
 func (g *GitFakeProvider) ListReleases(org string, name string) ([]*Release, error) {
-	panic("implement me")
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := []*Release{}
+	for _, release := range data.Releases {
+		answer = append(answer, release)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].TagName < answer[j].TagName
+	})
+	return answer, nil
 }
 
-// GetContent gets the content for a file
+// GetContent gets the content for a file, which the fake doesn't store
 func (g *GitFakeProvider) GetContent(org string, name string, path string, ref string) (*FileContent, error) {
-	panic("implement me")
+	return nil, fmt.Errorf("get content of %s in %s/%s: %w", path, org, name, ErrNotSupported)
+}
+
+// ResolveRef returns the SHA of the branch or, if there is no such branch, of the tag
+func (g *GitFakeProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return "", err
+	}
+	if sha, ok := data.Branches[ref]; ok {
+		return sha, nil
+	}
+	if tag, ok := data.Tags[ref]; ok {
+		return tag.SHA, nil
+	}
+	return "", fmt.Errorf("ref %s of %s/%s: %w", ref, org, name, ErrNotFound)
+}
+
+// GetFileLastCommit gets the last commit which changed a file, which the fake doesn't track
+func (g *GitFakeProvider) GetFileLastCommit(org string, name string, path string, ref string) (*Commit, error) {
+	return nil, fmt.Errorf("last commit of %s in %s/%s: %w", path, org, name, ErrNotSupported)
+}
+
+// BranchesContainingCommit returns the branches pointing at the commit, sorted by name, as the fake doesn't track
+// the history of branches
+func (g *GitFakeProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := []string{}
+	for branch, branchSHA := range data.Branches {
+		if branchSHA == sha {
+			answer = append(answer, branch)
+		}
+	}
+	sort.Strings(answer)
+	return answer, nil
 }
 
 // JenkinsWebHookPath returns the path for jenkins webhooks
@@ -289,7 +880,7 @@ func (g *GitFakeProvider) ServerURL() string {
 
 // BranchArchiveURL returns the branch archive URL
 func (g *GitFakeProvider) BranchArchiveURL(org string, name string, branch string) string {
-	panic("implement me")
+	return util.UrlJoin(g.URL, org, name, "archive", branch+".zip")
 }
 
 // CurrentUsername returns the current user name
@@ -297,28 +888,201 @@ func (g *GitFakeProvider) CurrentUsername() string {
 	return g.User.Login
 }
 
-// UserInfo returns the user info for the given user name
+// UserInfo returns the fake user if it has the user name, otherwise nil
 func (g *GitFakeProvider) UserInfo(username string) *User {
-	panic("implement me")
+	if username == "" || username != g.User.Login {
+		return nil
+	}
+	user := g.User
+	return &user
+}
+
+// GetUsers returns the users found by UserInfo, keyed by user name
+func (g *GitFakeProvider) GetUsers(usernames []string) (map[string]*User, error) {
+	answer := map[string]*User{}
+	for _, username := range usernames {
+		if user := g.UserInfo(username); user != nil {
+			answer[username] = user
+		}
+	}
+	return answer, nil
+}
+
+// GetCurrentUser returns the current user
+func (g *GitFakeProvider) GetCurrentUser() (*User, error) {
+	return &g.User, nil
+}
+
+// DeleteBranch forgets a branch of a repository, refusing to delete the default branch
+func (g *GitFakeProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return err
+	}
+	if branch == fakeDefaultBranch(repo) {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, ErrDefaultBranch)
+	}
+	if _, ok := data.Branches[branch]; !ok {
+		return fmt.Errorf("branch %s of %s/%s: %w", branch, org, name, ErrNotFound)
+	}
+	delete(data.Branches, branch)
+	return nil
+}
+
+// CreateBranch records the branch with the SHA the ref resolves to, or with the ref itself if it is neither a branch
+// nor a tag, as the fake can't tell a SHA from a missing ref
+func (g *GitFakeProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return err
+	}
+	if _, ok := data.Branches[branch]; ok || branch == fakeDefaultBranch(repo) {
+		return fmt.Errorf("create branch %s of %s/%s: %w", branch, org, name, ErrAlreadyExists)
+	}
+	sha, err := g.ResolveRef(org, name, fromRef)
+	if IsNotFound(err) {
+		sha, err = fromRef, nil
+	}
+	if err != nil {
+		return err
+	}
+	data.Branches[branch] = sha
+	return nil
+}
+
+// CreateTag creates a tag on a repository
+func (g *GitFakeProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return err
+	}
+	if _, ok := data.Tags[tag]; ok {
+		return fmt.Errorf("tag %s of %s/%s: %w", tag, org, name, ErrAlreadyExists)
+	}
+	data.Tags[tag] = &GitTag{
+		Name:    tag,
+		Message: message,
+		SHA:     sha,
+	}
+	return nil
+}
+
+// GetTag gets a tag of a repository, or nil if there is no such tag
+func (g *GitFakeProvider) GetTag(org string, name string, tag string) (*GitTag, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return data.Tags[tag], nil
+}
+
+// ListRepositoryTags lists the tags of a repository sorted by name
+func (g *GitFakeProvider) ListRepositoryTags(org string, name string) ([]*GitTag, error) {
+	data, err := g.repositoryData(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := []*GitTag{}
+	for _, tag := range data.Tags {
+		answer = append(answer, tag)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// GetAheadBehind compares the branch of a fork with its upstream, which the fake can't do as it doesn't track commits
+func (g *GitFakeProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	return 0, 0, fmt.Errorf("compare %s of %s/%s with %s/%s: %w", branch, org, name, upstreamOrg, upstreamName, ErrNotSupported)
+}
+
+// SyncFork updates a fork from its upstream, which the fake can't do as it doesn't track commits
+func (g *GitFakeProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return fmt.Errorf("sync %s of %s/%s with %s/%s: %w", branch, org, name, upstreamOrg, upstreamName, ErrNotSupported)
+}
+
+// ListPullRequestActivity returns the events of a pull request, which the fake doesn't record
+func (g *GitFakeProvider) ListPullRequestActivity(pr *PullRequest) ([]*PullRequestEvent, error) {
+	return nil, fmt.Errorf("activity of pull request %s: %w", pr.URL, ErrNotSupported)
+}
+
+// GetPullRequestStats returns the size of the change of a pull request, which the fake doesn't know
+func (g *GitFakeProvider) GetPullRequestStats(pr *PullRequest) (*DiffStat, error) {
+	return nil, fmt.Errorf("stats of pull request %s: %w", pr.URL, ErrNotSupported)
+}
+
+// GetPullRequestApprovalState returns the approvals of a pull request, which the fake doesn't record
+func (g *GitFakeProvider) GetPullRequestApprovalState(pr *PullRequest) (*ApprovalState, error) {
+	return nil, fmt.Errorf("approvals of pull request %s: %w", pr.URL, ErrNotSupported)
 }
 
 // AddCollaborator adds a collaborator
-func (g *GitFakeProvider) AddCollaborator(string, string, string) error {
-	panic("implement me")
+func (g *GitFakeProvider) AddCollaborator(user string, organisation string, repo string) error {
+	return fmt.Errorf("add collaborator %s to %s/%s: %w", user, organisation, repo, ErrNotSupported)
 }
 
 // ListInvitations list invitations
-func (g *GitFakeProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	panic("implement me")
+func (g *GitFakeProvider) ListInvitations() ([]*Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", ErrNotSupported)
 }
 
 // AcceptInvitation accepts invitation
-func (g *GitFakeProvider) AcceptInvitation(int64) (*github.Response, error) {
-	panic("implement me")
+func (g *GitFakeProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation %d: %w", ID, ErrNotSupported)
 }
 
 func (g *GitFakeProvider) notFound() error {
-	return fmt.Errorf("Not found")
+	return fmt.Errorf("Not found: %w", ErrNotFound)
+}
+
+// repositoryData returns what is stored for the repository, which must have been created
+func (g *GitFakeProvider) repositoryData(org string, name string) (*GitFakeRepositoryData, error) {
+	if _, err := g.GetRepository(org, name); err != nil {
+		return nil, err
+	}
+	if g.RepositoryData == nil {
+		g.RepositoryData = map[string]*GitFakeRepositoryData{}
+	}
+	key := org + "/" + name
+	data, ok := g.RepositoryData[key]
+	if !ok {
+		data = &GitFakeRepositoryData{
+			PullRequests: map[int]*PullRequest{},
+			Issues:       map[int]*Issue{},
+			Comments:     map[int][]string{},
+			Statuses:     map[string][]*RepoStatus{},
+			Releases:     map[string]*Release{},
+			Branches:     map[string]string{},
+			Tags:         map[string]*GitTag{},
+		}
+		g.RepositoryData[key] = data
+	}
+	return data, nil
+}
+
+// fakeDefaultBranch returns the default branch of the repository, which is master unless it says otherwise
+func fakeDefaultBranch(repo *Repository) string {
+	if repo.DefaultBranch == "" {
+		return "master"
+	}
+	return repo.DefaultBranch
+}
+
+// pullRequest returns the stored pull request with the owner, repository and number of the given one
+func (g *GitFakeProvider) pullRequest(pr *PullRequest) (*PullRequest, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("pull request of %s/%s has no number", pr.Owner, pr.Repo)
+	}
+	return g.GetPullRequest(pr.Owner, &Repository{Name: pr.Repo}, *pr.Number)
 }
 
 func (g *GitFakeProvider) AccessTokenURL() string {
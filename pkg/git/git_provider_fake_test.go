@@ -0,0 +1,266 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitFakeProvider returns a fake git provider with the repository test-org/test-repo
+func newTestGitFakeProvider(t *testing.T) (*GitFakeProvider, *Repository) {
+	provider, err := NewFakeGitProvider("test-user", "fake", NewGitFake())
+	require.NoError(t, err)
+	fakeProvider := provider.(*GitFakeProvider)
+	fakeProvider.User = User{Login: "test-user"}
+
+	repo, err := fakeProvider.CreateRepository("test-org", "test-repo", false)
+	require.NoError(t, err)
+	repo.Organisation = "test-org"
+	return fakeProvider, repo
+}
+
+func TestGitFakeProviderPullRequests(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+
+	pr, err := provider.CreatePullRequest(&PullRequestArguments{
+		Title:      "Add a feature",
+		Head:       "feature",
+		Base:       "master",
+		Repository: repo,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, *pr.Number)
+	assert.Equal(t, "https://fake.git/test-org/test-repo/pull/1", pr.URL)
+	assert.Equal(t, "test-user", pr.Author.Login)
+
+	found, err := provider.GetPullRequest("test-org", repo, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Add a feature", found.Title)
+	assert.False(t, found.IsClosed())
+
+	byBranch, err := provider.GetPullRequestByBranch("test-org", repo, "feature")
+	require.NoError(t, err)
+	require.NotNil(t, byBranch)
+	assert.Equal(t, 1, *byBranch.Number)
+
+	require.NoError(t, provider.MergePullRequest(found, "Merge the feature"))
+	assert.True(t, *found.Merged)
+	assert.True(t, found.IsClosed())
+	assert.Error(t, provider.MergePullRequest(found, "Merge it again"))
+
+	merged, err := provider.GetPullRequest("test-org", repo, 1)
+	require.NoError(t, err)
+	assert.True(t, *merged.Merged)
+
+	open, err := provider.ListPullRequests("test-org", repo, PullRequestStateOpen)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+	closed, err := provider.ListPullRequests("test-org", repo, PullRequestStateClosed)
+	require.NoError(t, err)
+	assert.Len(t, closed, 1)
+
+	_, err = provider.GetPullRequest("test-org", repo, 2)
+	assert.True(t, IsNotFound(err))
+	_, err = provider.CreatePullRequest(&PullRequestArguments{Repository: &Repository{Organisation: "test-org", Name: "missing"}})
+	assert.True(t, IsNotFound(err))
+}
+
+func TestGitFakeProviderIssuesAndStatuses(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+
+	pr, err := provider.CreatePullRequest(&PullRequestArguments{Head: "feature", Base: "master", Repository: repo})
+	require.NoError(t, err)
+	issue, err := provider.CreateIssue("test-org", "test-repo", &Issue{Title: "Something is broken"})
+	require.NoError(t, err)
+	// issues are numbered after the pull requests
+	assert.Equal(t, 2, *issue.Number)
+	require.NoError(t, provider.CreateIssueComment("test-org", "test-repo", 2, "It is"))
+	assert.Equal(t, []string{"It is"}, provider.GetIssueComments("test-org", "test-repo", 2))
+
+	issues, err := provider.SearchIssues("test-org", "test-repo", "is:open")
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+
+	provider.RepositoryData["test-org/test-repo"].PullRequests[*pr.Number].LastCommitSha = "abc123"
+	_, err = provider.UpdateCommitStatus("test-org", "test-repo", "abc123", &RepoStatus{Context: "ci", State: "pending"})
+	require.NoError(t, err)
+	_, err = provider.UpdateCommitStatus("test-org", "test-repo", "abc123", &RepoStatus{Context: "ci", State: "success"})
+	require.NoError(t, err)
+
+	statuses, err := provider.ListCommitStatus("test-org", "test-repo", "abc123")
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	state, err := provider.PullRequestLastCommitStatus(pr)
+	require.NoError(t, err)
+	assert.Equal(t, "success", state)
+
+	require.NoError(t, provider.UpdateRelease("test-org", "test-repo", "v1.0.0", &Release{Name: "1.0.0"}))
+	releases, err := provider.ListReleases("test-org", "test-repo")
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "v1.0.0", releases[0].TagName)
+}
+
+func TestGitFakeProviderComments(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+
+	pr, err := provider.CreatePullRequest(&PullRequestArguments{Head: "feature", Base: "master", Repository: repo})
+	require.NoError(t, err)
+	issue, err := provider.CreateIssue("test-org", "test-repo", &Issue{Title: "Something is broken"})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.AddPRComment(pr, "/lgtm"))
+	require.NoError(t, provider.AddPRComment(pr, "/approve"))
+	require.NoError(t, provider.CreateIssueComment("test-org", "test-repo", *issue.Number, "/assign"))
+
+	assert.Equal(t, []string{"/lgtm", "/approve"}, provider.GetPRComments("test-org", "test-repo", *pr.Number))
+	assert.Equal(t, []string{"/assign"}, provider.GetIssueComments("test-org", "test-repo", *issue.Number))
+	// the numbers are shared, so each getter only reads its own kind
+	assert.Nil(t, provider.GetIssueComments("test-org", "test-repo", *pr.Number))
+	assert.Nil(t, provider.GetPRComments("test-org", "missing", *pr.Number))
+
+	assert.True(t, IsNotFound(provider.CreateIssueComment("test-org", "test-repo", 10, "Hello")))
+}
+
+func TestGitFakeProviderLabels(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+
+	pr, err := provider.CreatePullRequest(&PullRequestArguments{Head: "feature", Base: "master", Repository: repo})
+	require.NoError(t, err)
+	issue, err := provider.CreateIssue("test-org", "test-repo", &Issue{Title: "Something is broken"})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.AddLabelsToIssue("test-org", "test-repo", *pr.Number, []string{"lgtm", "approved"}))
+	require.NoError(t, provider.AddLabelsToIssue("test-org", "test-repo", *pr.Number, []string{"lgtm"}))
+	require.NoError(t, provider.RemoveLabelFromIssue("test-org", "test-repo", *pr.Number, "approved"))
+	stored, err := provider.GetPullRequest("test-org", repo, *pr.Number)
+	require.NoError(t, err)
+	assert.Equal(t, []Label{{Name: "lgtm"}}, stored.Labels)
+
+	require.NoError(t, provider.AddLabelsToIssue("test-org", "test-repo", *issue.Number, []string{"bug"}))
+	require.NoError(t, provider.RemoveLabelFromIssue("test-org", "test-repo", *issue.Number, "bug"))
+	assert.Empty(t, issue.Labels)
+
+	assert.True(t, IsNotFound(provider.AddLabelsToIssue("test-org", "test-repo", 10, []string{"bug"})))
+}
+
+func TestGitFakeProviderRepositoryData(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+
+	milestone, err := provider.CreateMilestone("test-org", "test-repo", "1.0", nil)
+	require.NoError(t, err)
+	require.NoError(t, provider.CloseMilestone("test-org", "test-repo", milestone.ID))
+	milestones, err := provider.ListMilestones("test-org", "test-repo")
+	require.NoError(t, err)
+	require.Len(t, milestones, 1)
+	assert.Equal(t, MilestoneStateClosed, milestones[0].State)
+	assert.True(t, IsNotFound(provider.CloseMilestone("test-org", "test-repo", 2)))
+
+	require.NoError(t, provider.SetRepositoryTopics("test-org", "test-repo", []string{"go", "git"}))
+	assert.Equal(t, []string{"go", "git"}, repo.Topics)
+	assert.True(t, IsNotFound(provider.SetRepositoryTopics("test-org", "missing", []string{"go"})))
+
+	require.NoError(t, provider.SetMergeConfig("test-org", "test-repo", &MergeConfig{AllowSquash: true}))
+	config, err := provider.GetMergeConfig("test-org", "test-repo")
+	require.NoError(t, err)
+	assert.True(t, config.AllowSquash)
+
+	require.NoError(t, provider.CreateTag("test-org", "test-repo", "v1.0.0", "abc123", "Release 1.0.0"))
+	assert.True(t, IsAlreadyExists(provider.CreateTag("test-org", "test-repo", "v1.0.0", "def456", "")))
+	tag, err := provider.GetTag("test-org", "test-repo", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", tag.SHA)
+	sha, err := provider.ResolveRef("test-org", "test-repo", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", sha)
+	_, err = provider.ResolveRef("test-org", "test-repo", "missing")
+	assert.True(t, IsNotFound(err))
+
+	require.NoError(t, provider.CreateBranch("test-org", "test-repo", "feature", "v1.0.0"))
+	assert.True(t, IsAlreadyExists(provider.CreateBranch("test-org", "test-repo", "feature", "def456")))
+	assert.True(t, IsAlreadyExists(provider.CreateBranch("test-org", "test-repo", "master", "def456")))
+	require.NoError(t, provider.CreateBranch("test-org", "test-repo", "hotfix", "def456"))
+	sha, err = provider.ResolveRef("test-org", "test-repo", "hotfix")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", sha)
+	branches, err := provider.BranchesContainingCommit("test-org", "test-repo", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature"}, branches)
+	assert.True(t, IsDefaultBranch(provider.DeleteBranch("test-org", "test-repo", "master")))
+	require.NoError(t, provider.DeleteBranch("test-org", "test-repo", "feature"))
+	assert.True(t, IsNotFound(provider.DeleteBranch("test-org", "test-repo", "feature")))
+
+	fork, err := provider.ForkRepository("test-org", "test-repo", "test-user")
+	require.NoError(t, err)
+	assert.True(t, fork.Fork)
+	assert.Equal(t, "test-user", fork.Organisation)
+	assert.True(t, IsAlreadyExists(provider.ValidateRepositoryName("test-org", "test-repo")))
+	assert.NoError(t, provider.ValidateRepositoryName("test-org", "other-repo"))
+
+	users, err := provider.GetUsers([]string{"test-user", "someone-else"})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, _, err = provider.GetAheadBehind("test-user", "test-repo", "test-org", "test-repo", "master")
+	assert.True(t, IsNotSupported(err))
+	_, err = provider.GetContent("test-org", "test-repo", "README.md", "master")
+	assert.True(t, IsNotSupported(err))
+	_, err = provider.ListPullRequestActivity(&PullRequest{Owner: repo.Organisation, Repo: repo.Name})
+	assert.True(t, IsNotSupported(err))
+}
+
+func TestGitFakeProviderTriggerWebHook(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+	payload := []byte(`{"ref": "refs/heads/master"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	err := provider.TriggerWebHook(repo, "push", payload)
+	assert.True(t, IsNotFound(err))
+
+	err = provider.CreateWebHook(&WebhookArguments{Repo: repo, URL: server.URL, Secret: "s3cr3t"})
+	require.NoError(t, err)
+	err = provider.TriggerWebHook(repo, "push", payload)
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "push", received.Header.Get("X-GitHub-Event"))
+	assert.Equal(t, payload, body)
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(payload)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get("X-Hub-Signature-256"))
+	assert.Contains(t, received.Header.Get("X-Hub-Signature"), "sha1=")
+
+	// a failing handler fails the delivery
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	err = provider.TriggerWebHook(repo, "push", payload)
+	assert.Error(t, err)
+}
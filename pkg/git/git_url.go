@@ -3,6 +3,7 @@ package git
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/jenkins-x/jx/pkg/util"
@@ -96,6 +97,17 @@ func (i *Repository) PipelinePath() string {
 	return i.Organisation + "/" + i.Name + "/master"
 }
 
+// NormalizeServerURL returns the URL of a git server without trailing slashes, adding https:// if it has no
+// scheme. The path of servers hosted under a subpath, such as https://example.com/gitlab, is kept so that their
+// APIs are looked for under it
+func NormalizeServerURL(serverURL string) string {
+	u := strings.TrimRight(strings.TrimSpace(serverURL), "/")
+	if u != "" && !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	return u
+}
+
 // ParseGitURL attempts to parse the given text as a URL or git URL-like string to determine
 // the protocol, host, organisation and name
 func ParseGitURL(text string) (*Repository, error) {
@@ -137,6 +149,16 @@ func ParseGitURL(text string) (*Repository, error) {
 	return nil, fmt.Errorf("Could not parse Git URL %s", text)
 }
 
+// cloneDir returns a stable directory under baseDir to clone the given git URL into
+func cloneDir(gitURL string, baseDir string) (string, error) {
+	info, err := ParseGitURL(gitURL)
+	if err != nil {
+		return "", err
+	}
+	host := strings.Replace(info.Host, ":", "_", -1)
+	return filepath.Join(baseDir, host, info.Organisation, info.Name), nil
+}
+
 func parsePath(path string, info *Repository) (*Repository, error) {
 
 	// This is necessary for Bitbucket Server in some cases.
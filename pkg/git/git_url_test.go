@@ -62,3 +62,33 @@ func TestParseGitURL(t *testing.T) {
 		assert.Equal(t, data.name, info.Name, "Name does not match for input %s", data.url)
 	}
 }
+
+func TestNormalizeServerURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", NormalizeServerURL(""))
+	assert.Equal(t, "https://gitlab.com", NormalizeServerURL("https://gitlab.com/"))
+	assert.Equal(t, "https://example.com/gitlab", NormalizeServerURL("https://example.com/gitlab/"))
+	assert.Equal(t, "https://example.com/gitea", NormalizeServerURL("example.com/gitea"))
+	assert.Equal(t, "http://localhost:3000/git", NormalizeServerURL("http://localhost:3000/git//"))
+}
+
+func TestEnsureCloneDirIsStable(t *testing.T) {
+	t.Parallel()
+	g := &GitFake{}
+
+	dir, err := g.EnsureClone("https://github.com/fabric8io/foo.git", "/tmp/repos")
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/repos/github.com/fabric8io/foo", dir)
+
+	// ssh and https URLs for the same repository share a checkout
+	dir, err = g.EnsureClone("git@github.com:fabric8io/foo.git", "/tmp/repos")
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/repos/github.com/fabric8io/foo", dir)
+
+	dir, err = g.EnsureClone("http://localhost:3000/org/repo", "/tmp/repos")
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/repos/localhost_3000/org/repo", dir)
+
+	assert.Len(t, g.ClonedDirs, 3)
+}
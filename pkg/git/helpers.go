@@ -1,6 +1,8 @@
 package git
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/user"
 
@@ -41,3 +43,84 @@ func EnsureUserAndEmailSetup(gitter Gitter) (string, string, error) {
 	}
 	return userName, userEmail, nil
 }
+
+// EnsureRepository returns the repository if it already exists and creates it otherwise, so that imports can be run
+// again without failing on the repositories they created before
+func EnsureRepository(provider Provider, org string, name string, private bool) (*Repository, error) {
+	repo, err := provider.GetRepository(org, name)
+	if err == nil {
+		return repo, nil
+	}
+	if !IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get repository %s/%s", org, name)
+	}
+	return provider.CreateRepository(org, name, private)
+}
+
+// SyncForkWithGit merges the branch of the upstream repository into the fork using a local clone, for
+// providers whose server can't sync a fork itself. The token of the provider authenticates the clone and push, with
+// the PushUsername of the options when the provider has no username
+func SyncForkWithGit(provider Provider, gitter Gitter, options ProviderOptions, token string, org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	if gitter == nil {
+		return fmt.Errorf("sync fork without a git client: %w", ErrNotSupported)
+	}
+	fork, err := provider.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	upstream, err := provider.GetRepository(upstreamOrg, upstreamName)
+	if err != nil {
+		return err
+	}
+	username := options.PushUsername(provider.Kind(), provider.CurrentUsername(), token)
+	forkURL, err := gitter.CreatePushURL(fork.CloneURL, username, token)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to create the push URL of %s", fork.CloneURL)
+	}
+	upstreamURL, err := gitter.CreatePushURL(upstream.CloneURL, username, token)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to create the URL of %s", upstream.CloneURL)
+	}
+	return SyncForkFromUpstream(gitter, forkURL, upstreamURL, branch)
+}
+
+// SyncForkFromUpstream merges the branch of the upstream repository into the same branch of the
+// fork using a temporary clone, then pushes the result back to the fork. The URLs may carry credentials so
+// they are left out of the errors
+func SyncForkFromUpstream(gitter Gitter, forkURL string, upstreamURL string, branch string) error {
+	dir, err := ioutil.TempDir("", "sync-fork-")
+	if err != nil {
+		return errors.Wrap(err, "Failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	_, _, err = EnsureUserAndEmailSetup(gitter)
+	if err != nil {
+		return err
+	}
+	err = gitter.Clone(forkURL, dir)
+	if err != nil {
+		return errors.Wrap(err, "Failed to clone the fork")
+	}
+	err = gitter.AddRemote(dir, "upstream", upstreamURL)
+	if err != nil {
+		return errors.Wrap(err, "Failed to add the upstream remote")
+	}
+	err = gitter.FetchBranch(dir, "upstream", branch)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to fetch branch %s from upstream", branch)
+	}
+	err = gitter.Checkout(dir, branch)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to checkout branch %s", branch)
+	}
+	err = gitter.Merge(dir, "upstream/"+branch)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to merge upstream/%s into %s", branch, branch)
+	}
+	err = gitter.Push(dir)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to push branch %s to the fork", branch)
+	}
+	return nil
+}
@@ -23,8 +23,20 @@ type OrganisationChecker interface {
 type Provider interface {
 	OrganisationLister
 
+	// ListTeams returns the teams of an organisation
+	ListTeams(org string) ([]*Team, error)
+
+	// ListRepositories returns the repositories of an organisation. Passing a blank organisation is deprecated
+	// as its meaning differs between providers, use ListRepositoriesForCurrentUser instead
 	ListRepositories(org string) ([]*Repository, error)
 
+	// ListRepositoriesPaged returns a page of the repositories of an organisation, so that large organisations
+	// can be listed without holding all their repositories at once
+	ListRepositoriesPaged(org string, options ListOptions) (*RepositoryPage, error)
+
+	// ListRepositoriesForCurrentUser returns the repositories owned by the user the provider is authenticated as
+	ListRepositoriesForCurrentUser() ([]*Repository, error)
+
 	CreateRepository(org string, name string, private bool) (*Repository, error)
 
 	GetRepository(org string, name string) (*Repository, error)
@@ -37,18 +49,58 @@ type Provider interface {
 
 	ValidateRepositoryName(org string, name string) error
 
+	// GetMergeConfig returns how the pull requests of a repository can be merged
+	GetMergeConfig(org string, name string) (*MergeConfig, error)
+
+	// SetMergeConfig changes how the pull requests of a repository can be merged
+	SetMergeConfig(org string, name string, config *MergeConfig) error
+
+	// SetRepositoryTopics replaces the topics of a repository. Bitbucket and Gerrit have no topics and return
+	// ErrNotSupported
+	SetRepositoryTopics(org string, name string, topics []string) error
+
 	CreatePullRequest(data *PullRequestArguments) (*PullRequest, error)
 
 	UpdatePullRequestStatus(pr *PullRequest) error
 
 	GetPullRequest(owner string, repo *Repository, number int) (*PullRequest, error)
 
+	// GetPullRequestByBranch returns the open pull request from the branch of the repository, or nil if there is none
+	GetPullRequestByBranch(owner string, repo *Repository, branch string) (*PullRequest, error)
+
 	GetPullRequestCommits(owner string, repo *Repository, number int) ([]*Commit, error)
 
+	// ListPullRequests returns the pull requests of a repository in the given state, which is one of
+	// PullRequestStateOpen, PullRequestStateClosed or PullRequestStateAll
+	ListPullRequests(owner string, repo *Repository, state string) ([]*PullRequest, error)
+
+	// ListPullRequestsByAuthor returns the pull requests of a repository in the given state which were opened by
+	// the user with the given login, asking the provider to filter them where its API can
+	ListPullRequestsByAuthor(owner string, repo *Repository, author string, state string) ([]*PullRequest, error)
+
+	// ClosePullRequest closes a pull request without merging it
+	ClosePullRequest(pr *PullRequest) error
+
 	PullRequestLastCommitStatus(pr *PullRequest) (string, error)
 
+	// ListPullRequestActivity returns the events of the pull request, oldest first
+	ListPullRequestActivity(pr *PullRequest) ([]*PullRequestEvent, error)
+
+	// GetPullRequestStats returns the number of lines added and removed and of files changed by the pull request
+	GetPullRequestStats(pr *PullRequest) (*DiffStat, error)
+
+	// GetPullRequestApprovalState returns the approvals of the pull request. Only GitLab reports approvals, and
+	// whether the project resets them when commits are pushed; see NeedsReapproval
+	GetPullRequestApprovalState(pr *PullRequest) (*ApprovalState, error)
+
 	ListCommitStatus(org string, repo string, sha string) ([]*RepoStatus, error)
 
+	// GetCommitStatus returns the latest status of the given context for a commit, or nil if there is none
+	GetCommitStatus(org string, repo string, sha string, context string) (*RepoStatus, error)
+
+	// UpdateCommitStatus reports the status of the commit under the context of the status, replacing any status
+	// with the same context. Each pipeline should use a context of its own so they don't overwrite each other's
+	// statuses; the context must be set and at most MaxStatusContextLength characters long
 	UpdateCommitStatus(org string, repo string, sha string, status *RepoStatus) (*RepoStatus, error)
 
 	MergePullRequest(pr *PullRequest, message string) error
@@ -59,6 +111,15 @@ type Provider interface {
 
 	UpdateWebHook(data *WebhookArguments) error
 
+	// ListWebHookDeliveries returns the recent deliveries of a webhook, to find out why it is not triggering
+	ListWebHookDeliveries(org string, repo string, hookID int64) ([]*WebHookDelivery, error)
+
+	// RedeliverWebHook sends a previous delivery of a webhook again
+	RedeliverWebHook(org string, repo string, hookID int64, deliveryID int64) error
+
+	// PingWebHook sends a test delivery to a webhook to check the endpoint can be reached
+	PingWebHook(org string, repo string, hookID int64) error
+
 	IsGitHub() bool
 
 	IsGitea() bool
@@ -83,16 +144,77 @@ type Provider interface {
 
 	HasIssues() bool
 
+	// SetIssuesEnabled turns the issue tracker of a repository on or off
+	SetIssuesEnabled(org string, name string, enabled bool) error
+
 	AddPRComment(pr *PullRequest, comment string) error
 
 	CreateIssueComment(owner string, repo string, number int, comment string) error
 
+	// AddLabelsToIssue adds the labels to an issue or pull request. GitLab only labels issues, as merge requests are
+	// numbered apart from them. Bitbucket and Gerrit have no labels and return ErrNotSupported
+	AddLabelsToIssue(owner string, repo string, number int, labels []string) error
+
+	// RemoveLabelFromIssue removes the label from an issue or pull request. Removing a label the issue doesn't have
+	// is not an error
+	RemoveLabelFromIssue(owner string, repo string, number int, label string) error
+
+	// ListMilestones returns the open and closed milestones of a repository
+	ListMilestones(org string, name string) ([]*Milestone, error)
+
+	// CreateMilestone creates an open milestone, due on the given date unless it is nil
+	CreateMilestone(org string, name string, title string, due *time.Time) (*Milestone, error)
+
+	// CloseMilestone closes the milestone with the given ID
+	CloseMilestone(org string, name string, id int64) error
+
+	// ListContributors returns the users who committed to a repository, most contributions first
+	ListContributors(org string, name string) ([]*Contributor, error)
+
 	UpdateRelease(owner string, repo string, tag string, releaseInfo *Release) error
 
 	ListReleases(org string, name string) ([]*Release, error)
 
+	// GetContent returns the decoded content of the file at the ref. A missing file gives an error wrapping
+	// ErrNotFound
 	GetContent(org string, name string, path string, ref string) (*FileContent, error)
 
+	// ResolveRef returns the SHA of the commit a branch or tag of the repository currently points at, so that
+	// reads such as GetContent can be pinned to it. A missing ref gives an error wrapping ErrNotFound
+	ResolveRef(org string, name string, ref string) (string, error)
+
+	// GetFileLastCommit returns the last commit which changed the file on the ref, or nil if there is none.
+	// Bitbucket returns ErrNotSupported
+	GetFileLastCommit(org string, name string, path string, ref string) (*Commit, error)
+
+	// BranchesContainingCommit returns the names of the branches of the repository which contain the commit.
+	// Only GitLab can answer this without scanning every branch, so the other providers return ErrNotSupported
+	BranchesContainingCommit(org string, name string, sha string) ([]string, error)
+
+	// DeleteBranch deletes the branch from the remote repository. Deleting a branch which doesn't exist succeeds, and
+	// deleting the default branch of the repository gives an error wrapping ErrDefaultBranch
+	DeleteBranch(org string, name string, branch string) error
+
+	// CreateBranch creates the branch on the remote repository from the commit the ref resolves to. Creating a branch
+	// which already exists gives an error wrapping ErrAlreadyExists. Bitbucket and Gerrit return ErrNotSupported
+	CreateBranch(org string, name string, branch string, fromRef string) error
+
+	// CreateTag creates a tag on the remote repository pointing at the given commit
+	CreateTag(org string, name string, tag string, sha string, message string) error
+
+	// GetTag returns the tag and the commit it points at, or nil if there is no such tag
+	GetTag(org string, name string, tag string) (*GitTag, error)
+
+	// ListRepositoryTags returns all the tags of the repository with the commits they point at. GitHub lists tags
+	// without their message or date, so getting them would take a request per tag
+	ListRepositoryTags(org string, name string) ([]*GitTag, error)
+
+	// GetAheadBehind returns how many commits the branch of a fork is ahead and behind the same branch upstream
+	GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error)
+
+	// SyncFork updates the branch of a fork with the changes from the same branch upstream
+	SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error
+
 	// returns the path relative to the Jenkins URL to trigger webhooks on this kind of repository
 	//
 
@@ -130,6 +252,21 @@ type Provider interface {
 	// Returns user info, if possible
 	UserInfo(username string) *User
 
+	// GetUsers returns the users with the given usernames which can be found, keyed by username, or an error if a
+	// user can't be looked up. The users are cached so each one is only looked up once
+	GetUsers(usernames []string) (map[string]*User, error)
+
+	// GetCurrentUser returns the user the provider is authenticated as
+	GetCurrentUser() (*User, error)
+
+	// ListInvitations returns the pending invitations of the current user to collaborate on repositories. Only
+	// GitHub has invitations for users to accept, the other providers return ErrNotSupported
+	ListInvitations() ([]*Invitation, error)
+
+	// AcceptInvitation accepts the invitation of the current user with the given ID. Only GitHub has invitations for
+	// users to accept, the other providers return ErrNotSupported
+	AcceptInvitation(ID int64) error
+
 	AccessTokenURL() string
 }
 
@@ -154,12 +291,19 @@ type Gitter interface {
 	Init(dir string) error
 	Clone(url string, directory string) error
 	ShallowCloneBranch(url string, branch string, directory string) error
+	// ShallowCloneBranchWithDepth clones the branch with the given number of commits of history
+	ShallowCloneBranchWithDepth(url string, branch string, directory string, depth int) error
+	// CloneBare clones the repository without a working tree, with every branch and tag, e.g. to mirror it
+	CloneBare(url string, directory string) error
+	// Mirror pushes every ref of the repository to the origin remote, deleting the refs the repository doesn't have
+	Mirror(dir string) error
 	Push(dir string) error
 	PushMaster(dir string) error
 	PushTag(dir string, tag string) error
 	CreatePushURL(cloneURL, username, token string) (string, error)
 	ForcePushBranch(dir string, localBranch string, remoteBranch string) error
 	CloneOrPull(url string, directory string) error
+	EnsureClone(url string, baseDir string) (string, error)
 	Pull(dir string) error
 	PullRemoteBranches(dir string) error
 	PullUpstream(dir string) error
@@ -180,6 +324,7 @@ type Gitter interface {
 	CheckoutOrphan(dir string, branch string) error
 	ConvertToValidBranchName(name string) string
 	FetchBranch(dir string, repo string, refspec string) error
+	Merge(dir string, commitish string) error
 
 	Stash(dir string) error
 
@@ -195,6 +340,12 @@ type Gitter interface {
 	Diff(dir string) (string, error)
 
 	GetLatestCommitMessage(dir string) (string, error)
+	// GetCommitsBetween returns the commits reachable from toRef but not from fromRef, newest first, as git log
+	// fromRef..toRef lists them
+	GetCommitsBetween(dir string, fromRef string, toRef string) ([]Commit, error)
+	// ListChangedFilesInCommit returns the paths of the files the commit adds, modifies or deletes. A renamed file is
+	// listed under both its old and its new path
+	ListChangedFilesInCommit(dir string, sha string) ([]string, error)
 	GetPreviousGitTagSHA(dir string) (string, error)
 	GetCurrentGitTagSHA(dir string) (string, error)
 	FetchTags(dir string) error
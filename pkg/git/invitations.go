@@ -0,0 +1,19 @@
+package git
+
+import (
+	"time"
+)
+
+// Invitation is an invitation for the current user to collaborate on a repository
+type Invitation struct {
+	// ID identifies the invitation when accepting it
+	ID int64
+	// Repository is the repository the user is invited to, with its owner as the organisation
+	Repository *Repository
+	Inviter    *User
+	// Permissions are what the user may do once the invitation is accepted, such as read, write or admin
+	Permissions string
+	// URL is the page of the invitation in the web UI of the provider
+	URL       string
+	CreatedAt *time.Time
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"strings"
+)
+
+// IssueQuery is the subset of the GitHub issue search syntax which SearchIssues understands on every provider:
+//
+//	is:open or is:closed   the state of the issue
+//	label:name             issues with the label, can be repeated to require several labels
+//	author:login           issues opened by the user
+//
+// Any other words are matched against the key, title and body of the issues
+type IssueQuery struct {
+	State  string
+	Labels []string
+	Author string
+	Text   string
+}
+
+// ParseIssueQuery parses a search query written in the common syntax
+func ParseIssueQuery(query string) IssueQuery {
+	answer := IssueQuery{}
+	words := []string{}
+	for _, field := range strings.Fields(query) {
+		switch {
+		case field == "is:open" || field == "is:closed":
+			answer.State = strings.TrimPrefix(field, "is:")
+		case strings.HasPrefix(field, "label:") && len(field) > len("label:"):
+			answer.Labels = append(answer.Labels, strings.TrimPrefix(field, "label:"))
+		case strings.HasPrefix(field, "author:") && len(field) > len("author:"):
+			answer.Author = strings.TrimPrefix(field, "author:")
+		default:
+			words = append(words, field)
+		}
+	}
+	answer.Text = strings.Join(words, " ")
+	return answer
+}
+
+// Matches returns true if the issue matches the query. It is used by the providers which can't
+// filter issues on the server
+func (q IssueQuery) Matches(issue *Issue) bool {
+	if issue == nil {
+		return false
+	}
+	if q.State != "" {
+		closed := false
+		if issue.State != nil {
+			closed = strings.HasPrefix(strings.ToLower(*issue.State), "clos")
+		}
+		if closed != (q.State == "closed") {
+			return false
+		}
+	}
+	for _, label := range q.Labels {
+		if !issueHasLabel(issue, label) {
+			return false
+		}
+	}
+	if q.Author != "" && (issue.User == nil || !strings.EqualFold(issue.User.Login, q.Author)) {
+		return false
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(issue.Key), text) && !strings.Contains(strings.ToLower(issue.Title), text) &&
+			!strings.Contains(strings.ToLower(issue.Body), text) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterIssues returns the issues matching the query
+func (q IssueQuery) FilterIssues(issues []*Issue) []*Issue {
+	answer := []*Issue{}
+	for _, issue := range issues {
+		if q.Matches(issue) {
+			answer = append(answer, issue)
+		}
+	}
+	return answer
+}
+
+func issueHasLabel(issue *Issue, name string) bool {
+	for _, label := range issue.Labels {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueQuery(t *testing.T) {
+	t.Parallel()
+
+	query := ParseIssueQuery("is:closed label:bug label:area/ui author:jstrachan crash on start")
+	assert.Equal(t, "closed", query.State)
+	assert.Equal(t, []string{"bug", "area/ui"}, query.Labels)
+	assert.Equal(t, "jstrachan", query.Author)
+	assert.Equal(t, "crash on start", query.Text)
+
+	assert.Equal(t, IssueQuery{}, ParseIssueQuery(""))
+}
+
+func TestIssueQueryMatches(t *testing.T) {
+	t.Parallel()
+	open := "open"
+	closed := "closed"
+
+	issues := []*Issue{
+		{Title: "Crash on start", State: &closed, Labels: []Label{{Name: "bug"}}, User: &User{Login: "jstrachan"}},
+		{Title: "Add dark mode", State: &open, Labels: []Label{{Name: "enhancement"}}, User: &User{Login: "rawlingsj"}},
+		{Title: "Crash on exit", State: &open, Labels: []Label{{Name: "Bug"}}, User: &User{Login: "rawlingsj"}},
+		{Key: "JX-42", Title: "Slow builds", State: &open},
+	}
+
+	assert.Len(t, ParseIssueQuery("").FilterIssues(issues), 4)
+	assert.Len(t, ParseIssueQuery("is:open").FilterIssues(issues), 3)
+	assert.Len(t, ParseIssueQuery("label:bug").FilterIssues(issues), 2)
+	assert.Len(t, ParseIssueQuery("is:open label:bug").FilterIssues(issues), 1)
+	assert.Len(t, ParseIssueQuery("author:rawlingsj").FilterIssues(issues), 2)
+	assert.Len(t, ParseIssueQuery("crash").FilterIssues(issues), 2)
+	assert.Len(t, ParseIssueQuery("jx-42").FilterIssues(issues), 1)
+	assert.Empty(t, ParseIssueQuery("label:bug author:nobody").FilterIssues(issues))
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// closedIssueRefs matches a closing keyword followed by one or more issue references such as
+// "Fixes #12" or "Closes #3, #4 and #5", which GitHub, GitLab, Gitea and Bitbucket Cloud all understand
+var closedIssueRefs = regexp.MustCompile(`(?i)\b(?:close[sd]?|closing|fix(?:e[sd])?|fixing|resolve[sd]?|resolving)\s*:?\s+(#\d+(?:\s*(?:,|\band\b)\s*#\d+)*)`)
+
+var issueRef = regexp.MustCompile(`#(\d+)`)
+
+// CloseKeyword returns the phrase which closes the issue when a pull request with it in the body is merged,
+// or blank if the provider doesn't close issues from pull requests. The issues of Bitbucket Server live in
+// Jira and are referenced by key, and Gerrit has no issues
+func CloseKeyword(provider Provider, issueNumber int) string {
+	if provider != nil {
+		switch provider.Kind() {
+		case KindBitBucketServer, KindGerrit:
+			return ""
+		}
+	}
+	return fmt.Sprintf("Closes #%d", issueNumber)
+}
+
+// ParseClosedIssueRefs returns the numbers of the issues the body closes with a closing keyword, in the
+// order they are first referenced
+func ParseClosedIssueRefs(body string) []int {
+	answer := []int{}
+	found := map[int]bool{}
+	for _, match := range closedIssueRefs.FindAllStringSubmatch(body, -1) {
+		for _, ref := range issueRef.FindAllStringSubmatch(match[1], -1) {
+			n, err := strconv.Atoi(ref[1])
+			if err != nil || found[n] {
+				continue
+			}
+			found[n] = true
+			answer = append(answer, n)
+		}
+	}
+	return answer
+}
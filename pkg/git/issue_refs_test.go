@@ -0,0 +1,37 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseKeyword(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Closes #7", CloseKeyword(&FakeProvider{Type: GitHub}, 7))
+	assert.Equal(t, "Closes #7", CloseKeyword(&FakeProvider{Type: Gitlab}, 7))
+	assert.Equal(t, "Closes #7", CloseKeyword(&FakeProvider{Type: BitbucketCloud}, 7))
+	assert.Equal(t, "", CloseKeyword(&FakeProvider{Type: BitbucketServer}, 7))
+	assert.Equal(t, "Closes #7", CloseKeyword(nil, 7))
+}
+
+func TestParseClosedIssueRefs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		body     string
+		expected []int
+	}{
+		{"", []int{}},
+		{"Fixes #12", []int{12}},
+		{"this closes #3, #4 and #5 at last", []int{3, 4, 5}},
+		{"Resolves: #8\n\nAlso fixed #9 and resolving #8", []int{8, 9}},
+		{"See #10 which is related", []int{}},
+		{"prefixes #11 shouldn't count", []int{}},
+		{CloseKeyword(nil, 42), []int{42}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, ParseClosedIssueRefs(tc.body), tc.body)
+	}
+}
@@ -0,0 +1,33 @@
+package git
+
+const (
+	// MergeMethodMerge merges a pull request with a merge commit
+	MergeMethodMerge = "merge"
+	// MergeMethodSquash squashes the commits of a pull request into one commit
+	MergeMethodSquash = "squash"
+	// MergeMethodRebase rebases the commits of a pull request onto the base branch
+	MergeMethodRebase = "rebase"
+)
+
+// MergeConfig is how the pull requests of a repository can be merged
+type MergeConfig struct {
+	AllowMergeCommit bool
+	AllowSquash      bool
+	AllowRebase      bool
+
+	// DefaultMethod is the merge method used unless another one is asked for, or blank if the provider has no default
+	DefaultMethod string
+}
+
+// Allows returns true if pull requests can be merged with the given merge method
+func (c *MergeConfig) Allows(method string) bool {
+	switch method {
+	case MergeMethodMerge:
+		return c.AllowMergeCommit
+	case MergeMethodSquash:
+		return c.AllowSquash
+	case MergeMethodRebase:
+		return c.AllowRebase
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeConfigAllows(t *testing.T) {
+	t.Parallel()
+
+	squashOnly := &MergeConfig{AllowSquash: true, DefaultMethod: MergeMethodSquash}
+	assert.True(t, squashOnly.Allows(MergeMethodSquash))
+	assert.False(t, squashOnly.Allows(MergeMethodMerge))
+	assert.False(t, squashOnly.Allows(MergeMethodRebase))
+	assert.False(t, squashOnly.Allows("fast-forward"))
+}
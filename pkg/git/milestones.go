@@ -0,0 +1,26 @@
+package git
+
+import (
+	"time"
+)
+
+const (
+	// MilestoneStateOpen is the state of milestones which issues can still be planned for
+	MilestoneStateOpen = "open"
+	// MilestoneStateClosed is the state of milestones which are done
+	MilestoneStateClosed = "closed"
+)
+
+// Milestone is a milestone of a repository which issues and pull requests are planned for
+type Milestone struct {
+	// ID identifies the milestone in the API of the provider, which is the number of the milestone on GitHub
+	ID    int64
+	Title string
+	// State is MilestoneStateOpen or MilestoneStateClosed
+	State string
+	// DueOn is when the milestone is due, or nil if it has no due date
+	DueOn *time.Time
+	// OpenIssues and ClosedIssues count the issues of the milestone, on the providers which report them
+	OpenIssues   int
+	ClosedIssues int
+}
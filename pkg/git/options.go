@@ -0,0 +1,188 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout is the timeout of a single request to the git provider unless WithRequestTimeout is used
+const DefaultRequestTimeout = 30 * time.Second
+
+// RepoVisibility is the visibility of a repository
+type RepoVisibility string
+
+const (
+	// RepoVisibilityPublic repositories can be seen by anyone
+	RepoVisibilityPublic RepoVisibility = "public"
+	// RepoVisibilityInternal repositories can be seen by any signed in user, where the provider supports it
+	RepoVisibilityInternal RepoVisibility = "internal"
+	// RepoVisibilityPrivate repositories can only be seen by their members
+	RepoVisibilityPrivate RepoVisibility = "private"
+)
+
+// ProviderOptions are the settings shared by the git provider constructors
+type ProviderOptions struct {
+	// ForcePrivate creates every repository as private, whatever is passed to CreateRepository
+	ForcePrivate bool
+
+	// DefaultRepoVisibility is the visibility of repositories created without asking for a private one
+	DefaultRepoVisibility RepoVisibility
+
+	// RequestTimeout bounds each HTTP request made to the provider so a stalled connection can't hang the caller.
+	// It does not apply to the loops polling for forks and pull requests, which have their own timeout
+	RequestTimeout time.Duration
+
+	// AppendGitSuffix adds .git to the HTTP clone URLs the provider builds, which some servers reject
+	AppendGitSuffix bool
+
+	// Context cancels the requests made to the provider and the loops polling it once it is done. Nothing is
+	// cancelled if it is nil
+	Context context.Context
+
+	// Retry is how requests failing with a transient error are retried
+	Retry RetryConfig
+
+	// TokenUsername is the username git authenticates with over HTTPS when there is a token but no username. The
+	// TokenUsername of the kind of the provider is used if it is empty
+	TokenUsername string
+}
+
+// ProviderOption configures a git provider when it is created
+type ProviderOption func(*ProviderOptions)
+
+// NewProviderOptions returns the default options with the given options applied
+func NewProviderOptions(options ...ProviderOption) ProviderOptions {
+	answer := ProviderOptions{
+		DefaultRepoVisibility: RepoVisibilityPublic,
+		RequestTimeout:        DefaultRequestTimeout,
+		AppendGitSuffix:       true,
+		Retry:                 DefaultRetryConfig,
+	}
+	for _, option := range options {
+		option(&answer)
+	}
+	return answer
+}
+
+// WithForcePrivate makes the provider create every repository as private
+func WithForcePrivate() ProviderOption {
+	return func(o *ProviderOptions) {
+		o.ForcePrivate = true
+	}
+}
+
+// WithDefaultRepoVisibility sets the visibility of repositories created without asking for a private one
+func WithDefaultRepoVisibility(visibility RepoVisibility) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.DefaultRepoVisibility = visibility
+	}
+}
+
+// WithRequestTimeout sets the timeout of each HTTP request made to the provider. A zero duration disables the timeout
+func WithRequestTimeout(timeout time.Duration) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.RequestTimeout = timeout
+	}
+}
+
+// WithAppendGitSuffix sets whether .git is added to the HTTP clone URLs the provider builds
+func WithAppendGitSuffix(appendGitSuffix bool) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.AppendGitSuffix = appendGitSuffix
+	}
+}
+
+// WithContext makes the provider cancel its requests and stop polling once the context is done
+func WithContext(ctx context.Context) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.Context = ctx
+	}
+}
+
+// WithRetryConfig sets how requests failing with a transient error are retried
+func WithRetryConfig(config RetryConfig) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.Retry = config
+	}
+}
+
+// WithTokenUsername sets the username git authenticates with when there is a token but no username, e.g. for
+// servers which don't accept the default of their kind
+func WithTokenUsername(username string) ProviderOption {
+	return func(o *ProviderOptions) {
+		o.TokenUsername = username
+	}
+}
+
+// NewHTTPClient returns an HTTP client using the request timeout, which retries requests failing with a transient
+// error and makes every request with the context if there is one. That way the requests of clients which don't take
+// contexts can be cancelled too
+func (o ProviderOptions) NewHTTPClient() *http.Client {
+	client := &http.Client{
+		Timeout: o.RequestTimeout,
+	}
+	if o.Retry.MaxRetries > 0 {
+		client.Transport = NewRetryableRoundTripper(http.DefaultTransport, o.Retry)
+	}
+	if o.Context != nil {
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		client.Transport = &contextTransport{
+			ctx:       o.Context,
+			transport: transport,
+		}
+	}
+	return client
+}
+
+// RequestContext returns the context to make requests with, which is the background unless WithContext is used
+func (o ProviderOptions) RequestContext() context.Context {
+	if o.Context != nil {
+		return o.Context
+	}
+	return context.Background()
+}
+
+// Sleep waits for the duration between polls of the provider. It returns the error of the context if it is done
+// first
+func (o ProviderOptions) Sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-o.RequestContext().Done():
+		return o.Context.Err()
+	}
+}
+
+// contextTransport makes every request with its context
+type contextTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req.WithContext(t.ctx))
+}
+
+// Visibility returns the visibility to create a repository with. ForcePrivate takes precedence
+// over the private argument, which takes precedence over DefaultRepoVisibility
+func (o ProviderOptions) Visibility(private bool) RepoVisibility {
+	if o.ForcePrivate || private {
+		return RepoVisibilityPrivate
+	}
+	if o.DefaultRepoVisibility == "" {
+		return RepoVisibilityPublic
+	}
+	return o.DefaultRepoVisibility
+}
+
+// IsPrivate returns true if a repository should be created as private. Providers without
+// internal repositories treat them as private
+func (o ProviderOptions) IsPrivate(private bool) bool {
+	return o.Visibility(private) != RepoVisibilityPublic
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderOptionsVisibility(t *testing.T) {
+	t.Parallel()
+
+	options := NewProviderOptions()
+	assert.Equal(t, RepoVisibilityPublic, options.Visibility(false))
+	assert.Equal(t, RepoVisibilityPrivate, options.Visibility(true))
+	assert.False(t, options.IsPrivate(false))
+
+	// the zero value behaves like the defaults
+	assert.Equal(t, RepoVisibilityPublic, ProviderOptions{}.Visibility(false))
+
+	options = NewProviderOptions(WithDefaultRepoVisibility(RepoVisibilityInternal))
+	assert.Equal(t, RepoVisibilityInternal, options.Visibility(false))
+	assert.Equal(t, RepoVisibilityPrivate, options.Visibility(true))
+	assert.True(t, options.IsPrivate(false))
+
+	// forcing private overrides both the argument and the default visibility
+	options = NewProviderOptions(WithForcePrivate(), WithDefaultRepoVisibility(RepoVisibilityPublic))
+	assert.Equal(t, RepoVisibilityPrivate, options.Visibility(false))
+	assert.True(t, options.IsPrivate(false))
+}
+
+func TestProviderOptionsRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	options := NewProviderOptions()
+	assert.Equal(t, DefaultRequestTimeout, options.RequestTimeout)
+	assert.Equal(t, DefaultRequestTimeout, options.NewHTTPClient().Timeout)
+
+	options = NewProviderOptions(WithRequestTimeout(5 * time.Second))
+	assert.Equal(t, 5*time.Second, options.NewHTTPClient().Timeout)
+}
+
+func TestProviderOptionsAppendGitSuffix(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, NewProviderOptions().AppendGitSuffix)
+	assert.False(t, NewProviderOptions(WithAppendGitSuffix(false)).AppendGitSuffix)
+}
+
+func TestProviderOptionsContext(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	options := NewProviderOptions()
+	assert.Equal(t, context.Background(), options.RequestContext())
+	assert.NoError(t, options.Sleep(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	options = NewProviderOptions(WithContext(ctx))
+	client := options.NewHTTPClient()
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	cancel()
+	_, err = client.Get(server.URL)
+	assert.Error(t, err)
+
+	start := time.Now()
+	assert.Equal(t, context.Canceled, options.Sleep(time.Minute))
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -8,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	survey "gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
@@ -17,6 +19,14 @@ type Organisation struct {
 	Login string
 }
 
+// Team is a team of users in an organisation
+type Team struct {
+	Name string
+	Slug string
+	// Permission is the default permission of the team on the repositories of the organisation, if the provider has one
+	Permission string
+}
+
 type Repository struct {
 	Name             string
 	AllowMergeCommit bool
@@ -26,11 +36,25 @@ type Repository struct {
 	Language         string
 	Fork             bool
 	Stars            int
-	URL              string
-	Scheme           string
-	Host             string
-	Organisation     string
-	Project          string
+	// Topics are the topics the repository is tagged with, on the providers which report them
+	Topics       []string
+	URL          string
+	Scheme       string
+	Host         string
+	Organisation string
+	Project      string
+	Description  string
+	// DefaultBranch is the branch checked out by clones, or empty if the repository has no branches yet. Bitbucket
+	// Server only reports it by GetRepository
+	DefaultBranch    string
+	Private          bool
+	HasIssuesEnabled bool
+	// Size is the size of the repository in kilobytes, or 0 if the provider doesn't report it
+	Size int
+	// PushedAt is when a commit was last pushed and UpdatedAt is when the repository last changed, or nil if
+	// the provider doesn't report them
+	PushedAt  *time.Time
+	UpdatedAt *time.Time
 }
 
 type PullRequest struct {
@@ -42,6 +66,7 @@ type PullRequest struct {
 	Mergeable      *bool
 	Merged         *bool
 	HeadRef        *string
+	BaseRef        *string
 	State          *string
 	StatusesURL    *string
 	IssueURL       *string
@@ -52,6 +77,18 @@ type PullRequest struct {
 	LastCommitSha  string
 	Title          string
 	Body           string
+	Labels         []Label
+}
+
+// PullRequestEvent is an entry in the activity of a pull request such as a comment, review, push or merge.
+// Action is the lower case name the provider gives the event, e.g. "commented", "approved" or "merged"
+type PullRequestEvent struct {
+	ID        string
+	Action    string
+	Actor     *User
+	CreatedAt *time.Time
+	Body      string
+	CommitSHA string
 }
 
 type Commit struct {
@@ -61,6 +98,8 @@ type Commit struct {
 	URL       string
 	Branch    string
 	Committer *User
+	// CommittedAt is when the commit was made, on the providers which report it
+	CommittedAt *time.Time
 }
 
 type Issue struct {
@@ -129,6 +168,9 @@ type RepoStatus struct {
 
 	// Description is a short high level summary of the status.
 	Description string
+
+	// CreatedAt is when the status was reported, if the provider returns it
+	CreatedAt *time.Time
 }
 
 type PullRequestArguments struct {
@@ -137,6 +179,13 @@ type PullRequestArguments struct {
 	Head       string
 	Base       string
 	Repository *Repository
+	// Draft creates the pull request as a draft which can't be merged yet, on the providers which have drafts
+	Draft bool
+	// Assignees and Reviewers are the logins of the users to assign the pull request to and to request reviews of,
+	// on the providers which support them. Failing to request the reviews only logs a warning, while failing to
+	// assign the pull request returns the created pull request together with the error
+	Assignees []string
+	Reviewers []string
 }
 
 type WebhookArguments struct {
@@ -145,14 +194,33 @@ type WebhookArguments struct {
 	Repo   *Repository
 	URL    string
 	Secret string
+	// Name of the webhook, for providers which name their webhooks
+	Name string
+	// Events are the provider specific events to subscribe to, an empty list subscribes to the default events.
+	// Only used by Bitbucket Server and GitLab at the moment
+	Events []string
+}
+
+// WebHookDelivery is an attempt by the provider to deliver an event to a webhook
+type WebHookDelivery struct {
+	ID          int64
+	GUID        string
+	Event       string
+	Action      string
+	Status      string
+	StatusCode  int
+	Duration    time.Duration
+	DeliveredAt *time.Time
+	Redelivery  bool
 }
 
 type FileContent struct {
-	Type        string
-	Encoding    string
-	Size        int
-	Name        string
-	Path        string
+	Type     string
+	Encoding string
+	Size     int
+	Name     string
+	Path     string
+	// Content is the decoded content of the file, Encoding is how the provider sent it
 	Content     string
 	Sha         string
 	Url         string
@@ -182,6 +250,16 @@ func (pr *PullRequest) NumberString() string {
 	return "#" + strconv.Itoa(*n)
 }
 
+// HasLabel returns true if the pull request has a label with the given name
+func (pr *PullRequest) HasLabel(name string) bool {
+	for _, label := range pr.Labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // GetHost returns the Git Provider hostname, e.g github.com
 func GetHost(gitProvider Provider) (string, error) {
 	if gitProvider == nil {
@@ -294,12 +372,71 @@ func IsRepoStatusFailed(statuses ...*RepoStatus) bool {
 	return false
 }
 
+// MaxStatusContextLength is the longest status context GitHub, GitLab and Bitbucket Server accept
+const MaxStatusContextLength = 255
+
+// NormalizeContext trims the context of the status and checks it is set and no longer than maxLength. A status
+// replaces the status of the commit with the same context, so each pipeline should report under a context of its
+// own, e.g. "ci/<pipeline>/<step>"
+func (s *RepoStatus) NormalizeContext(maxLength int) error {
+	s.Context = strings.TrimSpace(s.Context)
+	if s.Context == "" {
+		return errors.New("the context of a commit status must be set")
+	}
+	if length := utf8.RuneCountInString(s.Context); length > maxLength {
+		return fmt.Errorf("the context %s of a commit status is %d characters long but can be at most %d", s.Context, length, maxLength)
+	}
+	return nil
+}
+
+// FindRepoStatus returns the latest status matching the given context
+func FindRepoStatus(statuses []*RepoStatus, context string) *RepoStatus {
+	return RollupStatuses(statuses)[context]
+}
+
+// RollupStatuses returns the newest status for each context. Statuses without a
+// timestamp are assumed to be listed newest first, as the provider APIs return them.
+func RollupStatuses(statuses []*RepoStatus) map[string]*RepoStatus {
+	answer := map[string]*RepoStatus{}
+	for _, status := range statuses {
+		if status == nil {
+			continue
+		}
+		latest, ok := answer[status.Context]
+		if !ok || (latest.CreatedAt != nil && status.CreatedAt != nil && status.CreatedAt.After(*latest.CreatedAt)) {
+			answer[status.Context] = status
+		}
+	}
+	return answer
+}
+
+// OverallState returns the combined state of the latest status for each context:
+// failure if any have failed, success if all have succeeded and pending otherwise,
+// including when there are no statuses at all. Every provider's PullRequestLastCommitStatus
+// returns it, so a commit without statuses is pending on all of them
+func OverallState(statuses []*RepoStatus) string {
+	latest := RollupStatuses(statuses)
+	if len(latest) == 0 {
+		return "pending"
+	}
+	answer := "success"
+	for _, status := range latest {
+		if status.IsFailed() {
+			return "failure"
+		}
+		if !status.IsSuccess() {
+			answer = "pending"
+		}
+	}
+	return answer
+}
+
 func (s *RepoStatus) IsSuccess() bool {
 	return s.State == "success"
 }
 
 func (s *RepoStatus) IsFailed() bool {
-	return s.State == "error" || s.State == "failure"
+	return s.State == "error" || s.State == "failure" || s.State == "failed"
 }
 
 // ToLabels converts the list of label names into an array of Labels
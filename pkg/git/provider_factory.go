@@ -0,0 +1,73 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// CredentialsUsernameKey is the key of the username in the credentials passed to NewProviderFromCredentials
+	CredentialsUsernameKey = "username"
+	// CredentialsTokenKey is the key of the API token in the credentials passed to NewProviderFromCredentials
+	CredentialsTokenKey = "token"
+	// CredentialsBearerTokenKey is the key of the bearer token used when there is no API token in the credentials
+	CredentialsBearerTokenKey = "bearerToken"
+)
+
+// ProviderFactory creates a git provider for a server
+type ProviderFactory func(username, serverURL, token string, git Gitter, options ...ProviderOption) (Provider, error)
+
+var (
+	providerFactoriesLock sync.RWMutex
+	providerFactories     = map[string]ProviderFactory{}
+)
+
+// RegisterProviderFactory registers the factory creating git providers of the given kind. The provider
+// packages register themselves when they are imported, as this package can't import them
+func RegisterProviderFactory(kind string, factory ProviderFactory) {
+	providerFactoriesLock.Lock()
+	defer providerFactoriesLock.Unlock()
+	providerFactories[kind] = factory
+}
+
+// ProviderFactoryKinds returns the sorted kinds of git provider that have a factory registered
+func ProviderFactoryKinds() []string {
+	providerFactoriesLock.RLock()
+	defer providerFactoriesLock.RUnlock()
+	answer := []string{}
+	for kind := range providerFactories {
+		answer = append(answer, kind)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// NewProviderFromCredentials creates a git provider of the given kind from credentials such as the data of a
+// mounted secret, reading the username, token and bearerToken keys. The bearer token is only used if there
+// is no token. The username may be left out with a token, as the provider then pushes with the TokenUsername
+// of its kind or options
+func NewProviderFromCredentials(kind, serverURL string, creds map[string]string, git Gitter, options ...ProviderOption) (Provider, error) {
+	username := creds[CredentialsUsernameKey]
+	token := creds[CredentialsTokenKey]
+	if token == "" {
+		token = creds[CredentialsBearerTokenKey]
+	}
+	if token == "" {
+		return nil, fmt.Errorf("missing the %s or %s key in the credentials for the git server %s", CredentialsTokenKey, CredentialsBearerTokenKey, serverURL)
+	}
+
+	providerFactoriesLock.RLock()
+	factory, ok := providerFactories[kind]
+	providerFactoriesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no git provider registered for kind %s, the registered kinds are %v", kind, ProviderFactoryKinds())
+	}
+	return factory(username, serverURL, token, git, options...)
+}
+
+func init() {
+	RegisterProviderFactory(KindGitFake, func(username, serverURL, token string, git Gitter, options ...ProviderOption) (Provider, error) {
+		return NewFakeGitProvider(username, KindGitFake, git)
+	})
+}
@@ -0,0 +1,74 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wbrefvem/go-gits/pkg/bitbucketcloud"
+	"github.com/wbrefvem/go-gits/pkg/bitbucketserver"
+	"github.com/wbrefvem/go-gits/pkg/gerrit"
+	"github.com/wbrefvem/go-gits/pkg/git"
+	"github.com/wbrefvem/go-gits/pkg/gitea"
+	"github.com/wbrefvem/go-gits/pkg/github"
+	"github.com/wbrefvem/go-gits/pkg/gitlab"
+)
+
+func TestNewProviderFromCredentials(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, git.ProviderFactoryKinds(), git.KindGitFake)
+
+	provider, err := git.NewProviderFromCredentials(git.KindGitFake, git.FakeGitURL, map[string]string{
+		git.CredentialsUsernameKey:    "test-user",
+		git.CredentialsBearerTokenKey: "secret",
+	}, git.NewGitFake())
+	assert.NoError(t, err)
+	assert.Equal(t, git.KindGitFake, provider.Kind())
+	fakeProvider, ok := provider.(*git.GitFakeProvider)
+	assert.True(t, ok)
+	assert.Equal(t, "test-user", fakeProvider.Username)
+
+	provider, err = git.NewProviderFromCredentials(git.KindGitFake, git.FakeGitURL, map[string]string{git.CredentialsTokenKey: "secret"}, git.NewGitFake())
+	assert.NoError(t, err)
+	assert.Equal(t, "", provider.CurrentUsername())
+
+	_, err = git.NewProviderFromCredentials(git.KindGitFake, git.FakeGitURL, map[string]string{git.CredentialsUsernameKey: "test-user"}, git.NewGitFake())
+	assert.EqualError(t, err, "missing the token or bearerToken key in the credentials for the git server https://fake.git")
+
+	_, err = git.NewProviderFromCredentials(git.KindUnknown, git.FakeGitURL, map[string]string{
+		git.CredentialsUsernameKey: "test-user",
+		git.CredentialsTokenKey:    "secret",
+	}, git.NewGitFake())
+	assert.Error(t, err)
+}
+
+func TestNewProviderFromCredentialsKinds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind      string
+		serverURL string
+		provider  git.Provider
+	}{
+		{git.KindGitHub, "https://github.com", &github.GitHubProvider{}},
+		{git.KindGitlab, "https://gitlab.com", &gitlab.GitlabProvider{}},
+		{git.KindGitea, "https://gitea.example.com", &gitea.GiteaProvider{}},
+		{git.KindBitBucketCloud, "https://bitbucket.org", &bitbucketcloud.CloudProvider{}},
+		{git.KindBitBucketServer, "https://bitbucket.example.com", &bitbucketserver.ServerProvider{}},
+		{git.KindGerrit, "https://gerrit.example.com", &gerrit.GerritProvider{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			assert.Contains(t, git.ProviderFactoryKinds(), tt.kind)
+
+			provider, err := git.NewProviderFromCredentials(tt.kind, tt.serverURL, map[string]string{
+				git.CredentialsUsernameKey: "test-user",
+				git.CredentialsTokenKey:    "secret",
+			}, git.NewGitFake())
+
+			assert.NoError(t, err)
+			assert.IsType(t, tt.provider, provider)
+			assert.Equal(t, tt.kind, provider.Kind())
+		})
+	}
+}
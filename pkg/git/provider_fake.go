@@ -3,11 +3,11 @@ package git
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 )
@@ -49,9 +49,11 @@ type FakeCommit struct {
 }
 
 type FakePullRequest struct {
-	PullRequest *PullRequest
-	Commits     []*FakeCommit
-	Comment     string
+	PullRequest   *PullRequest
+	Commits       []*FakeCommit
+	Comment       string
+	DiffStat      *DiffStat
+	ApprovalState *ApprovalState
 }
 
 type FakeIssue struct {
@@ -67,11 +69,24 @@ type FakeRepository struct {
 	Commits            []*FakeCommit
 	issueCount         int
 	Releases           map[string]*Release
+	Tags               map[string]*GitTag
 	PullRequestCounter int
+	MergeConfig        MergeConfig
+	Milestones         []*Milestone
+	Contributors       []*Contributor
+	// Statuses are the statuses reported with UpdateCommitStatus, keyed by SHA
+	Statuses map[string][]*RepoStatus
+	// Files are the contents of the files returned by GetContent, keyed by path. The fake doesn't track refs
+	Files map[string]string
+	// DeletedBranches are the branches deleted with DeleteBranch, in order
+	DeletedBranches []string
+	// Branches are the SHAs of the branches created with CreateBranch, keyed by name
+	Branches map[string]string
 }
 
 type FakeProvider struct {
 	Organizations      []Organisation
+	Teams              map[string][]*Team
 	Repositories       map[string][]*FakeRepository
 	ForkedRepositories map[string][]*FakeRepository
 	Type               FakeProviderType
@@ -91,6 +106,10 @@ func (f *FakeProvider) ListOrganisations() ([]Organisation, error) {
 	return f.Organizations, nil
 }
 
+func (f *FakeProvider) ListTeams(org string) ([]*Team, error) {
+	return f.Teams[org], nil
+}
+
 func (f *FakeProvider) ListRepositories(org string) ([]*Repository, error) {
 	repos, ok := f.Repositories[org]
 	if !ok {
@@ -103,6 +122,47 @@ func (f *FakeProvider) ListRepositories(org string) ([]*Repository, error) {
 	return gitRepos, nil
 }
 
+// ListRepositoriesPaged pages through the repositories of the organisation with the offset of the page as
+// its cursor
+func (f *FakeProvider) ListRepositoriesPaged(org string, options ListOptions) (*RepositoryPage, error) {
+	repos, err := f.ListRepositories(org)
+	if err != nil {
+		return nil, err
+	}
+	perPage := options.PerPage
+	if perPage <= 0 {
+		perPage = 30
+	}
+	start := 0
+	if options.Cursor != "" {
+		start, err = strconv.Atoi(options.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor '%s'", options.Cursor)
+		}
+	} else if options.Page > 1 {
+		start = (options.Page - 1) * perPage
+	}
+	if start > len(repos) {
+		start = len(repos)
+	}
+	end := start + perPage
+	if end > len(repos) {
+		end = len(repos)
+	}
+	page := &RepositoryPage{
+		Repositories: repos[start:end],
+		HasMore:      end < len(repos),
+	}
+	if page.HasMore {
+		page.NextCursor = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (f *FakeProvider) ListRepositoriesForCurrentUser() ([]*Repository, error) {
+	return f.ListRepositories(f.Username)
+}
+
 func (f *FakeProvider) CreateRepository(org string, name string, private bool) (*Repository, error) {
 	gitRepo := &Repository{
 		Name: name,
@@ -119,14 +179,14 @@ func (f *FakeProvider) CreateRepository(org string, name string, private bool) (
 func (f *FakeProvider) GetRepository(org string, name string) (*Repository, error) {
 	repos, ok := f.Repositories[org]
 	if !ok {
-		return nil, fmt.Errorf("organization '%s' not found", org)
+		return nil, fmt.Errorf("organization '%s': %w", org, ErrNotFound)
 	}
 	for _, repo := range repos {
 		if repo.GitRepo.Name == name {
 			return repo.GitRepo, nil
 		}
 	}
-	return nil, fmt.Errorf("repository '%s' not found within the organization '%s'", name, org)
+	return nil, fmt.Errorf("repository '%s' within the organization '%s': %w", name, org, ErrNotFound)
 }
 
 func (f *FakeProvider) DeleteRepository(org string, name string) error {
@@ -168,6 +228,34 @@ func (f *FakeProvider) ValidateRepositoryName(org string, name string) error {
 	return fmt.Errorf("repository '%s' not found within the organization '%s'", name, org)
 }
 
+func (f *FakeProvider) GetMergeConfig(org string, name string) (*MergeConfig, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	config := repo.MergeConfig
+	return &config, nil
+}
+
+func (f *FakeProvider) SetMergeConfig(org string, name string, config *MergeConfig) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	repo.MergeConfig = *config
+	return nil
+}
+
+// SetRepositoryTopics replaces the topics of the repository
+func (f *FakeProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	repo.GitRepo.Topics = append([]string{}, topics...)
+	return nil
+}
+
 func (f *FakeProvider) CreatePullRequest(data *PullRequestArguments) (*PullRequest, error) {
 	org := data.Repository.Organisation
 	repoName := data.Repository.Name
@@ -188,6 +276,8 @@ func (f *FakeProvider) CreatePullRequest(data *PullRequestArguments) (*PullReque
 
 	repo.issueCount += 1
 	number := repo.issueCount
+	base := data.Base
+	head := data.Head
 	pr := &PullRequest{
 		URL: "",
 		Author: &User{
@@ -202,7 +292,8 @@ func (f *FakeProvider) CreatePullRequest(data *PullRequestArguments) (*PullReque
 		Number:         &number,
 		Mergeable:      nil,
 		Merged:         nil,
-		HeadRef:        nil,
+		HeadRef:        &head,
+		BaseRef:        &base,
 		State:          nil,
 		StatusesURL:    nil,
 		IssueURL:       nil,
@@ -293,6 +384,66 @@ func (f *FakeProvider) GetPullRequestCommits(owner string, repo *Repository, num
 	return nil, fmt.Errorf("repository with name '%s' not found", repoName)
 }
 
+// ListPullRequests returns the pull requests of the repository in the given state, ordered by number.
+// Pull requests without a state are open
+func (f *FakeProvider) ListPullRequests(owner string, repo *Repository, state string) ([]*PullRequest, error) {
+	r, err := f.findRepository(owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+	numbers := []int{}
+	for number := range r.PullRequests {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	answer := []*PullRequest{}
+	for _, number := range numbers {
+		pr := r.PullRequests[number].PullRequest
+		open := (pr.State == nil || *pr.State == PullRequestStateOpen) && (pr.Merged == nil || !*pr.Merged)
+		if state == PullRequestStateAll || (state == PullRequestStateOpen) == open {
+			answer = append(answer, pr)
+		}
+	}
+	return answer, nil
+}
+
+// ListPullRequestsByAuthor returns the pull requests of the repository in the given state which were opened by
+// the author, ordered by number
+func (f *FakeProvider) ListPullRequestsByAuthor(owner string, repo *Repository, author string, state string) ([]*PullRequest, error) {
+	prs, err := f.ListPullRequests(owner, repo, state)
+	if err != nil {
+		return nil, err
+	}
+	return FilterPullRequestsByAuthor(prs, author), nil
+}
+
+// GetPullRequestByBranch returns the open pull request from the branch, or nil if there is none
+func (f *FakeProvider) GetPullRequestByBranch(owner string, repo *Repository, branch string) (*PullRequest, error) {
+	prs, err := f.ListPullRequests(owner, repo, PullRequestStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	return FindPullRequestByBranch(prs, branch), nil
+}
+
+func (f *FakeProvider) ClosePullRequest(pr *PullRequest) error {
+	r, err := f.findRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	number := *pr.Number
+	fakePR, ok := r.PullRequests[number]
+	if !ok {
+		return fmt.Errorf("pull request with id '%d' not found", number)
+	}
+	state := PullRequestStateClosed
+	now := time.Now()
+	fakePR.PullRequest.State = &state
+	fakePR.PullRequest.ClosedAt = &now
+	return nil
+}
+
 func (f *FakeProvider) PullRequestLastCommitStatus(pr *PullRequest) (string, error) {
 	owner := pr.Owner
 	repos, ok := f.Repositories[owner]
@@ -318,6 +469,72 @@ func (f *FakeProvider) PullRequestLastCommitStatus(pr *PullRequest) (string, err
 	return "", fmt.Errorf("repository with name '%s' not found", repoName)
 }
 
+func (f *FakeProvider) ListPullRequestActivity(pr *PullRequest) ([]*PullRequestEvent, error) {
+	repo, err := f.findRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	number := *pr.Number
+	fakePR, ok := repo.PullRequests[number]
+	if !ok {
+		return nil, fmt.Errorf("pull request with id '%d' not found", number)
+	}
+	events := []*PullRequestEvent{
+		{Action: "opened", Actor: fakePR.PullRequest.Author},
+	}
+	for _, commit := range fakePR.Commits {
+		events = append(events, &PullRequestEvent{
+			Action:    "committed",
+			Actor:     commit.Commit.Author,
+			CommitSHA: commit.Commit.SHA,
+		})
+	}
+	if fakePR.Comment != "" {
+		events = append(events, &PullRequestEvent{Action: "commented", Body: fakePR.Comment})
+	}
+	if fakePR.PullRequest.Merged != nil && *fakePR.PullRequest.Merged {
+		events = append(events, &PullRequestEvent{Action: "merged"})
+	}
+	for i, event := range events {
+		event.ID = strconv.Itoa(i + 1)
+	}
+	return events, nil
+}
+
+// GetPullRequestStats returns the DiffStat of the fake pull request, which is empty unless a test set it
+func (f *FakeProvider) GetPullRequestStats(pr *PullRequest) (*DiffStat, error) {
+	repo, err := f.findRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	number := *pr.Number
+	fakePR, ok := repo.PullRequests[number]
+	if !ok {
+		return nil, fmt.Errorf("pull request with id '%d' not found", number)
+	}
+	if fakePR.DiffStat == nil {
+		return &DiffStat{}, nil
+	}
+	return fakePR.DiffStat, nil
+}
+
+// GetPullRequestApprovalState returns the ApprovalState of the fake pull request, which is empty unless a test set it
+func (f *FakeProvider) GetPullRequestApprovalState(pr *PullRequest) (*ApprovalState, error) {
+	repo, err := f.findRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	number := *pr.Number
+	fakePR, ok := repo.PullRequests[number]
+	if !ok {
+		return nil, fmt.Errorf("pull request with id '%d' not found", number)
+	}
+	if fakePR.ApprovalState == nil {
+		return &ApprovalState{}, nil
+	}
+	return fakePR.ApprovalState, nil
+}
+
 func (f *FakeProvider) ListCommitStatus(org string, repoName string, sha string) ([]*RepoStatus, error) {
 	repos, ok := f.Repositories[org]
 	if !ok {
@@ -346,26 +563,38 @@ func (f *FakeProvider) ListCommitStatus(org string, repoName string, sha string)
 			answer = append(answer, status)
 		}
 	}
+	answer = append(answer, repo.Statuses[sha]...)
 	return answer, nil
 }
 
+func (f *FakeProvider) GetCommitStatus(org string, repoName string, sha string, context string) (*RepoStatus, error) {
+	statuses, err := f.ListCommitStatus(org, repoName, sha)
+	if err != nil {
+		return nil, err
+	}
+	return FindRepoStatus(statuses, context), nil
+}
+
+// UpdateCommitStatus replaces the status of the commit with the same context, or adds it if there is none
 func (f *FakeProvider) UpdateCommitStatus(org string, repo string, sha string, status *RepoStatus) (*RepoStatus, error) {
-	repoStatus, err := f.ListCommitStatus(org, repo, sha)
+	if err := status.NormalizeContext(MaxStatusContextLength); err != nil {
+		return &RepoStatus{}, err
+	}
+	r, err := f.findRepository(org, repo)
 	if err != nil {
 		return &RepoStatus{}, err
 	}
-	updated := false
-	for i, s := range repoStatus {
-		if s.ID == status.ID {
-			repoStatus[i] = status
-			updated = true
-		}
+	if r.Statuses == nil {
+		r.Statuses = map[string][]*RepoStatus{}
 	}
-	if !updated {
-		repoStatus = append(repoStatus, status)
+	for i, s := range r.Statuses[sha] {
+		if s.Context == status.Context {
+			r.Statuses[sha][i] = status
+			return status, nil
+		}
 	}
+	r.Statuses[sha] = append(r.Statuses[sha], status)
 	return status, nil
-
 }
 
 func (f *FakeProvider) MergePullRequest(pr *PullRequest, message string) error {
@@ -398,7 +627,7 @@ func (f *FakeProvider) MergePullRequest(pr *PullRequest, message string) error {
 }
 
 func (f *FakeProvider) CreateWebHook(data *WebhookArguments) error {
-	return nil
+	return data.Validate()
 }
 
 func (p *FakeProvider) ListWebHooks(owner string, repo string) ([]*WebhookArguments, error) {
@@ -410,6 +639,18 @@ func (p *FakeProvider) UpdateWebHook(data *WebhookArguments) error {
 	return fmt.Errorf("not implemented!")
 }
 
+func (p *FakeProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*WebHookDelivery, error) {
+	return []*WebHookDelivery{}, nil
+}
+
+func (p *FakeProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return nil
+}
+
+func (p *FakeProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	return nil
+}
+
 func (f *FakeProvider) IsGitHub() bool {
 	return f.Type == GitHub
 }
@@ -488,7 +729,7 @@ func (f *FakeProvider) SearchIssues(org string, name string, query string) ([]*I
 			for _, issue := range repo.Issues {
 				answer = append(answer, issue.Issue)
 			}
-			return answer, nil
+			return ParseIssueQuery(query).FilterIssues(answer), nil
 		}
 	}
 	return nil, fmt.Errorf("repository with name '%s' not found", name)
@@ -535,6 +776,15 @@ func (f *FakeProvider) HasIssues() bool {
 	return true
 }
 
+func (f *FakeProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	repo, err := f.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	repo.HasIssuesEnabled = enabled
+	return nil
+}
+
 func (f *FakeProvider) AddPRComment(pr *PullRequest, comment string) error {
 	owner := pr.Owner
 	repos, ok := f.Repositories[owner]
@@ -574,6 +824,57 @@ func (f *FakeProvider) CreateIssueComment(owner string, repoName string, number
 	return fmt.Errorf("repository with name '%s' not found", repoName)
 }
 
+// AddLabelsToIssue adds the labels to the issue or pull request with the number
+func (f *FakeProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return f.editLabels(owner, repo, number, func(existing []Label) []Label {
+		for _, label := range labels {
+			if !hasLabel(existing, label) {
+				existing = append(existing, Label{Name: label})
+			}
+		}
+		return existing
+	})
+}
+
+// RemoveLabelFromIssue removes the label from the issue or pull request with the number
+func (f *FakeProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return f.editLabels(owner, repo, number, func(existing []Label) []Label {
+		answer := []Label{}
+		for _, l := range existing {
+			if l.Name != label {
+				answer = append(answer, l)
+			}
+		}
+		return answer
+	})
+}
+
+// editLabels replaces the labels of the issue or pull request with the number with the ones returned by edit
+func (f *FakeProvider) editLabels(owner string, repo string, number int, edit func([]Label) []Label) error {
+	r, err := f.findRepository(owner, repo)
+	if err != nil {
+		return err
+	}
+	if issue, ok := r.Issues[number]; ok {
+		issue.Issue.Labels = edit(issue.Issue.Labels)
+		return nil
+	}
+	if pr, ok := r.PullRequests[number]; ok {
+		pr.PullRequest.Labels = edit(pr.PullRequest.Labels)
+		return nil
+	}
+	return fmt.Errorf("issue %s/%s#%d: %w", owner, repo, number, ErrNotFound)
+}
+
+func hasLabel(labels []Label, name string) bool {
+	for _, label := range labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *FakeProvider) UpdateRelease(owner string, repoName string, tag string, releaseInfo *Release) error {
 	repos, ok := f.Repositories[owner]
 	if !ok {
@@ -607,6 +908,54 @@ func (f *FakeProvider) ListReleases(org string, name string) ([]*Release, error)
 	return nil, fmt.Errorf("repository with name '%s' not found", name)
 }
 
+func (f *FakeProvider) ListMilestones(org string, name string) ([]*Milestone, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Milestones, nil
+}
+
+// CreateMilestone adds an open milestone to the fake repository, numbering milestones from 1 as GitHub does
+func (f *FakeProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*Milestone, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	milestone := &Milestone{
+		ID:    int64(len(repo.Milestones) + 1),
+		Title: title,
+		State: MilestoneStateOpen,
+		DueOn: due,
+	}
+	repo.Milestones = append(repo.Milestones, milestone)
+	return milestone, nil
+}
+
+func (f *FakeProvider) CloseMilestone(org string, name string, id int64) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	for _, milestone := range repo.Milestones {
+		if milestone.ID == id {
+			milestone.State = MilestoneStateClosed
+			return nil
+		}
+	}
+	return fmt.Errorf("milestone with id '%d' not found", id)
+}
+
+func (f *FakeProvider) ListContributors(org string, name string) ([]*Contributor, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := append([]*Contributor{}, repo.Contributors...)
+	SortContributors(answer)
+	return answer, nil
+}
+
 func (f *FakeProvider) JenkinsWebHookPath(gitURL string, secret string) string {
 	return jenkinsWebhookPath
 }
@@ -623,10 +972,18 @@ func (f *FakeProvider) BranchArchiveURL(org string, name string, branch string)
 	return util.UrlJoin(f.ServerURL(), org, name, "archive", branch+".zip")
 }
 
+func (f *FakeProvider) AccessTokenURL() string {
+	return ""
+}
+
 func (f *FakeProvider) CurrentUsername() string {
 	return f.Username
 }
 
+func (f *FakeProvider) GetCurrentUser() (*User, error) {
+	return &f.User, nil
+}
+
 func (f *FakeProvider) UserInfo(username string) *User {
 	for _, user := range f.Users {
 		if user.Name == username {
@@ -636,23 +993,241 @@ func (f *FakeProvider) UserInfo(username string) *User {
 	return nil
 }
 
+func (f *FakeProvider) GetUsers(usernames []string) (map[string]*User, error) {
+	answer := map[string]*User{}
+	for _, username := range usernames {
+		if user := f.UserInfo(username); user != nil {
+			answer[username] = user
+		}
+	}
+	return answer, nil
+}
+
+// DeleteBranch records the branch as deleted, and forgets it if it was created with CreateBranch
+func (f *FakeProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	defaultBranch := repo.GitRepo.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch = "master"
+	}
+	if branch == defaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, ErrDefaultBranch)
+	}
+	delete(repo.Branches, branch)
+	repo.DeletedBranches = append(repo.DeletedBranches, branch)
+	return nil
+}
+
+// CreateBranch records the branch with the SHA the ref resolves to. Only the default branch and the branches created
+// with CreateBranch are known to exist
+func (f *FakeProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	defaultBranch := repo.GitRepo.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch = "master"
+	}
+	if _, ok := repo.Branches[branch]; ok || branch == defaultBranch {
+		return fmt.Errorf("create branch %s of %s/%s: %w", branch, org, name, ErrAlreadyExists)
+	}
+	sha, err := f.ResolveRef(org, name, fromRef)
+	if err != nil {
+		return err
+	}
+	if repo.Branches == nil {
+		repo.Branches = map[string]string{}
+	}
+	repo.Branches[branch] = sha
+	return nil
+}
+
+func (f *FakeProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	if repo.Tags == nil {
+		repo.Tags = map[string]*GitTag{}
+	}
+	repo.Tags[tag] = &GitTag{
+		Name:    tag,
+		Message: message,
+		SHA:     sha,
+	}
+	return nil
+}
+
+func (f *FakeProvider) GetTag(org string, name string, tag string) (*GitTag, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Tags[tag], nil
+}
+
+// ListRepositoryTags returns the tags created with CreateTag sorted by name
+func (f *FakeProvider) ListRepositoryTags(org string, name string) ([]*GitTag, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer := []*GitTag{}
+	for _, tag := range repo.Tags {
+		answer = append(answer, tag)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+func (f *FakeProvider) findRepository(org string, name string) (*FakeRepository, error) {
+	repos, ok := f.Repositories[org]
+	if !ok {
+		return nil, fmt.Errorf("organization '%s': %w", org, ErrNotFound)
+	}
+	for _, repo := range repos {
+		if repo.GitRepo.Name == name {
+			return repo, nil
+		}
+	}
+	return nil, fmt.Errorf("repository '%s' within the organization '%s': %w", name, org, ErrNotFound)
+}
+
+func (f *FakeProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return 0, 0, err
+	}
+	upstream, err := f.findRepository(upstreamOrg, upstreamName)
+	if err != nil {
+		return 0, 0, err
+	}
+	return countMissingCommits(upstream.Commits, repo.Commits), countMissingCommits(repo.Commits, upstream.Commits), nil
+}
+
+// countMissingCommits returns how many of the commits are not in base
+func countMissingCommits(base []*FakeCommit, commits []*FakeCommit) int {
+	shas := map[string]bool{}
+	for _, c := range base {
+		shas[c.Commit.SHA] = true
+	}
+	count := 0
+	for _, c := range commits {
+		if !shas[c.Commit.SHA] {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *FakeProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	repo, err := f.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	upstream, err := f.findRepository(upstreamOrg, upstreamName)
+	if err != nil {
+		return err
+	}
+	shas := map[string]bool{}
+	for _, c := range repo.Commits {
+		shas[c.Commit.SHA] = true
+	}
+	for _, c := range upstream.Commits {
+		if !shas[c.Commit.SHA] {
+			repo.Commits = append(repo.Commits, c)
+		}
+	}
+	return nil
+}
+
 func (f *FakeProvider) AddCollaborator(user string, organisation string, repo string) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for git fake. Please add user: %v as a collaborator to this project.\n", user)
 	return nil
 }
 
-func (f *FakeProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+func (f *FakeProvider) ListInvitations() ([]*Invitation, error) {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for git fake.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+	return []*Invitation{}, nil
 }
 
-func (f *FakeProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func (f *FakeProvider) AcceptInvitation(ID int64) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for git fake.\n")
-	return &github.Response{}, nil
+	return nil
 }
 
 func (r *FakeProvider) GetContent(org string, name string, path string, ref string) (*FileContent, error) {
-	return nil, nil
+	repo, err := r.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := repo.Files[path]
+	if !ok {
+		return nil, fmt.Errorf("file '%s' in repository '%s': %w", path, name, ErrNotFound)
+	}
+	return &FileContent{
+		Type:    "file",
+		Size:    len(content),
+		Name:    path[strings.LastIndex(path, "/")+1:],
+		Path:    path,
+		Content: content,
+	}, nil
+}
+
+// ResolveRef returns the SHA of the branch created with CreateBranch or of the tag if there is one, otherwise the
+// last commit of the repository
+func (r *FakeProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	repo, err := r.findRepository(org, name)
+	if err != nil {
+		return "", err
+	}
+	if sha, ok := repo.Branches[ref]; ok {
+		return sha, nil
+	}
+	if tag, ok := repo.Tags[ref]; ok {
+		return tag.SHA, nil
+	}
+	if len(repo.Commits) == 0 {
+		return "", fmt.Errorf("ref '%s' not found", ref)
+	}
+	return repo.Commits[len(repo.Commits)-1].Commit.SHA, nil
+}
+
+// GetFileLastCommit returns the last commit of the repository as the fake provider doesn't track which files commits change
+func (r *FakeProvider) GetFileLastCommit(org string, name string, path string, ref string) (*Commit, error) {
+	repo, err := r.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(repo.Commits) == 0 {
+		return nil, nil
+	}
+	return repo.Commits[len(repo.Commits)-1].Commit, nil
+}
+
+// BranchesContainingCommit returns the default branch if the repository has the commit, as the fake provider doesn't
+// track branches
+func (r *FakeProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	repo, err := r.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, commit := range repo.Commits {
+		if commit.Commit.SHA == sha {
+			branch := repo.GitRepo.DefaultBranch
+			if branch == "" {
+				branch = "master"
+			}
+			return []string{branch}, nil
+		}
+	}
+	return []string{}, nil
 }
 
 func (r *FakeRepository) String() string {
@@ -668,12 +1243,21 @@ func NewFakeRepository(owner string, repoName string) *FakeRepository {
 	return &FakeRepository{
 		Owner: owner,
 		GitRepo: &Repository{
-			Name:     repoName,
-			CloneURL: "https://github.com/" + owner + "/" + repoName + ".git",
-			HTMLURL:  "https://github.com/" + owner + "/" + repoName,
+			Name:             repoName,
+			CloneURL:         "https://github.com/" + owner + "/" + repoName + ".git",
+			HTMLURL:          "https://github.com/" + owner + "/" + repoName,
+			HasIssuesEnabled: true,
 		},
 		PullRequests: map[int]*FakePullRequest{},
+		Issues:       map[int]*FakeIssue{},
 		Commits:      []*FakeCommit{},
+		Tags:         map[string]*GitTag{},
+		MergeConfig: MergeConfig{
+			AllowMergeCommit: true,
+			AllowSquash:      true,
+			AllowRebase:      true,
+			DefaultMethod:    MergeMethodMerge,
+		},
 	}
 }
 
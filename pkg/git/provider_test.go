@@ -1,15 +1,18 @@
-package git
+package git_test
 
 import (
 	"errors"
 	"os"
-	"strings"
 	"testing"
 
-	mocks "github.com/jenkins-x/jx/pkg/gits/mocks"
-	utiltests "github.com/jenkins-x/jx/pkg/tests"
-	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/wbrefvem/go-gits/pkg/bitbucketcloud"
+	"github.com/wbrefvem/go-gits/pkg/bitbucketserver"
+	"github.com/wbrefvem/go-gits/pkg/gerrit"
+	"github.com/wbrefvem/go-gits/pkg/git"
+	"github.com/wbrefvem/go-gits/pkg/gitea"
+	"github.com/wbrefvem/go-gits/pkg/github"
+	"github.com/wbrefvem/go-gits/pkg/gitlab"
 )
 
 type FakeOrgLister struct {
@@ -17,14 +20,14 @@ type FakeOrgLister struct {
 	fail     bool
 }
 
-func (l FakeOrgLister) ListOrganisations() ([]Organisation, error) {
+func (l FakeOrgLister) ListOrganisations() ([]git.Organisation, error) {
 	if l.fail {
 		return nil, errors.New("fail")
 	}
 
-	orgs := make([]Organisation, len(l.orgNames))
+	orgs := make([]git.Organisation, len(l.orgNames))
 	for _, v := range l.orgNames {
-		orgs = append(orgs, Organisation{Login: v})
+		orgs = append(orgs, git.Organisation{Login: v})
 	}
 	return orgs, nil
 }
@@ -33,7 +36,7 @@ func Test_getOrganizations(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		testDescription string
-		orgLister       OrganisationLister
+		orgLister       git.OrganisationLister
 		userName        string
 		want            []string
 	}{
@@ -44,677 +47,175 @@ func Test_getOrganizations(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.testDescription, func(t *testing.T) {
-			result := GetOrganizations(tt.orgLister, tt.userName)
+			result := git.GetOrganizations(tt.orgLister, tt.userName)
 			assert.Equal(t, tt.want, result)
 		})
 	}
 }
 
-func createGitProvider(t *testing.T, kind string, git Gitter) Provider {
+func createGitProvider(t *testing.T, kind string, serverURL string, username string, token string, gitter git.Gitter) git.Provider {
+	var provider git.Provider
+	var err error
 	switch kind {
-	case KindGitHub:
-		gitHubProvider, err := NewGitHubProvider(server, user, git)
-		assert.NoError(t, err, "should create GitHub provider without error")
-		return gitHubProvider
-	case KindGitlab:
-		gitlabProvider, err := NewGitlabProvider(server, user, git)
-		assert.NoError(t, err, "should create Gitlab provider without error")
-		return gitlabProvider
-	case KindGitea:
-		giteaProvider, err := NewGiteaProvider(server, user, git)
-		assert.NoError(t, err, "should create Gitea provider without error")
-		return giteaProvider
-	case KindBitBucketServer:
-		bitbucketServerProvider, err := NewBitbucketServerProvider(server, user, git)
-		assert.NoError(t, err, "should create Bitbucket server  provider without error")
-		return bitbucketServerProvider
-	case KindBitBucketCloud:
-		bitbucketCloudProvider, err := NewBitbucketCloudProvider(server, user, git)
-		assert.NoError(t, err, "should create Bitbucket cloud  provider without error")
-		return bitbucketCloudProvider
+	case git.KindGitHub:
+		provider, err = github.NewProvider(username, serverURL, token, kind, gitter)
+	case git.KindGitlab:
+		provider, err = gitlab.NewProvider(username, serverURL, token, kind, gitter)
+	case git.KindGitea:
+		provider, err = gitea.NewProvider(username, serverURL, token, kind, gitter)
+	case git.KindBitBucketServer:
+		provider, err = bitbucketserver.NewProvider(username, serverURL, token, kind, gitter)
+	case git.KindBitBucketCloud:
+		provider, err = bitbucketcloud.NewProvider(username, serverURL, token, kind, gitter)
+	case git.KindGerrit:
+		provider, err = gerrit.NewProvider(username, serverURL, token, gitter)
 	default:
 		return nil
 	}
+	assert.NoError(t, err, "should create %s provider without error", kind)
+	return provider
 }
 
 func setUserAuthInEnv(kind string, username string, apiToken string) error {
-	prefix := strings.ToUpper(kind)
-	err := os.Setenv(prefix+"_USERNAME", username)
+	envVars := git.CredentialEnvVars(kind)
+	err := os.Setenv(envVars[0], username)
 	if err != nil {
 		return err
 	}
-	return os.Setenv(prefix+"_API_TOKEN", apiToken)
+	return os.Setenv(envVars[1], apiToken)
 }
 
 func unsetUserAuthInEnv(kind string) error {
-	prefix := strings.ToUpper(kind)
-	err := os.Unsetenv(prefix + "_USERNAME")
+	envVars := git.CredentialEnvVars(kind)
+	err := os.Unsetenv(envVars[0])
 	if err != nil {
 		return err
 	}
-	return os.Unsetenv(prefix + "_API_TOKEN")
+	return os.Unsetenv(envVars[1])
 }
 
+// getAndCleanEnviron unsets the credential environment variables of the kind, returning their values to restore
 func getAndCleanEnviron(kind string) (map[string]string, error) {
-	prefix := strings.ToUpper(kind)
-	keys := []string{
-		prefix + "_USERNAME",
-		prefix + "_API_TOKEN",
-		"GIT_USERNAME",
-		"GIT_API_TOKEN",
+	environ := map[string]string{}
+	for _, key := range git.CredentialEnvVars(kind) {
+		if value, ok := os.LookupEnv(key); ok {
+			environ[key] = value
+			if err := os.Unsetenv(key); err != nil {
+				return environ, err
+			}
+		}
 	}
-	return util.GetAndCleanEnviron(keys)
+	return environ, nil
 }
 
 func restoreEnviron(t *testing.T, environ map[string]string) {
-	err := util.RestoreEnviron(environ)
-	assert.NoError(t, err, "should restore the env variable")
+	for key, value := range environ {
+		err := os.Setenv(key, value)
+		assert.NoError(t, err, "should restore the env variable")
+	}
 }
 
-func TestCreateGitProviderFromURL(t *testing.T) {
-	t.Parallel()
-	utiltests.SkipForWindows(t, "go-expect does not work on Windows")
+// credentialsFromEnv reads the credentials of the kind from its environment variables, falling back to the
+// GIT_USERNAME and GIT_API_TOKEN ones
+func credentialsFromEnv(kind string) map[string]string {
+	envVars := git.CredentialEnvVars(kind)
+	creds := map[string]string{}
+	for i := 0; i < len(envVars); i += 2 {
+		if creds[git.CredentialsUsernameKey] == "" {
+			creds[git.CredentialsUsernameKey] = os.Getenv(envVars[i])
+		}
+		if creds[git.CredentialsTokenKey] == "" {
+			creds[git.CredentialsTokenKey] = os.Getenv(envVars[i+1])
+		}
+	}
+	return creds
+}
 
-	git := mocks.NewMockGitter()
+func TestCreateGitProviderFromURL(t *testing.T) {
+	gitter := git.NewGitFake()
 
-	tests := []struct {
+	type testCase struct {
 		description  string
-		setup        func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{})
-		cleanup      func(c *utiltests.ConsoleWrapper, donech chan struct{})
-		Name         string
+		setup        func(t *testing.T)
+		cleanup      func(t *testing.T)
 		providerKind string
 		hostURL      string
-		git          Gitter
-		numUsers     int
-		currUser     int
-		pipelineUser int
+		creds        map[string]string
 		username     string
 		apiToken     string
-		batchMode    bool
-		inCluster    bool
 		wantError    bool
+	}
+	hosts := []struct {
+		name string
+		kind string
+		url  string
 	}{
-		{"create GitHub provider for one user",
-			nil,
-			nil,
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			1,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create GitHub provider for multiple users",
-			nil,
-			nil,
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			2,
-			1,
-			1,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create GitHub provider for pipline user when in cluster ",
-			nil,
-			nil,
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			2,
-			1,
-			0,
-			"test",
-			"test",
-			false,
-			true,
-			false,
-		},
-		{"create GitHub provider for user from environment",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				err := setUserAuthInEnv(KindGitHub, "test", "test")
-				assert.NoError(t, err, "should configure the user auth in environment")
-				console := utiltests.NewTerminal(t)
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-				}()
-				return console, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := unsetUserAuthInEnv(KindGitHub)
-				assert.NoError(t, err, "should reset the user auth in environment")
-				err = c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create GitHub provider in barch mode ",
-			nil,
-			nil,
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			0,
-			0,
-			0,
-			"",
-			"",
-			true,
-			false,
-			true,
-		},
-		{"create GitHub provider in interactive mode",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				c := utiltests.NewTerminal(t)
-				assert.NotNil(t, c, "console should not be nil")
-				assert.NotNil(t, c.Stdio, "term should not be nil")
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-					c.ExpectString("github.com user name:")
-					c.SendLine("test")
-					c.ExpectString("API Token:")
-					c.SendLine("test")
-					c.ExpectEOF()
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"GitHub",
-			KindGitHub,
-			"https://github.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitlab provider for one user",
-			nil,
-			nil,
-			"Gitlab",
-			KindGitlab,
-			"https://gitlab.com",
-			git,
-			1,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitlab provider for multiple users",
-			nil,
-			nil,
-			"Gitlab",
-			KindGitHub,
-			"https://gitlab.com",
-			git,
-			2,
-			1,
-			1,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitlab provider for user from environment",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				err := setUserAuthInEnv(KindGitlab, "test", "test")
-				assert.NoError(t, err, "should configure the user auth in environment")
-				c := utiltests.NewTerminal(t)
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := unsetUserAuthInEnv(KindGitlab)
-				assert.NoError(t, err, "should reset the user auth in environment")
-				err = c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"Gitlab",
-			KindGitlab,
-			"https://gitlab.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitlab provider in barch mode ",
-			nil,
-			nil,
-			"Gitlab",
-			KindGitlab,
-			"https://gitlab.com",
-			git,
-			0,
-			0,
-			0,
-			"",
-			"",
-			true,
-			false,
-			true,
-		},
-		{"create Gitlab provider in interactive mode",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				c := utiltests.NewTerminal(t)
-				assert.NotNil(t, c, "console should not be nil")
-				assert.NotNil(t, c.Stdio, "term should not be nil")
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-					c.ExpectString("gitlab.com user name:")
-					c.SendLine("test")
-					c.ExpectString("API Token:")
-					c.SendLine("test")
-					c.ExpectEOF()
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"Gitlab",
-			KindGitlab,
-			"https://gitlab.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitea provider for one user",
-			nil,
-			nil,
-			"Gitea",
-			KindGitea,
-			"https://gitea.com",
-			git,
-			1,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitea provider for multiple users",
-			nil,
-			nil,
-			"Gitea",
-			KindGitea,
-			"https://gitea.com",
-			git,
-			2,
-			1,
-			1,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitea provider for user from environment",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				err := setUserAuthInEnv(KindGitea, "test", "test")
-				assert.NoError(t, err, "should configure the user auth in environment")
-				c := utiltests.NewTerminal(t)
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := unsetUserAuthInEnv(KindGitea)
-				assert.NoError(t, err, "should reset the user auth in environment")
-				err = c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"Gitea",
-			KindGitea,
-			"https://gitea.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create Gitea provider in barch mode ",
-			nil,
-			nil,
-			"Gitea",
-			KindGitea,
-			"https://gitea.com",
-			git,
-			0,
-			0,
-			0,
-			"",
-			"",
-			true,
-			false,
-			true,
-		},
-		{"create Gitea provider in interactive mode",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				c := utiltests.NewTerminal(t)
-				assert.NotNil(t, c, "console should not be nil")
-				assert.NotNil(t, c.Stdio, "term should not be nil")
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-					c.ExpectString("gitea.com user name:")
-					c.SendLine("test")
-					c.ExpectString("API Token:")
-					c.SendLine("test")
-					c.ExpectEOF()
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"Gitea",
-			KindGitea,
-			"https://gitea.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketServer provider for one user",
-			nil,
-			nil,
-			"BitbucketServer",
-			KindBitBucketServer,
-			"https://bitbucket-server.com",
-			git,
-			1,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketServer provider for multiple users",
-			nil,
-			nil,
-			"BitbucketServer",
-			KindBitBucketServer,
-			"https://bitbucket-server.com",
-			git,
-			2,
-			1,
-			1,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketServer provider for user from environment",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				err := setUserAuthInEnv(KindBitBucketServer, "test", "test")
-				assert.NoError(t, err, "should configure the user auth in environment")
-				c := utiltests.NewTerminal(t)
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := unsetUserAuthInEnv(KindBitBucketServer)
-				assert.NoError(t, err, "should reset the user auth in environment")
-				err = c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
-			},
-			"BitbucketServer",
-			KindBitBucketServer,
-			"https://bitbucket-server.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketServer provider in barch mode ",
-			nil,
-			nil,
-			"BitbucketServer",
-			KindBitBucketServer,
-			"https://bitbucket-server.com",
-			git,
-			0,
-			0,
-			0,
-			"",
-			"",
-			true,
-			false,
-			true,
-		},
-		{"create BitbucketServer provider in interactive mode",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				c := utiltests.NewTerminal(t)
-				assert.NotNil(t, c, "console should not be nil")
-				assert.NotNil(t, c.Stdio, "term should not be nil")
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-					c.ExpectString("bitbucket-server.com user name:")
-					c.SendLine("test")
-					c.ExpectString("API Token:")
-					c.SendLine("test")
-					c.ExpectEOF()
-				}()
-				return c, donech
-			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
+		{"GitHub", git.KindGitHub, "https://github.com"},
+		{"Gitlab", git.KindGitlab, "https://gitlab.com"},
+		{"Gitea", git.KindGitea, "https://gitea.com"},
+		{"BitbucketServer", git.KindBitBucketServer, "https://bitbucket-server.com"},
+		{"BitbucketCloud", git.KindBitBucketCloud, "https://bitbucket.org"},
+		{"Gerrit", git.KindGerrit, "https://gerrit.com"},
+	}
+	tests := []testCase{}
+	for _, host := range hosts {
+		kind := host.kind
+		tests = append(tests,
+			testCase{
+				description:  "create " + host.name + " provider for a user",
+				providerKind: kind,
+				hostURL:      host.url,
+				creds: map[string]string{
+					git.CredentialsUsernameKey: "test",
+					git.CredentialsTokenKey:    "test",
+				},
+				username: "test",
+				apiToken: "test",
 			},
-			"BitbucketServer",
-			KindBitBucketServer,
-			"https://bitbucket-server.com",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketCloud provider for one user",
-			nil,
-			nil,
-			"BitbucketCloud",
-			KindBitBucketCloud,
-			"https://bitbucket.org",
-			git,
-			1,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketCloud provider for multiple users",
-			nil,
-			nil,
-			"BitbucketCloud",
-			KindBitBucketCloud,
-			"https://bitbucket.org",
-			git,
-			2,
-			1,
-			1,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketCloud provider for user from environment",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				err := setUserAuthInEnv(KindBitBucketCloud, "test", "test")
-				assert.NoError(t, err, "should configure the user auth in environment")
-				c := utiltests.NewTerminal(t)
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-				}()
-				return c, donech
+			testCase{
+				description:  "create " + host.name + " provider for a bearer token",
+				providerKind: kind,
+				hostURL:      host.url,
+				creds: map[string]string{
+					git.CredentialsUsernameKey:    "test",
+					git.CredentialsBearerTokenKey: "bearer",
+				},
+				username: "test",
+				apiToken: "bearer",
 			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := unsetUserAuthInEnv(KindBitBucketCloud)
-				assert.NoError(t, err, "should reset the user auth in environment")
-				err = c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
+			testCase{
+				description:  "create " + host.name + " provider for a token without a user",
+				providerKind: kind,
+				hostURL:      host.url,
+				creds: map[string]string{
+					git.CredentialsTokenKey: "test",
+				},
+				username: "",
+				apiToken: "test",
 			},
-			"BitbucketCloud",
-			KindBitBucketCloud,
-			"https://bitbucket.org",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
-		{"create BitbucketCloud provider in barch mode ",
-			nil,
-			nil,
-			"BitbucketCloud",
-			KindBitBucketCloud,
-			"https://bitbucket.org",
-			git,
-			0,
-			0,
-			0,
-			"",
-			"",
-			true,
-			false,
-			true,
-		},
-		{"create BitbucketCloud provider in interactive mode",
-			func(t *testing.T) (*utiltests.ConsoleWrapper, chan struct{}) {
-				c := utiltests.NewTerminal(t)
-				assert.NotNil(t, c, "console should not be nil")
-				assert.NotNil(t, c.Stdio, "term should not be nil")
-				donech := make(chan struct{})
-				go func() {
-					defer close(donech)
-					c.ExpectString("bitbucket.org user name:")
-					c.SendLine("test")
-					c.ExpectString("API Token:")
-					c.SendLine("test")
-					c.ExpectEOF()
-				}()
-				return c, donech
+			testCase{
+				description: "create " + host.name + " provider for user from environment",
+				setup: func(t *testing.T) {
+					err := setUserAuthInEnv(kind, "test", "test")
+					assert.NoError(t, err, "should configure the user auth in environment")
+				},
+				cleanup: func(t *testing.T) {
+					err := unsetUserAuthInEnv(kind)
+					assert.NoError(t, err, "should reset the user auth in environment")
+				},
+				providerKind: kind,
+				hostURL:      host.url,
+				username:     "test",
+				apiToken:     "test",
 			},
-			func(c *utiltests.ConsoleWrapper, donech chan struct{}) {
-				err := c.Close()
-				assert.NoError(t, err, "should close the tty")
-				<-donech
+			testCase{
+				description:  "create " + host.name + " provider without credentials",
+				providerKind: kind,
+				hostURL:      host.url,
+				creds:        map[string]string{},
+				wantError:    true,
 			},
-			"BitbucketCloud",
-			KindBitBucketCloud,
-			"https://bitbucket.org",
-			git,
-			0,
-			0,
-			0,
-			"test",
-			"test",
-			false,
-			false,
-			false,
-		},
+		)
 	}
 
 	for _, tc := range tests {
@@ -723,44 +224,34 @@ func TestCreateGitProviderFromURL(t *testing.T) {
 			assert.NoError(t, err, "should clean the env variables")
 			defer restoreEnviron(t, environ)
 
-			var console *utiltests.ConsoleWrapper
-			var donech chan struct{}
 			if tc.setup != nil {
-				console, donech = tc.setup(t)
+				tc.setup(t)
 			}
-
-			var result Provider
-			if console != nil {
-				result, err = CreateProviderForURL(tc.inCluster, *authSvc, tc.providerKind, tc.hostURL, tc.git, tc.batchMode, console.In, console.Out, console.Err)
-			} else {
-				result, err = CreateProviderForURL(tc.inCluster, *authSvc, tc.providerKind, tc.hostURL, tc.git, tc.batchMode, nil, nil, nil)
+			if tc.cleanup != nil {
+				defer tc.cleanup(t)
+			}
+			creds := tc.creds
+			if creds == nil {
+				creds = credentialsFromEnv(tc.providerKind)
 			}
+
+			result, err := git.NewProviderFromCredentials(tc.providerKind, tc.hostURL, creds, gitter)
 			if tc.wantError {
 				assert.Error(t, err, "should fail to create provider")
 				assert.Nil(t, result, "created provider should be nil")
-			} else {
-				assert.NoError(t, err, "should create provider without error")
-				assert.NotNil(t, result, "created provider should not be nil")
-				if tc.inCluster {
-					want := createGitProvider(t, tc.providerKind, server, pipelineUser, tc.git)
-					assert.NotNil(t, want, "expected provider should not be nil")
-					assertProvider(t, want, result)
-				} else {
-					want := createGitProvider(t, tc.providerKind, server, currUser, tc.git)
-					assert.NotNil(t, want, "expected provider should not be nil")
-					assertProvider(t, want, result)
-				}
-			}
-
-			if tc.cleanup != nil {
-				tc.cleanup(console, donech)
+				return
 			}
+			assert.NoError(t, err, "should create provider without error")
+			assert.NotNil(t, result, "created provider should not be nil")
+			want := createGitProvider(t, tc.providerKind, tc.hostURL, tc.username, tc.apiToken, gitter)
+			assert.NotNil(t, want, "expected provider should not be nil")
+			assertProvider(t, want, result)
 		})
 	}
 }
 
-func assertProvider(t *testing.T, want Provider, result Provider) {
+func assertProvider(t *testing.T, want git.Provider, result git.Provider) {
 	assert.Equal(t, want.Kind(), result.Kind())
 	assert.Equal(t, want.ServerURL(), result.ServerURL())
-	assert.Equal(t, want.UserAuth(), result.UserAuth())
+	assert.Equal(t, want.CurrentUsername(), result.CurrentUsername())
 }
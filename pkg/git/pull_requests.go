@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// PullRequestStateOpen is the state of pull requests which are neither merged nor closed
+	PullRequestStateOpen = "open"
+	// PullRequestStateClosed is the state of pull requests which were merged or closed without merging
+	PullRequestStateClosed = "closed"
+	// PullRequestStateAll lists pull requests whatever their state
+	PullRequestStateAll = "all"
+)
+
+// DiffStat is the size of the change of a pull request
+type DiffStat struct {
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+	// Truncated is true when the provider only returned part of a large change, which is then at least as big as
+	// counted
+	Truncated bool
+}
+
+// CloseStalePullRequests closes the open pull requests of a repository which target the base branch, except
+// the one numbered keepNumber, commenting on each that it has been superseded. It is used to clean up
+// promotion pull requests once a newer one has been created
+func CloseStalePullRequests(provider Provider, owner string, repo *Repository, base string, keepNumber int) error {
+	prs, err := provider.ListPullRequests(owner, repo, PullRequestStateOpen)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the open pull requests of %s/%s", owner, repo.Name)
+	}
+
+	for _, pr := range prs {
+		if pr.Number == nil || *pr.Number == keepNumber || pr.BaseRef == nil || *pr.BaseRef != base {
+			continue
+		}
+		comment := fmt.Sprintf("Closing this pull request as it has been superseded by pull request %d", keepNumber)
+		err = provider.AddPRComment(pr, comment)
+		if err != nil {
+			return errors.Wrapf(err, "failed to comment on pull request %d of %s/%s", *pr.Number, owner, repo.Name)
+		}
+		err = provider.ClosePullRequest(pr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to close pull request %d of %s/%s", *pr.Number, owner, repo.Name)
+		}
+	}
+	return nil
+}
+
+// FilterPullRequestsByAuthor returns the pull requests opened by the user with the given login, which is compared
+// ignoring case as the providers do
+func FilterPullRequestsByAuthor(prs []*PullRequest, author string) []*PullRequest {
+	answer := []*PullRequest{}
+	for _, pr := range prs {
+		if pr.Author != nil && strings.EqualFold(pr.Author.Login, author) {
+			answer = append(answer, pr)
+		}
+	}
+	return answer
+}
+
+// FindPullRequestByBranch returns the first of the pull requests from the branch, or nil if there is none
+func FindPullRequestByBranch(prs []*PullRequest, branch string) *PullRequest {
+	for _, pr := range prs {
+		if pr.HeadRef != nil && *pr.HeadRef == branch {
+			return pr
+		}
+	}
+	return nil
+}
+
+// CountDiffLines returns the number of lines added and removed by a unified diff. The headers of the files are
+// skipped, so a removed line starting with "--" is still counted
+func CountDiffLines(diff string) (int, int) {
+	additions, deletions := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "diff "):
+			inHunk = false
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
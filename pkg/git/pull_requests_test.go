@@ -0,0 +1,115 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseStalePullRequests(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFakeProvider(NewFakeRepository("org", "environment-staging"))
+	repo := &Repository{Organisation: "org", Name: "environment-staging"}
+	numbers := []int{}
+	for _, base := range []string{"master", "master", "release", "master"} {
+		pr, err := provider.CreatePullRequest(&PullRequestArguments{
+			Repository: repo,
+			Title:      "Promote",
+			Base:       base,
+		})
+		assert.NoError(t, err)
+		numbers = append(numbers, *pr.Number)
+	}
+
+	err := CloseStalePullRequests(provider, "org", repo, "master", numbers[3])
+	assert.NoError(t, err)
+
+	open, err := provider.ListPullRequests("org", repo, PullRequestStateOpen)
+	assert.NoError(t, err)
+	assert.Len(t, open, 2)
+	assert.Equal(t, numbers[2], *open[0].Number)
+	assert.Equal(t, numbers[3], *open[1].Number)
+
+	closed, err := provider.ListPullRequests("org", repo, PullRequestStateClosed)
+	assert.NoError(t, err)
+	assert.Len(t, closed, 2)
+	assert.Equal(t, numbers[0], *closed[0].Number)
+	assert.Equal(t, numbers[1], *closed[1].Number)
+	assert.Contains(t, provider.Repositories["org"][0].PullRequests[numbers[0]].Comment, "superseded")
+
+	all, err := provider.ListPullRequests("org", repo, PullRequestStateAll)
+	assert.NoError(t, err)
+	assert.Len(t, all, 4)
+}
+
+func TestFilterPullRequestsByAuthor(t *testing.T) {
+	t.Parallel()
+
+	prs := []*PullRequest{
+		{Title: "by the bot", Author: &User{Login: "jenkins-x-bot"}},
+		{Title: "by someone else", Author: &User{Login: "someone"}},
+		{Title: "without an author"},
+		{Title: "by the bot again", Author: &User{Login: "Jenkins-X-Bot"}},
+	}
+
+	found := FilterPullRequestsByAuthor(prs, "jenkins-x-bot")
+	assert.Len(t, found, 2)
+	assert.Equal(t, "by the bot", found[0].Title)
+	assert.Equal(t, "by the bot again", found[1].Title)
+
+	assert.Empty(t, FilterPullRequestsByAuthor(prs, "nobody"))
+}
+
+func TestGetPullRequestByBranch(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFakeProvider(NewFakeRepository("org", "environment-staging"))
+	repo := &Repository{Organisation: "org", Name: "environment-staging"}
+	for _, head := range []string{"promote-1.0.1", "promote-1.0.2"} {
+		_, err := provider.CreatePullRequest(&PullRequestArguments{
+			Repository: repo,
+			Title:      "Promote",
+			Head:       head,
+			Base:       "master",
+		})
+		assert.NoError(t, err)
+	}
+
+	pr, err := provider.GetPullRequestByBranch("org", repo, "promote-1.0.2")
+	assert.NoError(t, err)
+	if assert.NotNil(t, pr) {
+		assert.Equal(t, 2, *pr.Number)
+	}
+
+	pr, err = provider.GetPullRequestByBranch("org", repo, "promote-1.0.3")
+	assert.NoError(t, err)
+	assert.Nil(t, pr)
+}
+
+func TestCountDiffLines(t *testing.T) {
+	t.Parallel()
+
+	diff := `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1,3 +1,3 @@
+ # Title
+--- a rule
++***
++Some more text
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -10 +10,0 @@
+-	return nil
+`
+	additions, deletions := CountDiffLines(diff)
+	assert.Equal(t, 2, additions)
+	assert.Equal(t, 2, deletions)
+
+	// gitlab returns the hunks of each file without the headers
+	additions, deletions = CountDiffLines("@@ -1 +1 @@\n-old\n+new\n")
+	assert.Equal(t, 1, additions)
+	assert.Equal(t, 1, deletions)
+}
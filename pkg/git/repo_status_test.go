@@ -0,0 +1,88 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupStatuses(t *testing.T) {
+	t.Parallel()
+	older := time.Date(2018, 4, 2, 1, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	statuses := []*RepoStatus{
+		{ID: "1", Context: "ci", State: "failure", CreatedAt: &older},
+		{ID: "2", Context: "ci", State: "success", CreatedAt: &newer},
+		{ID: "3", Context: "lint", State: "pending"},
+		{ID: "4", Context: "lint", State: "failure"},
+	}
+
+	latest := RollupStatuses(statuses)
+	assert.Len(t, latest, 2)
+	assert.Equal(t, "2", latest["ci"].ID)
+	// without timestamps the first status listed is the newest
+	assert.Equal(t, "3", latest["lint"].ID)
+
+	assert.Equal(t, "2", FindRepoStatus(statuses, "ci").ID)
+	assert.Nil(t, FindRepoStatus(statuses, "missing"))
+}
+
+func TestOverallState(t *testing.T) {
+	t.Parallel()
+	older := time.Date(2018, 4, 2, 1, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	assert.Equal(t, "pending", OverallState(nil))
+	assert.Equal(t, "success", OverallState([]*RepoStatus{
+		{Context: "ci", State: "failure", CreatedAt: &older},
+		{Context: "ci", State: "success", CreatedAt: &newer},
+	}))
+	assert.Equal(t, "pending", OverallState([]*RepoStatus{
+		{Context: "ci", State: "success"},
+		{Context: "lint", State: "in-progress"},
+	}))
+	assert.Equal(t, "failure", OverallState([]*RepoStatus{
+		{Context: "ci", State: "success"},
+		{Context: "lint", State: "pending"},
+		{Context: "e2e", State: "failed"},
+	}))
+}
+
+func TestNormalizeContext(t *testing.T) {
+	t.Parallel()
+
+	status := &RepoStatus{Context: "  ci/release/build "}
+	assert.NoError(t, status.NormalizeContext(MaxStatusContextLength))
+	assert.Equal(t, "ci/release/build", status.Context)
+
+	assert.Error(t, (&RepoStatus{Context: " "}).NormalizeContext(MaxStatusContextLength))
+	assert.Error(t, (&RepoStatus{Context: "ci/release/build"}).NormalizeContext(10))
+	// the limit counts characters rather than bytes
+	assert.NoError(t, (&RepoStatus{Context: "ci/réléase"}).NormalizeContext(10))
+}
+
+func TestUpdateCommitStatusContexts(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFakeProvider(NewFakeRepository("org", "repo"))
+	sha := "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+
+	_, err := provider.UpdateCommitStatus("org", "repo", sha, &RepoStatus{Context: "ci/build", State: "pending"})
+	assert.NoError(t, err)
+	_, err = provider.UpdateCommitStatus("org", "repo", sha, &RepoStatus{Context: "ci/lint", State: "success"})
+	assert.NoError(t, err)
+	// the same context replaces the status
+	_, err = provider.UpdateCommitStatus("org", "repo", sha, &RepoStatus{Context: "ci/build", State: "success"})
+	assert.NoError(t, err)
+
+	statuses, err := provider.ListCommitStatus("org", "repo", sha)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "success", FindRepoStatus(statuses, "ci/build").State)
+	assert.Equal(t, "success", FindRepoStatus(statuses, "ci/lint").State)
+
+	_, err = provider.UpdateCommitStatus("org", "repo", sha, &RepoStatus{State: "success"})
+	assert.Error(t, err)
+}
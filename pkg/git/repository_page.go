@@ -0,0 +1,35 @@
+package git
+
+// ListOptions selects a page of a list. Providers which page by number read Page, and providers which page
+// from an offset or token read Cursor, which is the NextCursor of the page before. PerPage is the size of
+// the pages, or 0 for the default of the provider
+type ListOptions struct {
+	Page    int
+	PerPage int
+	Cursor  string
+}
+
+// RepositoryPage is a page of repositories. If HasMore is true the next page is listed with NextCursor
+type RepositoryPage struct {
+	Repositories []*Repository
+	NextCursor   string
+	HasMore      bool
+}
+
+// ListAllRepositories lists the pages of repositories from the one selected by options on, following the
+// cursor of each page to the next, and returns the repositories of all of them
+func ListAllRepositories(options ListOptions, listPage func(options ListOptions) (*RepositoryPage, error)) ([]*Repository, error) {
+	answer := []*Repository{}
+	for {
+		page, err := listPage(options)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, page.Repositories...)
+		if !page.HasMore || page.NextCursor == options.Cursor {
+			return answer, nil
+		}
+		options.Page = 0
+		options.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAllRepositories(t *testing.T) {
+	t.Parallel()
+
+	cursors := []string{}
+	repos, err := ListAllRepositories(ListOptions{PerPage: 2}, func(options ListOptions) (*RepositoryPage, error) {
+		assert.Equal(t, 2, options.PerPage)
+		cursors = append(cursors, options.Cursor)
+		switch options.Cursor {
+		case "":
+			return &RepositoryPage{Repositories: []*Repository{{Name: "a"}, {Name: "b"}}, NextCursor: "2", HasMore: true}, nil
+		case "2":
+			return &RepositoryPage{Repositories: []*Repository{{Name: "c"}}}, nil
+		}
+		return nil, fmt.Errorf("unexpected cursor %s", options.Cursor)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "2"}, cursors)
+	assert.Len(t, repos, 3)
+	assert.Equal(t, "c", repos[2].Name)
+
+	_, err = ListAllRepositories(ListOptions{}, func(options ListOptions) (*RepositoryPage, error) {
+		return nil, fmt.Errorf("failed")
+	})
+	assert.Error(t, err)
+}
+
+func TestFakeProviderListRepositoriesPaged(t *testing.T) {
+	t.Parallel()
+
+	provider := &FakeProvider{Repositories: map[string][]*FakeRepository{}}
+	for _, name := range []string{"a", "b", "c"} {
+		provider.Repositories["org"] = append(provider.Repositories["org"], &FakeRepository{GitRepo: &Repository{Name: name}})
+	}
+
+	page, err := provider.ListRepositoriesPaged("org", ListOptions{PerPage: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Repositories, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "2", page.NextCursor)
+
+	page, err = provider.ListRepositoriesPaged("org", ListOptions{PerPage: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Repositories, 1)
+	assert.Equal(t, "c", page.Repositories[0].Name)
+	assert.False(t, page.HasMore)
+
+	repos, err := ListAllRepositories(ListOptions{PerPage: 2}, func(options ListOptions) (*RepositoryPage, error) {
+		return provider.ListRepositoriesPaged("org", options)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, repos, 3)
+}
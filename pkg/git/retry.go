@@ -0,0 +1,132 @@
+package git
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryConfig is how providers retry requests unless WithRetryConfig is used
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries:     3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// RetryConfig is how often a request which failed with a transient error is retried. The wait doubles after every
+// attempt, starting from InitialBackoff, unless the provider asks for a wait with a Retry-After header. Waits longer
+// than MaxBackoff are not made, so a provider which is rate limited for an hour fails straight away
+type RetryConfig struct {
+	// MaxRetries is the number of times a request is retried. Zero disables retrying
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// RetryableRoundTripper retries requests which fail with a 429 Too Many Requests or a 5xx response. Requests which
+// are not idempotent are only retried after a 429 or a 503, which tell they have not been processed
+type RetryableRoundTripper struct {
+	Transport http.RoundTripper
+	Config    RetryConfig
+}
+
+// NewRetryableRoundTripper returns a round tripper retrying the requests of the transport, or of the default
+// transport if it is nil
+func NewRetryableRoundTripper(transport http.RoundTripper, config RetryConfig) *RetryableRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &RetryableRoundTripper{
+		Transport: transport,
+		Config:    config,
+	}
+}
+
+func (t *RetryableRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.Config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Transport.RoundTrip(req)
+		if err != nil || attempt >= t.Config.MaxRetries || !isRetryable(req, resp) {
+			return resp, err
+		}
+		wait := backoff
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			wait = retryAfter
+		}
+		if wait > t.Config.MaxBackoff {
+			return resp, nil
+		}
+		// the body of a request is read by the attempt, so retrying needs a fresh copy
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		drain(resp.Body)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+		if backoff > t.Config.MaxBackoff {
+			backoff = t.Config.MaxBackoff
+		}
+	}
+}
+
+// isRetryable returns true if the response reports a transient error worth retrying the request for
+func isRetryable(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	if resp.StatusCode < 500 {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter returns the wait asked for by a Retry-After header, which is either a number of seconds or a date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// drain reads the rest of the body so the connection can be reused, and closes it
+func drain(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	body.Close()
+}
@@ -0,0 +1,165 @@
+package git
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryConfig = RetryConfig{
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// failingServer responds with the status for the given number of requests, and then with 200 and the body of the
+// request
+func failingServer(status, failures int, header http.Header) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&requests, 1)) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	return server, &requests
+}
+
+func TestRetryableRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	server, requests := failingServer(http.StatusServiceUnavailable, 2, nil)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryableRoundTripper(nil, testRetryConfig)}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestRetryableRoundTripperGivesUp(t *testing.T) {
+	t.Parallel()
+
+	server, requests := failingServer(http.StatusBadGateway, 10, nil)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryableRoundTripper(nil, testRetryConfig)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestRetryableRoundTripperDoesNotRetry(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{"client error", http.MethodGet, http.StatusNotFound},
+		{"not implemented", http.MethodGet, http.StatusNotImplemented},
+		{"post which may have been processed", http.MethodPost, http.StatusBadGateway},
+	}
+	for _, tc := range testCases {
+		server, requests := failingServer(tc.status, 1, nil)
+
+		req, err := http.NewRequest(tc.method, server.URL, nil)
+		require.NoError(t, err)
+		client := &http.Client{Transport: NewRetryableRoundTripper(nil, testRetryConfig)}
+		resp, err := client.Do(req)
+		require.NoError(t, err, tc.name)
+		resp.Body.Close()
+		server.Close()
+
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests), tc.name)
+	}
+}
+
+func TestRetryableRoundTripperRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	server, requests := failingServer(http.StatusTooManyRequests, 1, http.Header{"Retry-After": {"1"}})
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryableRoundTripper(nil, testRetryConfig)}
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+	assert.True(t, time.Since(start) >= time.Second)
+
+	// waits longer than the maximum backoff are not made
+	server, requests = failingServer(http.StatusTooManyRequests, 1, http.Header{"Retry-After": {"3600"}})
+	defer server.Close()
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	wait, ok := parseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestProviderOptionsRetry(t *testing.T) {
+	t.Parallel()
+
+	server, requests := failingServer(http.StatusServiceUnavailable, 2, nil)
+	defer server.Close()
+
+	assert.Equal(t, DefaultRetryConfig, NewProviderOptions().Retry)
+
+	resp, err := NewProviderOptions(WithRetryConfig(testRetryConfig)).NewHTTPClient().Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// retrying can be disabled
+	atomic.StoreInt32(requests, 0)
+	resp, err = NewProviderOptions(WithRetryConfig(RetryConfig{})).NewHTTPClient().Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
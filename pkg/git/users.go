@@ -0,0 +1,113 @@
+package git
+
+import (
+	"sync"
+)
+
+// maxConcurrentUserLookups bounds how many users GetUsers looks up at once
+const maxConcurrentUserLookups = 8
+
+// UserCache remembers the users a provider has looked up, so that listing pull requests, commits or reviews only
+// looks each of their users up once. Providers whose API can look up a set of users in one request use
+// GetUsersBatch, the others look users up one by one with GetUsers, which runs a few of those lookups at a time.
+// The zero value is an empty cache, and a nil cache only remembers users for a single call
+type UserCache struct {
+	lock  sync.Mutex
+	users map[string]*User
+}
+
+// GetUsers returns the users with the given usernames, keyed by username. Users which aren't cached are looked up
+// with getUser a few at a time and cached. getUser returns nil for users which don't exist, which are left out, and
+// the first error it returns is returned once the other lookups are done
+func (c *UserCache) GetUsers(usernames []string, getUser func(username string) (*User, error)) (map[string]*User, error) {
+	if c == nil {
+		c = &UserCache{}
+	}
+	answer, missing := c.cached(usernames)
+
+	found := make([]*User, len(missing))
+	errs := make([]error, len(missing))
+	limit := make(chan struct{}, maxConcurrentUserLookups)
+	var wg sync.WaitGroup
+	for i, username := range missing {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int, username string) {
+			defer wg.Done()
+			found[i], errs[i] = getUser(username)
+			<-limit
+		}(i, username)
+	}
+	wg.Wait()
+
+	var err error
+	users := map[string]*User{}
+	for i, username := range missing {
+		if errs[i] != nil && err == nil {
+			err = errs[i]
+		}
+		if found[i] != nil {
+			users[username] = found[i]
+		}
+	}
+	c.add(answer, missing, users)
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// GetUsersBatch returns the users with the given usernames, keyed by username. Users which aren't cached are looked
+// up with a single call to getUsers and cached. getUsers leaves out the users which don't exist
+func (c *UserCache) GetUsersBatch(usernames []string, getUsers func(usernames []string) (map[string]*User, error)) (map[string]*User, error) {
+	if c == nil {
+		c = &UserCache{}
+	}
+	answer, missing := c.cached(usernames)
+	if len(missing) == 0 {
+		return answer, nil
+	}
+
+	users, err := getUsers(missing)
+	if err != nil {
+		return nil, err
+	}
+	c.add(answer, missing, users)
+	return answer, nil
+}
+
+// cached returns the cached users with the given usernames, and the distinct usernames which aren't cached
+func (c *UserCache) cached(usernames []string) (map[string]*User, []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	answer := map[string]*User{}
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, username := range usernames {
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		if user, ok := c.users[username]; ok {
+			answer[username] = user
+		} else {
+			missing = append(missing, username)
+		}
+	}
+	return answer, missing
+}
+
+// add caches the users found for the missing usernames and adds them to the answer
+func (c *UserCache) add(answer map[string]*User, missing []string, found map[string]*User) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.users == nil {
+		c.users = map[string]*User{}
+	}
+	for _, username := range missing {
+		if user := found[username]; user != nil {
+			c.users[username] = user
+			answer[username] = user
+		}
+	}
+}
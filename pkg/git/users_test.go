@@ -0,0 +1,123 @@
+package git
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCacheGetUsers(t *testing.T) {
+	t.Parallel()
+
+	var lock sync.Mutex
+	lookups := map[string]int{}
+	getUser := func(username string) (*User, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		lookups[username]++
+		if username == "missing" {
+			return nil, nil
+		}
+		return &User{Login: username, Email: username + "@example.com"}, nil
+	}
+
+	cache := &UserCache{}
+	users, err := cache.GetUsers([]string{"alice", "bob", "alice", "missing"}, getUser)
+
+	assert.NoError(t, err)
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, "alice@example.com", users["alice"].Email)
+	assert.Equal(t, "bob@example.com", users["bob"].Email)
+	assert.NotContains(t, users, "missing")
+	assert.Equal(t, map[string]int{"alice": 1, "bob": 1, "missing": 1}, lookups)
+
+	// cached users aren't looked up again
+	users, err = cache.GetUsers([]string{"alice", "carol"}, getUser)
+
+	assert.NoError(t, err)
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, map[string]int{"alice": 1, "bob": 1, "carol": 1, "missing": 1}, lookups)
+
+	// a nil cache still looks each user up once
+	var nilCache *UserCache
+	users, err = nilCache.GetUsers([]string{"dave", "dave"}, getUser)
+
+	assert.NoError(t, err)
+
+	assert.Len(t, users, 1)
+	assert.Equal(t, 1, lookups["dave"])
+}
+
+func TestUserCacheGetUsersFailing(t *testing.T) {
+	t.Parallel()
+
+	getUser := func(username string) (*User, error) {
+		if username == "broken" {
+			return nil, errors.New("server error")
+		}
+		return &User{Login: username}, nil
+	}
+
+	cache := &UserCache{}
+	users, err := cache.GetUsers([]string{"alice", "broken"}, getUser)
+
+	assert.EqualError(t, err, "server error")
+	assert.Nil(t, users)
+
+	// the users which were found are still cached
+	users, err = cache.GetUsers([]string{"alice"}, func(username string) (*User, error) {
+		return nil, errors.New("looked up again")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", users["alice"].Login)
+}
+
+func TestUserCacheGetUsersBatch(t *testing.T) {
+	t.Parallel()
+
+	batches := [][]string{}
+	getUsers := func(usernames []string) (map[string]*User, error) {
+		batches = append(batches, usernames)
+		answer := map[string]*User{}
+		for _, username := range usernames {
+			if username != "missing" {
+				answer[username] = &User{Login: username}
+			}
+		}
+		return answer, nil
+	}
+
+	cache := &UserCache{}
+	users, err := cache.GetUsersBatch([]string{"alice", "bob", "alice", "missing"}, getUsers)
+
+	assert.NoError(t, err)
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, "alice", users["alice"].Login)
+	assert.NotContains(t, users, "missing")
+	assert.Equal(t, [][]string{{"alice", "bob", "missing"}}, batches)
+
+	// only the users which aren't cached are looked up, and not at all once they all are
+	users, err = cache.GetUsersBatch([]string{"alice", "carol"}, getUsers)
+
+	assert.NoError(t, err)
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, [][]string{{"alice", "bob", "missing"}, {"carol"}}, batches)
+
+	_, err = cache.GetUsersBatch([]string{"bob", "carol"}, getUsers)
+
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+
+	_, err = cache.GetUsersBatch([]string{"dave"}, func(usernames []string) (map[string]*User, error) {
+		return nil, errors.New("server error")
+	})
+
+	assert.EqualError(t, err, "server error")
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Validate checks the arguments have what every provider needs to create a webhook, returning an error naming
+// the property which is missing or malformed. It also fills in whichever of Owner and Repo.Organisation is blank
+// from the other, as providers read one or the other
+func (data *WebhookArguments) Validate() error {
+	if data.Repo == nil || data.Repo.Name == "" {
+		return fmt.Errorf("invalid webhook arguments: missing property Repo")
+	}
+	if err := data.ValidateURL(); err != nil {
+		return err
+	}
+	if data.Owner == "" {
+		data.Owner = data.Repo.Organisation
+	}
+	if data.Repo.Organisation == "" {
+		data.Repo.Organisation = data.Owner
+	}
+	return nil
+}
+
+// ValidateURL checks the URL of the webhook is an absolute URL. It is all there is to validate for the webhooks
+// of groups, which have no repository
+func (data *WebhookArguments) ValidateURL() error {
+	if data.URL == "" {
+		return fmt.Errorf("invalid webhook arguments: missing property URL")
+	}
+	u, err := url.Parse(data.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("invalid webhook arguments: URL %s is not an absolute URL", data.URL)
+	}
+	return nil
+}
+
+// RepositoryErrors are the errors of an operation run on several repositories, keyed by owner/name
+type RepositoryErrors map[string]error
+
+func (e RepositoryErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, e[name]))
+	}
+	return "repositories failed: " + strings.Join(messages, "; ")
+}
+
+// RotateWebHookSecrets sets the secret of the Jenkins X webhook of every repository, which is the webhook whose URL
+// ends with the JenkinsWebHookPath of the provider. A repository which fails doesn't stop the others being updated,
+// the failures are returned together as RepositoryErrors
+func RotateWebHookSecrets(provider Provider, owner string, repos []*Repository, newSecret string) error {
+	errs := RepositoryErrors{}
+	for _, repo := range repos {
+		if err := rotateWebHookSecret(provider, owner, repo, newSecret); err != nil {
+			errs[owner+"/"+repo.Name] = err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func rotateWebHookSecret(provider Provider, owner string, repo *Repository, newSecret string) error {
+	path := strings.TrimSuffix(provider.JenkinsWebHookPath(repo.URL, newSecret), "/")
+	if path == "" {
+		return fmt.Errorf("find the Jenkins X webhook: %w", ErrNotSupported)
+	}
+	hooks, err := provider.ListWebHooks(owner, repo.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list the webhooks: %s", err)
+	}
+	updated := 0
+	for _, hook := range hooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || !strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), path) {
+			continue
+		}
+		// providers don't fill in the repository of the webhooks they list
+		data := *hook
+		data.Owner = owner
+		data.Repo = repo
+		data.Secret = newSecret
+		if err := provider.UpdateWebHook(&data); err != nil {
+			return fmt.Errorf("failed to update the webhook %s: %s", hook.URL, err)
+		}
+		updated++
+	}
+	if updated == 0 {
+		return fmt.Errorf("Jenkins X webhook: %w", ErrNotFound)
+	}
+	return nil
+}
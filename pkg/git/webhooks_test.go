@@ -0,0 +1,94 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookArgumentsValidate(t *testing.T) {
+	t.Parallel()
+
+	data := &WebhookArguments{
+		Repo: &Repository{Organisation: "org", Name: "repo"},
+		URL:  "https://jenkins.example.com/hook/",
+	}
+	assert.NoError(t, data.Validate())
+	assert.Equal(t, "org", data.Owner)
+
+	data = &WebhookArguments{
+		Owner: "owner",
+		Repo:  &Repository{Name: "repo"},
+		URL:   "https://jenkins.example.com/hook/",
+	}
+	assert.NoError(t, data.Validate())
+	assert.Equal(t, "owner", data.Repo.Organisation)
+
+	testCases := []struct {
+		data     *WebhookArguments
+		expected string
+	}{
+		{&WebhookArguments{URL: "https://jenkins.example.com/hook/"}, "missing property Repo"},
+		{&WebhookArguments{Repo: &Repository{}, URL: "https://jenkins.example.com/hook/"}, "missing property Repo"},
+		{&WebhookArguments{Repo: &Repository{Name: "repo"}}, "missing property URL"},
+		{&WebhookArguments{Repo: &Repository{Name: "repo"}, URL: "/hook/"}, "URL /hook/ is not an absolute URL"},
+		{&WebhookArguments{Repo: &Repository{Name: "repo"}, URL: "jenkins.example.com/hook/"}, "is not an absolute URL"},
+	}
+	for _, tc := range testCases {
+		err := tc.data.Validate()
+		if assert.Error(t, err, tc.expected) {
+			assert.Contains(t, err.Error(), tc.expected)
+		}
+	}
+}
+
+func TestWebhookArgumentsValidateURL(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, (&WebhookArguments{URL: "https://jenkins.example.com/hook/"}).ValidateURL())
+	assert.EqualError(t, (&WebhookArguments{}).ValidateURL(), "invalid webhook arguments: missing property URL")
+	assert.EqualError(t, (&WebhookArguments{URL: "/hook/"}).ValidateURL(), "invalid webhook arguments: URL /hook/ is not an absolute URL")
+}
+
+func TestRotateWebHookSecrets(t *testing.T) {
+	t.Parallel()
+
+	provider, repo := newTestGitFakeProvider(t)
+	other, err := provider.CreateRepository("test-org", "other-repo", false)
+	require.NoError(t, err)
+	other.Organisation = "test-org"
+	unhooked, err := provider.CreateRepository("test-org", "unhooked-repo", false)
+	require.NoError(t, err)
+	unhooked.Organisation = "test-org"
+
+	hooks := []*WebhookArguments{
+		{Repo: repo, URL: "https://jenkins.example.com/fake-webhook/", Secret: "old"},
+		{Repo: repo, URL: "https://chat.example.com/notify", Secret: "chat"},
+		{Repo: other, URL: "https://jenkins.example.com/fake-webhook", Secret: "old"},
+	}
+	for _, hook := range hooks {
+		require.NoError(t, provider.CreateWebHook(hook))
+	}
+
+	err = RotateWebHookSecrets(provider, "test-org", []*Repository{repo, unhooked, other}, "new")
+	require.Error(t, err)
+	errs, ok := err.(RepositoryErrors)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.True(t, IsNotFound(errs["test-org/unhooked-repo"]))
+	assert.Contains(t, err.Error(), "repositories failed: test-org/unhooked-repo: Jenkins X webhook: not found")
+
+	// the repositories after the failing one are updated too, and other webhooks are left alone
+	secrets := map[string]string{}
+	for _, hook := range provider.WebHooks {
+		secrets[hook.Repo.Name+" "+hook.URL] = hook.Secret
+	}
+	assert.Equal(t, map[string]string{
+		"test-repo https://jenkins.example.com/fake-webhook/": "new",
+		"test-repo https://chat.example.com/notify":           "chat",
+		"other-repo https://jenkins.example.com/fake-webhook": "new",
+	}, secrets)
+
+	assert.NoError(t, RotateWebHookSecrets(provider, "test-org", []*Repository{repo, other}, "newer"))
+}
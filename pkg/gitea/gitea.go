@@ -1,36 +1,62 @@
 package gitea
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"code.gitea.io/sdk/gitea"
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/wbrefvem/go-gits/pkg/git"
 )
 
+// pageSize is how many items are listed per page, which is the most Gitea returns by default
+const pageSize = 50
+
 type GiteaProvider struct {
 	Username string
 	Client   *gitea.Client
 	URL      string
 	Git      git.Gitter
 	Name     string
+	Options  git.ProviderOptions
+	// ForkPollConfig is how ForkRepository polls for the fork
+	ForkPollConfig git.ForkPollConfig
+
+	token string
+	// users caches the users looked up by GetUsers
+	users *git.UserCache
+}
+
+func init() {
+	git.RegisterProviderFactory(git.KindGitea, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, git.KindGitea, gitter, options...)
+	})
 }
 
-func NewProvider(username, serverURL, token, providerName string, git git.Gitter) (git.Provider, error) {
-	client := gitea.NewClient(serverURL, token)
+func NewProvider(username, serverURL, token, providerName string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	// the client appends the API path to the server URL, which must not end with a slash
+	client := gitea.NewClient(git.NormalizeServerURL(serverURL), token)
 
 	provider := GiteaProvider{
 		Client:   client,
 		Username: username,
-		Git:      git,
+		URL:      serverURL,
+		Git:      gitter,
 		Name:     providerName,
+		Options:  git.NewProviderOptions(options...),
+		token:    token,
+		users:    &git.UserCache{},
 	}
+	provider.ForkPollConfig = git.DefaultForkPollConfig
+	client.SetHTTPClient(provider.Options.NewHTTPClient())
 
 	return &provider, nil
 }
@@ -54,6 +80,24 @@ func (p *GiteaProvider) ListOrganisations() ([]git.Organisation, error) {
 	return answer, nil
 }
 
+func (p *GiteaProvider) ListTeams(org string) ([]*git.Team, error) {
+	answer := []*git.Team{}
+	teams, err := p.Client.ListOrgTeams(org)
+	if err != nil {
+		return answer, err
+	}
+
+	for _, team := range teams {
+		// gitea identifies teams by their name
+		answer = append(answer, &git.Team{
+			Name:       team.Name,
+			Slug:       team.Name,
+			Permission: string(team.Permission),
+		})
+	}
+	return answer, nil
+}
+
 func (p *GiteaProvider) ListRepositories(org string) ([]*git.Repository, error) {
 	answer := []*git.Repository{}
 	if org == "" {
@@ -76,6 +120,22 @@ func (p *GiteaProvider) ListRepositories(org string) ([]*git.Repository, error)
 	return answer, nil
 }
 
+func (p *GiteaProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	return nil, fmt.Errorf("list repositories paged: %w", git.ErrNotSupported)
+}
+
+func (p *GiteaProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	answer := []*git.Repository{}
+	repos, err := p.Client.ListMyRepos()
+	if err != nil {
+		return answer, err
+	}
+	for _, repo := range repos {
+		answer = append(answer, toGiteaRepo(repo.Name, repo))
+	}
+	return answer, nil
+}
+
 func (p *GiteaProvider) ListReleases(org string, name string) ([]*git.Release, error) {
 	owner := org
 	if owner == "" {
@@ -116,7 +176,7 @@ func toGiteaRelease(org string, name string, release *gitea.Release) *git.Releas
 func (p *GiteaProvider) CreateRepository(org string, name string, private bool) (*git.Repository, error) {
 	options := gitea.CreateRepoOption{
 		Name:    name,
-		Private: private,
+		Private: p.Options.IsPrivate(private),
 	}
 	repo, err := p.Client.CreateRepo(options)
 	if err != nil {
@@ -128,9 +188,37 @@ func (p *GiteaProvider) CreateRepository(org string, name string, private bool)
 func (p *GiteaProvider) GetRepository(org string, name string) (*git.Repository, error) {
 	repo, err := p.Client.GetRepo(org, name)
 	if err != nil {
+		if strings.Contains(err.Error(), "404") {
+			return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+		}
 		return nil, fmt.Errorf("Failed to get repository %s/%s due to: %s", org, name, err)
 	}
-	return toGiteaRepo(name, repo), nil
+	answer := toGiteaRepo(name, repo)
+	hasIssues, err := p.hasIssuesEnabled(org, name)
+	if err != nil {
+		return nil, err
+	}
+	answer.HasIssuesEnabled = hasIssues
+	return answer, nil
+}
+
+// hasIssuesEnabled reads has_issues from the repository API, which the client doesn't support.
+// Servers too old to report it have no way to turn issues off so they are enabled
+func (p *GiteaProvider) hasIssuesEnabled(org string, name string) (bool, error) {
+	repo := struct {
+		HasIssues *bool `json:"has_issues"`
+	}{}
+	status, err := p.getJSON(util.UrlJoin("/repos", org, name), &repo)
+	if err != nil {
+		return false, err
+	}
+	if status >= 300 {
+		return false, fmt.Errorf("Could not get repository %s/%s: %d", org, name, status)
+	}
+	if repo.HasIssues == nil {
+		return true, nil
+	}
+	return *repo.HasIssues, nil
 }
 
 func (p *GiteaProvider) DeleteRepository(org string, name string) error {
@@ -145,15 +233,26 @@ func (p *GiteaProvider) DeleteRepository(org string, name string) error {
 	return err
 }
 
+// toGiteaRepo converts a client repository, leaving HasIssuesEnabled unset as the client doesn't expose it.
+// Gitea doesn't record pushes on repositories so PushedAt is left unset too
 func toGiteaRepo(name string, repo *gitea.Repository) *git.Repository {
-	return &git.Repository{
+	answer := &git.Repository{
 		Name:             name,
 		AllowMergeCommit: true,
 		CloneURL:         repo.CloneURL,
 		HTMLURL:          repo.HTMLURL,
 		SSHURL:           repo.SSHURL,
 		Fork:             repo.Fork,
+		Description:      repo.Description,
+		DefaultBranch:    repo.DefaultBranch,
+		Private:          repo.Private,
+		Size:             repo.Size,
 	}
+	if !repo.Updated.IsZero() {
+		updated := repo.Updated
+		answer.UpdatedAt = &updated
+	}
+	return answer
 }
 
 func (p *GiteaProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*git.Repository, error) {
@@ -173,18 +272,13 @@ func (p *GiteaProvider) ForkRepository(originalOrg string, name string, destinat
 		if strings.Contains(err.Error(), "try again later") {
 			log.Warnf("Waiting for the fork of %s/%s to appear...\n", owner, name)
 			// lets wait for the fork to occur...
-			start := time.Now()
-			deadline := start.Add(time.Minute)
-			for {
-				time.Sleep(5 * time.Second)
+			err = p.ForkPollConfig.Poll(p.Options, func() error {
+				var err error
 				repo, err = p.Client.GetRepo(owner, name)
-				if repo != nil && err == nil {
-					break
-				}
-				t := time.Now()
-				if t.After(deadline) {
-					return nil, fmt.Errorf("Gave up waiting for Repository %s/%s to appear: %s", owner, name, err)
-				}
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Gave up waiting for Repository %s/%s to appear: %s", owner, name, err)
 			}
 		} else {
 			return nil, fmt.Errorf("Failed to fork repository %s/%s%s due to: %s", originalOrg, name, msg, err)
@@ -193,19 +287,21 @@ func (p *GiteaProvider) ForkRepository(originalOrg string, name string, destinat
 	return toGiteaRepo(name, repo), nil
 }
 
+// SetForkPollConfig sets how often ForkRepository checks whether the fork is ready and how long it waits for it
+func (p *GiteaProvider) SetForkPollConfig(config git.ForkPollConfig) {
+	p.ForkPollConfig = config
+}
+
 func (p *GiteaProvider) CreateWebHook(data *git.WebhookArguments) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
 	owner := data.Owner
 	if owner == "" {
 		owner = p.Username
 	}
 	repo := data.Repo.Name
-	if repo == "" {
-		return fmt.Errorf("Missing property Repo")
-	}
 	webhookUrl := data.URL
-	if repo == "" {
-		return fmt.Errorf("Missing property URL")
-	}
 	hooks, err := p.Client.ListRepoHooks(owner, repo)
 	if err != nil {
 		return err
@@ -247,6 +343,19 @@ func (p *GiteaProvider) UpdateWebHook(data *git.WebhookArguments) error {
 	return fmt.Errorf("not implemented!")
 }
 
+func (p *GiteaProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	return nil, fmt.Errorf("list webhook deliveries: %w", git.ErrNotSupported)
+}
+
+func (p *GiteaProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return fmt.Errorf("redeliver webhook: %w", git.ErrNotSupported)
+}
+
+func (p *GiteaProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	// the server can test hooks but the client has no call for it yet
+	return fmt.Errorf("ping webhook: %w", git.ErrNotSupported)
+}
+
 func (p *GiteaProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.PullRequest, error) {
 	owner := data.Repository.Organisation
 	repo := data.Repository.Name
@@ -254,6 +363,10 @@ func (p *GiteaProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.
 	body := data.Body
 	head := data.Head
 	base := data.Base
+	// pull requests are works in progress while their title starts with WIP:
+	if data.Draft && !strings.HasPrefix(title, "WIP:") {
+		title = "WIP: " + title
+	}
 	config := gitea.CreatePullRequestOption{}
 	if title != "" {
 		config.Title = title
@@ -267,10 +380,17 @@ func (p *GiteaProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.
 	if base != "" {
 		config.Base = base
 	}
+	config.Assignees = data.Assignees
 	pr, err := p.Client.CreatePullRequest(owner, repo, config)
 	if err != nil {
 		return nil, err
 	}
+	if len(data.Reviewers) > 0 {
+		err = p.requestReviewers(owner, repo, pr.Index, data.Reviewers)
+		if err != nil {
+			log.Warnf("%s\n", err)
+		}
+	}
 	id := int(pr.Index)
 	answer := &git.PullRequest{
 		URL:    pr.HTMLURL,
@@ -284,6 +404,26 @@ func (p *GiteaProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.
 	return answer, nil
 }
 
+// requestReviewers requests reviews of the pull request, unless the server is older than Gitea 1.14 and has no
+// review requests
+func (p *GiteaProvider) requestReviewers(owner string, repo string, number int64, reviewers []string) error {
+	body := map[string][]string{"reviewers": reviewers}
+	u := util.UrlJoin("/repos", owner, repo, "pulls", strconv.FormatInt(number, 10), "requested_reviewers")
+	resp, err := p.do(http.MethodPost, u, body)
+	if err != nil {
+		return fmt.Errorf("Could not request reviews of pull request %d of %s/%s: %s", number, owner, repo, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		log.Warnf("Gitea at %s doesn't support review requests so no reviews of %s/%s#%d are requested\n", p.URL, owner, repo, number)
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Could not request reviews of pull request %d of %s/%s: %d", number, owner, repo, resp.StatusCode)
+	}
+	return nil
+}
+
 func (p *GiteaProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	if pr.Number == nil {
 		return fmt.Errorf("Missing Number for PullRequest %#v", pr)
@@ -293,6 +433,13 @@ func (p *GiteaProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	if err != nil {
 		return fmt.Errorf("Could not find pull request for %s/%s #%d: %s", pr.Owner, pr.Repo, n, err)
 	}
+	p.updatePullRequest(pr, result)
+	return nil
+}
+
+// updatePullRequest copies the fields of a gitea pull request into the git pull request
+func (p *GiteaProvider) updatePullRequest(pr *git.PullRequest, result *gitea.PullRequest) {
+	n := *pr.Number
 	pr.Author = &git.User{
 		Login: result.Poster.UserName,
 	}
@@ -308,18 +455,27 @@ func (p *GiteaProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	head := result.Head
 	if head != nil {
 		pr.LastCommitSha = head.Sha
+		pr.HeadRef = &head.Ref
 	} else {
 		pr.LastCommitSha = ""
 	}
-	/*
-		TODO
-
-		pr.ClosedAt = result.Closed
-		pr.StatusesURL = result.StatusesURL
-		pr.IssueURL = result.IssueURL
-		pr.DiffURL = result.DiffURL
-	*/
-	return nil
+	if result.Base != nil {
+		pr.BaseRef = &result.Base.Ref
+	}
+	labels := []git.Label{}
+	for _, label := range result.Labels {
+		labels = append(labels, toGiteaLabel(label))
+	}
+	pr.Labels = labels
+	pr.ClosedAt = result.Closed
+	pr.DiffURL = &result.DiffURL
+	issueURL := p.IssueURL(pr.Owner, pr.Repo, n, false)
+	pr.IssueURL = &issueURL
+	if pr.LastCommitSha != "" {
+		// gitea has no statuses link on the pull request so point at the statuses API for the head commit
+		statusesURL := util.UrlJoin(p.ServerURL(), "api/v1/repos", pr.Owner, pr.Repo, "statuses", pr.LastCommitSha)
+		pr.StatusesURL = &statusesURL
+	}
 }
 
 func (p *GiteaProvider) GetPullRequest(owner string, repo *git.Repository, number int) (*git.PullRequest, error) {
@@ -341,14 +497,163 @@ func (p *GiteaProvider) GetPullRequest(owner string, repo *git.Repository, numbe
 func (p *GiteaProvider) GetPullRequestCommits(owner string, repository *git.Repository, number int) ([]*git.Commit, error) {
 	answer := []*git.Commit{}
 
-	// TODO there does not seem to be any way to get a diff of commits
-	// unless maybe checking out the repo (do we have access to a local copy?)
-	// there is a pr.Base and pr.Head that might be able to compare to get
-	// commits somehow, but does not look like anything through the api
+	// the client has no call for the commits of a pull request so use the API directly
+	commits := []*giteaCommit{}
+	status, err := p.getJSON(util.UrlJoin("/repos", owner, repository.Name, "pulls", strconv.Itoa(number), "commits"), &commits)
+	if err != nil {
+		return answer, err
+	}
+	if status == http.StatusNotFound {
+		// gitea servers older than 1.17 can't list the commits of a pull request
+		log.Warn(fmt.Sprintf("Cannot list the commits of pull request %d on %s/%s: %d\n", number, owner, repository.Name, status))
+		return answer, nil
+	}
+	if status >= 300 {
+		return answer, fmt.Errorf("failed to list the commits of pull request %d on %s/%s: %d", number, owner, repository.Name, status)
+	}
+	for _, commit := range commits {
+		answer = append(answer, fromGiteaCommit(commit))
+	}
+	return answer, nil
+}
+
+func fromGiteaCommit(commit *giteaCommit) *git.Commit {
+	answer := &git.Commit{
+		SHA:     commit.SHA,
+		Message: commit.Commit.Message,
+		URL:     commit.HTMLURL,
+		Author: &git.User{
+			Name:  commit.Commit.Author.Name,
+			Email: commit.Commit.Author.Email,
+		},
+	}
+	if commit.Author != nil {
+		answer.Author.Login = commit.Author.UserName
+		answer.Author.AvatarURL = commit.Author.AvatarURL
+	}
+	return answer
+}
 
+func (p *GiteaProvider) ListPullRequests(owner string, repository *git.Repository, state string) ([]*git.PullRequest, error) {
+	repo := repository.Name
+	answer := []*git.PullRequest{}
+	// the client sends the page and state in the body, which the API ignores, so list them directly
+	for page := 1; ; page++ {
+		query := url.Values{
+			"state": {state},
+			"page":  {strconv.Itoa(page)},
+		}
+		prs := []*gitea.PullRequest{}
+		status, err := p.getJSON(util.UrlJoin("/repos", owner, repo, "pulls")+"?"+query.Encode(), &prs)
+		if err != nil {
+			return answer, fmt.Errorf("Could not list the pull requests of %s/%s: %s", owner, repo, err)
+		}
+		if status >= 300 {
+			return answer, fmt.Errorf("Could not list the pull requests of %s/%s: %d", owner, repo, status)
+		}
+		if len(prs) == 0 {
+			break
+		}
+		for _, result := range prs {
+			number := int(result.Index)
+			pr := &git.PullRequest{
+				URL:    result.HTMLURL,
+				Owner:  owner,
+				Repo:   repo,
+				Number: &number,
+			}
+			p.updatePullRequest(pr, result)
+			answer = append(answer, pr)
+		}
+	}
 	return answer, nil
 }
 
+// ListPullRequestsByAuthor filters the pull requests of the repository as the API can't filter them by poster
+func (p *GiteaProvider) ListPullRequestsByAuthor(owner string, repository *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	prs, err := p.ListPullRequests(owner, repository, state)
+	if err != nil {
+		return nil, err
+	}
+	return git.FilterPullRequestsByAuthor(prs, author), nil
+}
+
+// GetPullRequestByBranch looks through the open pull requests as the API can't filter them by branch
+func (p *GiteaProvider) GetPullRequestByBranch(owner string, repository *git.Repository, branch string) (*git.PullRequest, error) {
+	prs, err := p.ListPullRequests(owner, repository, git.PullRequestStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindPullRequestByBranch(prs, branch), nil
+}
+
+func (p *GiteaProvider) ClosePullRequest(pr *git.PullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for PullRequest %#v", pr)
+	}
+	n := *pr.Number
+	closed := string(gitea.StateClosed)
+	result, err := p.Client.EditPullRequest(pr.Owner, pr.Repo, int64(n), gitea.EditPullRequestOption{
+		State: &closed,
+	})
+	if err != nil {
+		return fmt.Errorf("Could not close pull request %s/%s #%d: %s", pr.Owner, pr.Repo, n, err)
+	}
+	p.updatePullRequest(pr, result)
+	return nil
+}
+
+// getJSON decodes the response of a GET request to an API path the client doesn't support into v. It returns
+// the status code of the response, leaving v untouched if it isn't successful
+func (p *GiteaProvider) getJSON(path string, v interface{}) (int, error) {
+	resp, err := p.do(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// do sends a request to an API the client doesn't support, with the body encoded as JSON unless it is nil
+func (p *GiteaProvider) do(method string, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, util.UrlJoin(p.URL, "/api/v1", path), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "token "+p.token)
+	}
+	return p.Options.NewHTTPClient().Do(req)
+}
+
+// giteaCommit is a commit as returned by the pull request commits API, which the client doesn't support
+type giteaCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *gitea.User `json:"author"`
+}
+
 func (p *GiteaProvider) GetIssue(org string, name string, number int) (*git.Issue, error) {
 	i, err := p.Client.GetIssue(org, name, int64(number))
 	if err != nil {
@@ -373,10 +678,48 @@ func (p *GiteaProvider) IssueURL(org string, name string, number int, isPull boo
 	return url
 }
 
+// SearchIssues returns the issues matching the query. Gitea filters the state and labels, the author and text are
+// filtered here
 func (p *GiteaProvider) SearchIssues(org string, name string, filter string) ([]*git.Issue, error) {
-	opts := gitea.ListIssueOption{}
-	// TODO apply the filter?
-	return p.searchIssuesWithOptions(org, name, opts)
+	issueQuery := git.ParseIssueQuery(filter)
+	query := url.Values{
+		"state": {"all"},
+		"limit": {strconv.Itoa(pageSize)},
+	}
+	if issueQuery.State != "" {
+		query.Set("state", issueQuery.State)
+	}
+	if len(issueQuery.Labels) > 0 {
+		query.Set("labels", strings.Join(issueQuery.Labels, ","))
+	}
+
+	answer := []*git.Issue{}
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		issues := []*gitea.Issue{}
+		status, err := p.getJSON(util.UrlJoin("/repos", org, name, "issues")+"?"+query.Encode(), &issues)
+		if err != nil {
+			return answer, fmt.Errorf("Could not search the issues of %s/%s: %s", org, name, err)
+		}
+		// a repository without issues has none to find
+		if status == http.StatusNotFound {
+			return answer, nil
+		}
+		if status >= 300 {
+			return answer, fmt.Errorf("Could not search the issues of %s/%s: %d", org, name, status)
+		}
+		for _, issue := range issues {
+			i, err := p.fromGiteaIssue(org, name, issue)
+			if err != nil {
+				return answer, err
+			}
+			answer = append(answer, i)
+		}
+		if len(issues) < pageSize {
+			break
+		}
+	}
+	return git.IssueQuery{Author: issueQuery.Author, Text: issueQuery.Text}.FilterIssues(answer), nil
 }
 
 func (p *GiteaProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*git.Issue, error) {
@@ -419,7 +762,7 @@ func (p *GiteaProvider) fromGiteaIssue(org string, name string, i *gitea.Issue)
 	if assignee != nil {
 		assignees = append(assignees, *toGiteaUser(assignee))
 	}
-	number := int(i.ID)
+	number := int(i.Index)
 	return &git.Issue{
 		Number:        &number,
 		URL:           p.IssueURL(org, name, number, false),
@@ -437,9 +780,21 @@ func (p *GiteaProvider) fromGiteaIssue(org string, name string, i *gitea.Issue)
 }
 
 func (p *GiteaProvider) CreateIssue(owner string, repo string, issue *git.Issue) (*git.Issue, error) {
+	labelIDs, err := p.labelIDs(owner, repo, issue.Labels)
+	if err != nil {
+		return nil, err
+	}
 	config := gitea.CreateIssueOption{
-		Title: issue.Title,
-		Body:  issue.Body,
+		Title:  issue.Title,
+		Body:   issue.Body,
+		Labels: labelIDs,
+	}
+	// gitea issues only have a single assignee
+	if len(issue.Assignees) > 0 {
+		config.Assignee = issue.Assignees[0].Login
+		if len(issue.Assignees) > 1 {
+			log.Warnf("Gitea issues only support one assignee so only %s is assigned to %s\n", config.Assignee, issue.Title)
+		}
 	}
 	i, err := p.Client.CreateIssue(owner, repo, config)
 	if err != nil {
@@ -448,6 +803,45 @@ func (p *GiteaProvider) CreateIssue(owner string, repo string, issue *git.Issue)
 	return p.fromGiteaIssue(owner, repo, i)
 }
 
+// labelIDs returns the ids of the labels of the repository, creating the ones which don't exist yet
+func (p *GiteaProvider) labelIDs(owner string, repo string, labels []git.Label) ([]int64, error) {
+	answer := []int64{}
+	if len(labels) == 0 {
+		return answer, nil
+	}
+	existing, err := p.Client.ListRepoLabels(owner, repo)
+	if err != nil {
+		return answer, err
+	}
+	for _, label := range labels {
+		if label.Name == "" {
+			continue
+		}
+		var found *gitea.Label
+		for _, l := range existing {
+			if l.Name == label.Name {
+				found = l
+				break
+			}
+		}
+		if found == nil {
+			color := label.Color
+			if color == "" {
+				color = "ededed"
+			}
+			found, err = p.Client.CreateLabel(owner, repo, gitea.CreateLabelOption{
+				Name:  label.Name,
+				Color: "#" + strings.TrimPrefix(color, "#"),
+			})
+			if err != nil {
+				return answer, err
+			}
+		}
+		answer = append(answer, found.ID)
+	}
+	return answer, nil
+}
+
 func toGiteaLabel(label *gitea.Label) git.Label {
 	return git.Label{
 		Name:  label.Name,
@@ -478,17 +872,11 @@ func (p *GiteaProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string
 	if ref == "" {
 		return "", fmt.Errorf("Missing String for LastCommitSha %#v", pr)
 	}
-	results, err := p.Client.ListStatuses(pr.Owner, pr.Repo, ref, gitea.ListStatusesOption{})
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, ref)
 	if err != nil {
 		return "", err
 	}
-	for _, result := range results {
-		text := string(result.State)
-		if text != "" {
-			return text, nil
-		}
-	}
-	return "", fmt.Errorf("Could not find a status for repository %s/%s with ref %s", pr.Owner, pr.Repo, ref)
+	return git.OverallState(statuses), nil
 }
 
 func (p *GiteaProvider) AddPRComment(pr *git.PullRequest, comment string) error {
@@ -514,6 +902,40 @@ func (p *GiteaProvider) CreateIssueComment(owner string, repo string, number int
 	return nil
 }
 
+// AddLabelsToIssue adds the labels to the issue, creating the ones the repository doesn't have yet
+func (p *GiteaProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	labelIDs, err := p.labelIDs(owner, repo, git.ToLabels(labels))
+	if err != nil {
+		return fmt.Errorf("Could not find the labels of %s/%s: %s", owner, repo, err)
+	}
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	_, err = p.Client.AddIssueLabels(owner, repo, int64(number), gitea.IssueLabelsOption{Labels: labelIDs})
+	if err != nil {
+		return fmt.Errorf("Could not add labels to %s/%s#%d: %s", owner, repo, number, err)
+	}
+	return nil
+}
+
+func (p *GiteaProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	labels, err := p.Client.ListRepoLabels(owner, repo)
+	if err != nil {
+		return fmt.Errorf("Could not find the labels of %s/%s: %s", owner, repo, err)
+	}
+	for _, l := range labels {
+		if l.Name == label {
+			err = p.Client.DeleteIssueLabel(owner, repo, int64(number), l.ID)
+			if err != nil {
+				return fmt.Errorf("Could not remove label %s from %s/%s#%d: %s", label, owner, repo, number, err)
+			}
+			return nil
+		}
+	}
+	// a label the repository doesn't have can't be on the issue
+	return nil
+}
+
 func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
 	answer := []*git.RepoStatus{}
 	results, err := p.Client.ListStatuses(org, repo, sha, gitea.ListStatusesOption{})
@@ -521,6 +943,7 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 		return answer, fmt.Errorf("Could not find a status for repository %s/%s with ref %s", org, repo, sha)
 	}
 	for _, result := range results {
+		created := result.Created
 		status := &git.RepoStatus{
 			ID:          string(result.ID),
 			Context:     result.Context,
@@ -528,18 +951,27 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 			TargetURL:   result.TargetURL,
 			State:       string(result.State),
 			Description: result.Description,
+			CreatedAt:   &created,
 		}
 		answer = append(answer, status)
 	}
 	return answer, nil
 }
 
+func (p *GiteaProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	statuses, err := p.ListCommitStatus(org, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindRepoStatus(statuses, context), nil
+}
+
 func (b *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
-	return &git.RepoStatus{}, errors.New("TODO")
+	return nil, fmt.Errorf("update commit status: %w", git.ErrNotSupported)
 }
 
 func (p *GiteaProvider) RenameRepository(org string, name string, newName string) (*git.Repository, error) {
-	return nil, fmt.Errorf("Rename of repositories is not supported for Gitea")
+	return nil, fmt.Errorf("rename repository: %w", git.ErrNotSupported)
 }
 
 func (p *GiteaProvider) ValidateRepositoryName(org string, name string) error {
@@ -553,6 +985,124 @@ func (p *GiteaProvider) ValidateRepositoryName(org string, name string) error {
 	return err
 }
 
+// GetMergeConfig returns the merge styles allowed on the repository. Servers older than Gitea 1.16 have no default
+// merge style, and the rebase-merge style has no merge method, so the default method is blank for them
+func (p *GiteaProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	settings := &giteaEditRepo{}
+	status, err := p.getJSON(util.UrlJoin("/repos", org, name), settings)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get the repository %s/%s: %s", org, name, err)
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("Could not get the repository %s/%s: %d", org, name, status)
+	}
+	config := &git.MergeConfig{
+		AllowMergeCommit: asBool(settings.AllowMergeCommits),
+		AllowSquash:      asBool(settings.AllowSquashMerge),
+		AllowRebase:      asBool(settings.AllowRebase),
+	}
+	switch settings.DefaultMergeStyle {
+	case git.MergeMethodMerge, git.MergeMethodSquash, git.MergeMethodRebase:
+		config.DefaultMethod = settings.DefaultMergeStyle
+	}
+	return config, nil
+}
+
+// SetMergeConfig sets the merge styles allowed on the repository, and its default merge style unless the default
+// method is blank
+func (p *GiteaProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	if !config.AllowMergeCommit && !config.AllowSquash && !config.AllowRebase {
+		return fmt.Errorf("at least one merge method must be allowed on repository %s/%s", org, name)
+	}
+	return p.editRepository(org, name, &giteaEditRepo{
+		AllowMergeCommits: &config.AllowMergeCommit,
+		AllowRebase:       &config.AllowRebase,
+		AllowSquashMerge:  &config.AllowSquash,
+		DefaultMergeStyle: config.DefaultMethod,
+	})
+}
+
+// giteaTopics is the body of the topics API, which the client doesn't support
+type giteaTopics struct {
+	Topics []string `json:"topics"`
+}
+
+// SetRepositoryTopics replaces the topics directly as the client can't set topics yet. Servers older than
+// Gitea 1.10 have no topics API
+func (p *GiteaProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	if topics == nil {
+		topics = []string{}
+	}
+	resp, err := p.do(http.MethodPut, util.UrlJoin("/repos", org, name, "topics"), &giteaTopics{Topics: topics})
+	if err != nil {
+		return fmt.Errorf("Could not set the topics of %s/%s: %s", org, name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		if _, err := p.GetRepository(org, name); err != nil {
+			return err
+		}
+		return fmt.Errorf("set repository topics: %w", git.ErrNotSupported)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Could not set the topics of %s/%s: %d", org, name, resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *GiteaProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	answer := []*git.Milestone{}
+	milestones, err := p.Client.ListRepoMilestones(org, name)
+	if err != nil {
+		return answer, fmt.Errorf("Failed to list the milestones of %s/%s due to: %s", org, name, err)
+	}
+	for _, milestone := range milestones {
+		answer = append(answer, toGiteaMilestone(milestone))
+	}
+	return answer, nil
+}
+
+func (p *GiteaProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	milestone, err := p.Client.CreateMilestone(org, name, gitea.CreateMilestoneOption{
+		Title:    title,
+		Deadline: due,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create milestone %s on repository %s/%s due to: %s", title, org, name, err)
+	}
+	return toGiteaMilestone(milestone), nil
+}
+
+func (p *GiteaProvider) CloseMilestone(org string, name string, id int64) error {
+	closed := string(gitea.StateClosed)
+	_, err := p.Client.EditMilestone(org, name, id, gitea.EditMilestoneOption{
+		State: &closed,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to close milestone %d of repository %s/%s due to: %s", id, org, name, err)
+	}
+	return nil
+}
+
+func toGiteaMilestone(milestone *gitea.Milestone) *git.Milestone {
+	return &git.Milestone{
+		ID:           milestone.ID,
+		Title:        milestone.Title,
+		State:        string(milestone.State),
+		DueOn:        milestone.Deadline,
+		OpenIssues:   milestone.OpenIssues,
+		ClosedIssues: milestone.ClosedIssues,
+	}
+}
+
+// ListContributors is not supported as the gitea API doesn't count the commits of contributors
+func (p *GiteaProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	return nil, fmt.Errorf("list contributors: %w", git.ErrNotSupported)
+}
+
 func (p *GiteaProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
 	var release *gitea.Release
 	releases, err := p.Client.ListReleases(owner, repo)
@@ -609,6 +1159,36 @@ func (p *GiteaProvider) HasIssues() bool {
 	return true
 }
 
+// giteaEditRepo is the body of the repository edit API, which the client doesn't support. Only the settings which
+// are set are changed. The repository API returns the settings too
+type giteaEditRepo struct {
+	HasIssues         *bool  `json:"has_issues,omitempty"`
+	AllowMergeCommits *bool  `json:"allow_merge_commits,omitempty"`
+	AllowRebase       *bool  `json:"allow_rebase,omitempty"`
+	AllowSquashMerge  *bool  `json:"allow_squash_merge,omitempty"`
+	DefaultMergeStyle string `json:"default_merge_style,omitempty"`
+}
+
+// editRepository changes the settings of the repository which are set in the edit
+func (p *GiteaProvider) editRepository(org string, name string, edit *giteaEditRepo) error {
+	resp, err := p.do(http.MethodPatch, util.UrlJoin("/repos", org, name), edit)
+	if err != nil {
+		return fmt.Errorf("Could not edit the repository %s/%s: %s", org, name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Could not edit the repository %s/%s: %d", org, name, resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *GiteaProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	return p.editRepository(org, name, &giteaEditRepo{HasIssues: &enabled})
+}
+
 func (p *GiteaProvider) IsGitHub() bool {
 	return false
 }
@@ -630,7 +1210,7 @@ func (p *GiteaProvider) IsGerrit() bool {
 }
 
 func (p *GiteaProvider) Kind() string {
-	return "gitea"
+	return git.KindGitea
 }
 
 func (p *GiteaProvider) JenkinsWebHookPath(gitURL string, secret string) string {
@@ -654,14 +1234,42 @@ func (p *GiteaProvider) BranchArchiveURL(org string, name string, branch string)
 }
 
 func (p *GiteaProvider) CurrentUsername() string {
+	if p.Username == "" {
+		user, err := p.GetCurrentUser()
+		if err == nil {
+			p.Username = user.Login
+		}
+	}
 	return p.Username
 }
 
+func (p *GiteaProvider) GetCurrentUser() (*git.User, error) {
+	user, err := p.Client.GetMyUserInfo()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the authenticated user due to: %s", err)
+	}
+	answer := toGiteaUser(user)
+	answer.URL = util.UrlJoin(p.URL, user.UserName)
+	return answer, nil
+}
+
 func (p *GiteaProvider) UserInfo(username string) *git.User {
-	user, err := p.Client.GetUserInfo(username)
+	user, _ := p.getUser(username)
+	return user
+}
 
+// getUser looks up a user, or returns nil if there is no such user
+func (p *GiteaProvider) getUser(username string) (*git.User, error) {
+	user := &gitea.User{}
+	status, err := p.getJSON(util.UrlJoin("/users", username), user)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("get user %s: %w", username, err)
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("Could not get user %s: %d", username, status)
 	}
 
 	return &git.User{
@@ -671,7 +1279,213 @@ func (p *GiteaProvider) UserInfo(username string) *git.User {
 		Email:     user.Email,
 		// TODO figure the Gitea user url
 		URL: p.URL + "/" + username,
+	}, nil
+}
+
+func (p *GiteaProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return p.users.GetUsers(usernames, p.getUser)
+}
+
+// DeleteBranch deletes the branch directly as the client can't delete branches yet
+func (p *GiteaProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := p.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	if branch == repo.DefaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, git.ErrDefaultBranch)
+	}
+	resp, err := p.do(http.MethodDelete, util.UrlJoin("/repos", org, name, "branches", branch), nil)
+	if err != nil {
+		return fmt.Errorf("Could not delete branch %s of %s/%s: %s", branch, org, name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("Could not delete branch %s of %s/%s: %d", branch, org, name, resp.StatusCode)
+	}
+	return nil
+}
+
+// giteaCreateBranchOption is the body of the create branch API, which the client doesn't support. Older servers
+// only read old_branch_name, newer ones create the branch from old_ref_name when it is set
+type giteaCreateBranchOption struct {
+	BranchName    string `json:"new_branch_name"`
+	OldBranchName string `json:"old_branch_name,omitempty"`
+	OldRefName    string `json:"old_ref_name,omitempty"`
+}
+
+// CreateBranch creates the branch directly as the client can't create branches yet
+func (p *GiteaProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	sha, err := p.ResolveRef(org, name, fromRef)
+	if err != nil {
+		return err
+	}
+	option := &giteaCreateBranchOption{
+		BranchName:    branch,
+		OldBranchName: fromRef,
+		OldRefName:    sha,
+	}
+	resp, err := p.do(http.MethodPost, util.UrlJoin("/repos", org, name, "branches"), option)
+	if err != nil {
+		return fmt.Errorf("Could not create branch %s of %s/%s: %s", branch, org, name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("create branch %s of %s/%s: %w", branch, org, name, git.ErrAlreadyExists)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Could not create branch %s of %s/%s: %d", branch, org, name, resp.StatusCode)
+	}
+	return nil
+}
+
+// giteaCreateTag is the body of the create tag API, which the client doesn't support
+type giteaCreateTag struct {
+	TagName string `json:"tag_name"`
+	Target  string `json:"target"`
+	Message string `json:"message,omitempty"`
+}
+
+// CreateTag creates the tag with the tags API. Servers older than Gitea 1.15 have no tags API to create one with
+func (p *GiteaProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	resp, err := p.do(http.MethodPost, util.UrlJoin("/repos", org, name, "tags"), &giteaCreateTag{
+		TagName: tag,
+		Target:  sha,
+		Message: message,
+	})
+	if err != nil {
+		return fmt.Errorf("Could not create tag %s on %s/%s: %s", tag, org, name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		if _, err := p.GetRepository(org, name); err != nil {
+			return err
+		}
+		return fmt.Errorf("create tag: %w", git.ErrNotSupported)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Could not create tag %s on %s/%s: %d", tag, org, name, resp.StatusCode)
+	}
+	return nil
+}
+
+// GetTag returns the tag using the refs API, or nil if there is no such tag. Annotated tags are resolved
+// to the commit they tag
+func (p *GiteaProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	refs := []*giteaReference{}
+	status, err := p.getJSON(util.UrlJoin("/repos", org, name, "git/refs/tags", tag), &refs)
+	if err != nil {
+		return nil, err
 	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("Could not get tag %s of %s/%s: %d", tag, org, name, status)
+	}
+	// gitea lists the refs starting with the name so look for an exact match
+	for _, r := range refs {
+		if r.Ref != "refs/tags/"+tag {
+			continue
+		}
+		answer := &git.GitTag{
+			Name: tag,
+			SHA:  r.Object.SHA,
+		}
+		if r.Object.Type != "tag" {
+			return answer, nil
+		}
+		tagObject := giteaReference{}
+		status, err := p.getJSON(util.UrlJoin("/repos", org, name, "git/tags", r.Object.SHA), &tagObject)
+		if err != nil {
+			return nil, err
+		}
+		if status >= 300 {
+			return nil, fmt.Errorf("Could not get tag %s of %s/%s: %d", tag, org, name, status)
+		}
+		answer.SHA = tagObject.Object.SHA
+		answer.Message = tagObject.Message
+		return answer, nil
+	}
+	return nil, nil
+}
+
+// ListRepositoryTags lists the tags directly as the client can't list them yet. Older versions of Gitea don't report
+// the message and the date of the commit
+func (p *GiteaProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	answer := []*git.GitTag{}
+	for page := 1; ; page++ {
+		query := url.Values{
+			"page":  {strconv.Itoa(page)},
+			"limit": {strconv.Itoa(pageSize)},
+		}
+		tags := []*giteaTag{}
+		status, err := p.getJSON(util.UrlJoin("/repos", org, name, "tags")+"?"+query.Encode(), &tags)
+		if err != nil {
+			return answer, fmt.Errorf("Could not list the tags of %s/%s: %s", org, name, err)
+		}
+		if status >= 300 {
+			return answer, fmt.Errorf("Could not list the tags of %s/%s: %d", org, name, status)
+		}
+		for _, tag := range tags {
+			answer = append(answer, &git.GitTag{
+				Name:    tag.Name,
+				Message: tag.Message,
+				SHA:     tag.Commit.SHA,
+				Date:    tag.Commit.Created,
+			})
+		}
+		// a short page is the last one, which also stops servers ignoring the page from being listed forever
+		if len(tags) < pageSize {
+			break
+		}
+	}
+	return answer, nil
+}
+
+func (p *GiteaProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	return 0, 0, fmt.Errorf("get ahead behind: %w", git.ErrNotSupported)
+}
+
+// SyncFork merges the upstream branch into the fork using a local clone
+func (p *GiteaProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return git.SyncForkWithGit(p, p.Git, p.Options, p.token, org, name, upstreamOrg, upstreamName, branch)
+}
+
+func (p *GiteaProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	return nil, fmt.Errorf("list pull request activity: %w", git.ErrNotSupported)
+}
+
+func (p *GiteaProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	return nil, fmt.Errorf("get pull request approval state: %w", git.ErrNotSupported)
+}
+
+// GetPullRequestStats reads the size of the change from the pull request, which the client doesn't decode
+func (p *GiteaProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for PullRequest %#v", pr)
+	}
+	var stats struct {
+		Additions    *int `json:"additions"`
+		Deletions    *int `json:"deletions"`
+		ChangedFiles *int `json:"changed_files"`
+	}
+	status, err := p.getJSON(util.UrlJoin("/repos", pr.Owner, pr.Repo, "pulls", strconv.Itoa(*pr.Number)), &stats)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("Could not get pull request %d of %s/%s: %d", *pr.Number, pr.Owner, pr.Repo, status)
+	}
+	// gitea servers older than 1.18 don't report the size of pull requests
+	if stats.Additions == nil || stats.Deletions == nil || stats.ChangedFiles == nil {
+		return nil, fmt.Errorf("get pull request stats: %w", git.ErrNotSupported)
+	}
+	return &git.DiffStat{
+		Additions:    *stats.Additions,
+		Deletions:    *stats.Deletions,
+		ChangedFiles: *stats.ChangedFiles,
+	}, nil
 }
 
 func (p *GiteaProvider) AddCollaborator(user string, organisation string, repo string) error {
@@ -679,18 +1493,91 @@ func (p *GiteaProvider) AddCollaborator(user string, organisation string, repo s
 	return nil
 }
 
-func (p *GiteaProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Gitea.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+// ListInvitations isn't supported as gitea makes users collaborators as soon as they are added
+func (p *GiteaProvider) ListInvitations() ([]*git.Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", git.ErrNotSupported)
 }
 
-func (p *GiteaProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Gitea.\n")
-	return &github.Response{}, nil
+func (p *GiteaProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation: %w", git.ErrNotSupported)
+}
+
+// GetFileLastCommit returns the last commit which changed the file on the ref, or nil if there is none. Servers
+// which can't filter the commits by path give the last commit of the ref
+func (p *GiteaProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	query := url.Values{
+		"path":  {path},
+		"sha":   {ref},
+		"limit": {"1"},
+	}
+	commits := []*giteaCommit{}
+	status, err := p.getJSON(util.UrlJoin("/repos", org, name, "commits")+"?"+query.Encode(), &commits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the commits of %s in %s/%s: %s", path, org, name, err)
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("failed to list the commits of %s in %s/%s: %d", path, org, name, status)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return fromGiteaCommit(commits[0]), nil
+}
+
+func (p *GiteaProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	return nil, fmt.Errorf("list branches containing commit: %w", git.ErrNotSupported)
 }
 
 func (p *GiteaProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on gitea")
+	return nil, fmt.Errorf("get content: %w", git.ErrNotSupported)
+}
+
+// giteaTag is a tag as returned by the tags API, which the client doesn't support. Servers older than Gitea 1.14
+// don't report the message or when the commit was made
+type giteaTag struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Commit  struct {
+		SHA     string     `json:"sha"`
+		Created *time.Time `json:"created"`
+	} `json:"commit"`
+}
+
+// giteaReference is a git reference or annotated tag as returned by the refs and tags APIs, which the
+// client doesn't support. Only annotated tags have a message
+type giteaReference struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+	Object  struct {
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	} `json:"object"`
+}
+
+// ResolveRef returns the SHA of the commit the branch or tag points at, looking for a branch first.
+// Annotated tags are resolved to the commit they tag
+func (p *GiteaProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	branch := &gitea.Branch{}
+	status, err := p.getJSON(util.UrlJoin("/repos", org, name, "branches", ref), branch)
+	if err != nil {
+		return "", err
+	}
+	if status < 300 && branch.Commit != nil {
+		return branch.Commit.ID, nil
+	}
+	// only a missing branch may be a tag
+	if status != http.StatusNotFound {
+		return "", fmt.Errorf("Could not get branch %s of %s/%s: %d", ref, org, name, status)
+	}
+
+	tag, err := p.GetTag(org, name, ref)
+	if err != nil {
+		return "", err
+	}
+	if tag == nil {
+		return "", fmt.Errorf("ref %s of %s/%s: %w", ref, org, name, git.ErrNotFound)
+	}
+	return tag.SHA, nil
 }
 
 func asText(text *string) string {
@@ -699,3 +1586,7 @@ func asText(text *string) string {
 	}
 	return ""
 }
+
+func asBool(value *bool) bool {
+	return value != nil && *value
+}
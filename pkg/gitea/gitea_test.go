@@ -0,0 +1,876 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+	"github.com/wbrefvem/go-gits/pkg/git"
+)
+
+const (
+	giteaUserName = "testperson"
+	giteaOrgName  = "testorg"
+	giteaRepoName = "test-repo"
+)
+
+type GiteaProviderSuite struct {
+	suite.Suite
+	mux      *http.ServeMux
+	server   *httptest.Server
+	provider *GiteaProvider
+
+	// milestone is the last body sent to the milestones API
+	milestone map[string]interface{}
+	// deletedBranches are the branches deleted through the branches API
+	deletedBranches []string
+	// createdBranch is the last body posted to the branches API
+	createdBranch map[string]interface{}
+	// topics is the last body sent to the topics API
+	topics map[string]interface{}
+	// pullRequest is the last body posted to the pull requests API
+	pullRequest map[string]interface{}
+	// issueLabels is the last body posted to the issue labels API and removedLabels the paths of the labels removed
+	issueLabels   map[string]interface{}
+	removedLabels []string
+	// reviewRequest is the last body posted to the requested reviewers API
+	reviewRequest map[string]interface{}
+	// issueQuery is the query of the last request listing issues
+	issueQuery url.Values
+	// repositoryEdit is the last body sent to the repository edit API
+	repositoryEdit map[string]interface{}
+	// createdTag is the last body posted to the tags API
+	createdTag map[string]interface{}
+}
+
+var giteaRouter = util.Router{
+	"/api/v1/user": util.MethodMap{
+		"GET": "user.json",
+	},
+	"/api/v1/user/repos": util.MethodMap{
+		"GET": "user-repos.json",
+	},
+	"/api/v1/repos/testorg/test-repo": util.MethodMap{
+		"GET":   "repos.test-repo.json",
+		"PATCH": "repos.test-repo.json",
+	},
+	"/api/v1/repos/testorg/test-repo/pulls/1": util.MethodMap{
+		"GET": "pulls.1.json",
+	},
+	"/api/v1/repos/testorg/test-repo/pulls/1/commits": util.MethodMap{
+		"GET": "pulls.1.commits.json",
+	},
+	"/api/v1/repos/testorg/test-repo/labels": util.MethodMap{
+		"GET": "labels.json",
+	},
+	"/api/v1/repos/testorg/test-repo/issues/3": util.MethodMap{
+		"GET": "issues.3.json",
+	},
+	"/api/v1/repos/testorg/test-repo/git/refs/tags/v1.0": util.MethodMap{
+		"GET": "git.refs.tags.v1.0.json",
+	},
+	"/api/v1/repos/testorg/test-repo/git/refs/tags/v1.0.1": util.MethodMap{
+		"GET": "git.refs.tags.v1.0.1.json",
+	},
+	"/api/v1/repos/testorg/test-repo/milestones": util.MethodMap{
+		"GET":  "milestones.json",
+		"POST": "milestone.3.json",
+	},
+	"/api/v1/repos/testorg/test-repo/milestones/3": util.MethodMap{
+		"PATCH": "milestone.3.json",
+	},
+	"/api/v1/repos/testorg/test-repo/git/tags/b1e9d3c6f0a2e4d8c7b5a3f1e9d7c5b3a1f0e2d4": util.MethodMap{
+		"GET": "git.tags.v1.0.1.json",
+	},
+}
+
+func (suite *GiteaProviderSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+	for path, methodMap := range giteaRouter {
+		handler := util.GetMockAPIResponseFromFile("test_data/gitea", methodMap)
+		if strings.Contains(path, "/milestones") {
+			handler = suite.recordMilestone(handler)
+		}
+		if path == "/api/v1/repos/testorg/test-repo" {
+			handler = suite.recordRepositoryEdit(handler)
+		}
+		suite.mux.HandleFunc(path, handler)
+	}
+
+	// the pull requests in the state asked for come back a page at a time
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.pullRequest = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.pullRequest))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 5, "html_url": "http://gitea.example.com/testorg/test-repo/pulls/5", "state": "open"}`)
+			return
+		}
+		state := r.URL.Query().Get("state")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			if state == git.PullRequestStateOpen {
+				fmt.Fprint(w, `[{"number": 2, "state": "open", "head": {"ref": "promote-1.0.3"}, "base": {"ref": "master"}}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"number": 1, "state": "closed", "merged": true, "head": {"ref": "feature"}, "base": {"ref": "master"}}]`)
+		case "2":
+			if state == git.PullRequestStateOpen {
+				fmt.Fprint(w, `[{"number": 4, "state": "open", "head": {"ref": "feature"}, "base": {"ref": "master"}}]`)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/pulls/5/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.reviewRequest = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.reviewRequest))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `[]`)
+	})
+
+	createIssue := util.GetMockAPIResponseFromFile("test_data/gitea", util.MethodMap{
+		"POST": "issues.3.json",
+	})
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			createIssue(w, r)
+			return
+		}
+		suite.issueQuery = r.URL.Query()
+		if suite.issueQuery.Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 3, "title": "Flaky tests break the build", "state": "open",
+			"user": {"login": "reviewer"}, "labels": [{"id": 3, "name": "flaky"}]},
+			{"number": 4, "title": "Flaky login page", "state": "open",
+			"user": {"login": "testperson"}, "labels": [{"id": 3, "name": "flaky"}]}]`)
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/issues/3/labels", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.issueLabels = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.issueLabels))
+		fmt.Fprint(w, `[{"id": 1, "name": "do-not-merge"}, {"id": 2, "name": "bug"}]`)
+	})
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/issues/3/labels/", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		suite.removedLabels = append(suite.removedLabels, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/topics", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPut, r.Method)
+		suite.topics = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.topics))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/branches", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.createdBranch = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.createdBranch))
+		if suite.createdBranch["new_branch_name"] == "develop" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"name": "%s"}`, suite.createdBranch["new_branch_name"])
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/branches/", func(w http.ResponseWriter, r *http.Request) {
+		branch := strings.TrimPrefix(r.URL.Path, "/api/v1/repos/testorg/test-repo/branches/")
+		if r.Method == http.MethodGet {
+			if branch == "locked" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if branch != "develop" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"name": "develop", "commit": {"id": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}}`)
+			return
+		}
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		if branch != "feature" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		suite.deletedBranches = append(suite.deletedBranches, branch)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.mux.HandleFunc("/api/v1/repos/testorg/test-repo/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.createdTag = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.createdTag))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"name": "%s"}`, suite.createdTag["tag_name"])
+			return
+		}
+		suite.Require().Equal(strconv.Itoa(pageSize), r.URL.Query().Get("limit"))
+		switch r.URL.Query().Get("page") {
+		case "1":
+			// a full page
+			tags := []string{`{"name": "v1.0.1", "message": "Release 1.0.1\n",
+				"commit": {"sha": "0f3a9c1e5b7d2f4a6c8e0b2d4f6a8c0e2b4d6f8a", "created": "2018-11-20T10:12:45Z"}}`}
+			for i := 1; i < pageSize; i++ {
+				tags = append(tags, fmt.Sprintf(`{"name": "v0.%d", "commit": {"sha": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}}`, i))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(tags, ","))
+		case "2":
+			// older servers only report the commit
+			fmt.Fprint(w, `[{"name": "v1.0", "commit": {"sha": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}}]`)
+		default:
+			suite.Fail("the tags after a short page should not be listed")
+		}
+	})
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	p, err := NewProvider(giteaUserName, suite.server.URL, "test", "gitea", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = p.(*GiteaProvider)
+	suite.Require().True(ok)
+}
+
+// recordMilestone keeps the body of the requests which change milestones
+func (suite *GiteaProviderSuite) recordMilestone(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			suite.milestone = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.milestone))
+		}
+		handler(w, r)
+	}
+}
+
+// recordRepositoryEdit keeps the body of the requests which edit the repository
+func (suite *GiteaProviderSuite) recordRepositoryEdit(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			suite.repositoryEdit = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.repositoryEdit))
+		}
+		handler(w, r)
+	}
+}
+
+func (suite *GiteaProviderSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+func (suite *GiteaProviderSuite) TestGetRepository() {
+	repo, err := suite.provider.GetRepository(giteaOrgName, giteaRepoName)
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+
+	suite.Require().Equal(giteaRepoName, repo.Name)
+	suite.Require().Equal("A repository for testing", repo.Description)
+	suite.Require().Equal("develop", repo.DefaultBranch)
+	suite.Require().True(repo.Private)
+	suite.Require().False(repo.HasIssuesEnabled)
+	suite.Require().Equal("https://try.gitea.io/testorg/test-repo.git", repo.CloneURL)
+	suite.Require().Equal(112, repo.Size)
+	suite.Require().Nil(repo.PushedAt)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *repo.UpdatedAt)
+}
+
+func (suite *GiteaProviderSuite) TestListRepositoriesForCurrentUser() {
+	repos, err := suite.provider.ListRepositoriesForCurrentUser()
+	suite.Require().Nil(err)
+	suite.Require().Len(repos, 1)
+
+	suite.Require().Equal(giteaRepoName, repos[0].Name)
+	suite.Require().Equal("https://try.gitea.io/testperson/test-repo.git", repos[0].CloneURL)
+}
+
+func (suite *GiteaProviderSuite) TestUpdatePullRequestStatus() {
+	number := 1
+	pr := &git.PullRequest{
+		Owner:  giteaOrgName,
+		Repo:   giteaRepoName,
+		Number: &number,
+	}
+
+	err := suite.provider.UpdatePullRequestStatus(pr)
+	suite.Require().Nil(err)
+
+	suite.Require().Equal("closed", *pr.State)
+	suite.Require().True(*pr.Merged)
+	suite.Require().Len(pr.Labels, 1)
+	suite.Require().True(pr.HasLabel("do-not-merge"))
+	suite.Require().NotNil(pr.ClosedAt)
+	suite.Require().Equal("2018-11-20T10:12:45Z", pr.ClosedAt.UTC().Format("2006-01-02T15:04:05Z"))
+	suite.Require().NotNil(pr.DiffURL)
+	suite.Require().Equal("https://try.gitea.io/testorg/test-repo/pulls/1.diff", *pr.DiffURL)
+	suite.Require().NotNil(pr.IssueURL)
+	suite.Require().Equal(suite.server.URL+"/testorg/test-repo/issues/1", *pr.IssueURL)
+	suite.Require().NotNil(pr.StatusesURL)
+	suite.Require().Equal(suite.server.URL+"/api/v1/repos/testorg/test-repo/statuses/9a5c3b2c4f7e1d0e8b6a4c2e0f1d3b5a7c9e1f23", *pr.StatusesURL)
+}
+
+func (suite *GiteaProviderSuite) TestGetPullRequestStats() {
+	number := 1
+	pr := &git.PullRequest{
+		Owner:  giteaOrgName,
+		Repo:   giteaRepoName,
+		Number: &number,
+	}
+
+	stats, err := suite.provider.GetPullRequestStats(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.DiffStat{Additions: 40, Deletions: 7, ChangedFiles: 5}, stats)
+}
+
+func (suite *GiteaProviderSuite) TestListPullRequests() {
+	repo := &git.Repository{Name: giteaRepoName}
+	prs, err := suite.provider.ListPullRequests(giteaOrgName, repo, git.PullRequestStateOpen)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 2)
+	suite.Require().Equal(2, *prs[0].Number)
+	suite.Require().Equal(4, *prs[1].Number)
+
+	prs, err = suite.provider.ListPullRequests(giteaOrgName, repo, git.PullRequestStateClosed)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 1)
+	suite.Require().Equal(1, *prs[0].Number)
+	suite.Require().True(*prs[0].Merged)
+}
+
+func (suite *GiteaProviderSuite) TestCreateDraftPullRequest() {
+	pr, err := suite.provider.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: giteaOrgName, Name: giteaRepoName},
+		Draft:      true,
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(5, *pr.Number)
+	suite.Require().Equal("WIP: Promote to version 1.0.4", suite.pullRequest["title"])
+	suite.Require().Equal("promote-1.0.4", suite.pullRequest["head"])
+}
+
+func (suite *GiteaProviderSuite) TestCreatePullRequestAssignsUsers() {
+	pr, err := suite.provider.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: giteaOrgName, Name: giteaRepoName},
+		Assignees:  []string{"some", "few"},
+		Reviewers:  []string{"most"},
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(5, *pr.Number)
+	suite.Require().Equal([]interface{}{"some", "few"}, suite.pullRequest["assignees"])
+	suite.Require().Equal([]interface{}{"most"}, suite.reviewRequest["reviewers"])
+}
+
+func (suite *GiteaProviderSuite) TestRequestReviewersOnOldServers() {
+	status := http.StatusNotFound
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo/pulls/5/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &GiteaProvider{URL: server.URL, Options: suite.provider.Options}
+
+	// servers without review requests are skipped
+	err := p.requestReviewers(giteaOrgName, giteaRepoName, 5, []string{"most"})
+	suite.Require().Nil(err)
+
+	status = http.StatusForbidden
+	err = p.requestReviewers(giteaOrgName, giteaRepoName, 5, []string{"most"})
+	suite.Require().NotNil(err)
+}
+
+func (suite *GiteaProviderSuite) TestGetPullRequestByBranch() {
+	repo := &git.Repository{Name: giteaRepoName}
+	pr, err := suite.provider.GetPullRequestByBranch(giteaOrgName, repo, "promote-1.0.3")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(2, *pr.Number)
+
+	pr, err = suite.provider.GetPullRequestByBranch(giteaOrgName, repo, "promote-1.0.4")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(pr)
+}
+
+func (suite *GiteaProviderSuite) TestGetPullRequestCommits() {
+	repo := &git.Repository{Name: giteaRepoName}
+	commits, err := suite.provider.GetPullRequestCommits(giteaOrgName, repo, 1)
+	suite.Require().Nil(err)
+	suite.Require().Len(commits, 2)
+
+	suite.Require().Equal("4f7e1d0e8b6a4c2e0f1d3b5a7c9e1f239a5c3b2c", commits[0].SHA)
+	suite.Require().Equal("Add the feature\n", commits[0].Message)
+	suite.Require().Equal("https://try.gitea.io/testorg/test-repo/commit/4f7e1d0e8b6a4c2e0f1d3b5a7c9e1f239a5c3b2c", commits[0].URL)
+	suite.Require().Equal(giteaUserName, commits[0].Author.Login)
+	suite.Require().Equal("testperson@example.com", commits[0].Author.Email)
+
+	// commits by an unknown user only have the git author
+	suite.Require().Equal("", commits[1].Author.Login)
+	suite.Require().Equal("someone@example.com", commits[1].Author.Email)
+}
+
+func (suite *GiteaProviderSuite) TestGetFileLastCommit() {
+	var query url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if query.Get("path") != "README.md" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"sha": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00",
+			"html_url": "https://try.gitea.io/testorg/test-repo/commit/6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00",
+			"commit": {"message": "Update the README", "author": {"name": "Test Person", "email": "testperson@example.com"}},
+			"author": {"login": "testperson"}}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &GiteaProvider{URL: server.URL, Options: suite.provider.Options}
+
+	commit, err := p.GetFileLastCommit(giteaOrgName, giteaRepoName, "README.md", "develop")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(commit)
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", commit.SHA)
+	suite.Require().Equal("Update the README", commit.Message)
+	suite.Require().Equal("testperson", commit.Author.Login)
+	suite.Require().Equal("develop", query.Get("sha"))
+	suite.Require().Equal("1", query.Get("limit"))
+
+	commit, err = p.GetFileLastCommit(giteaOrgName, giteaRepoName, "missing.md", "develop")
+	suite.Require().Nil(err)
+	suite.Require().Nil(commit)
+}
+
+func (suite *GiteaProviderSuite) TestCreateAndGetIssue() {
+	issue, err := suite.provider.CreateIssue(giteaOrgName, giteaRepoName, &git.Issue{
+		Title:     "Something is broken",
+		Body:      "Steps to reproduce",
+		Labels:    []git.Label{{Name: "bug"}},
+		Assignees: []git.User{{Login: giteaUserName}},
+	})
+	suite.Require().Nil(err)
+	suite.Require().NotNil(issue)
+	suite.Require().Equal(3, *issue.Number)
+	suite.Require().Len(issue.Labels, 1)
+	suite.Require().Equal("bug", issue.Labels[0].Name)
+	suite.Require().Len(issue.Assignees, 1)
+	suite.Require().Equal(giteaUserName, issue.Assignees[0].Login)
+	suite.Require().Equal(suite.server.URL+"/testorg/test-repo/issues/3", issue.URL)
+
+	found, err := suite.provider.GetIssue(giteaOrgName, giteaRepoName, *issue.Number)
+	suite.Require().Nil(err)
+	suite.Require().NotNil(found)
+	suite.Require().Equal(*issue.Number, *found.Number)
+	suite.Require().Equal("Something is broken", found.Title)
+}
+
+func (suite *GiteaProviderSuite) TestSearchIssues() {
+	issues, err := suite.provider.SearchIssues(giteaOrgName, giteaRepoName, "is:open label:flaky label:ci author:reviewer build")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal(3, *issues[0].Number)
+	suite.Require().Equal("open", suite.issueQuery.Get("state"))
+	suite.Require().Equal("flaky,ci", suite.issueQuery.Get("labels"))
+
+	// every state is searched unless the query asks for one
+	issues, err = suite.provider.SearchIssues(giteaOrgName, giteaRepoName, "flaky")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 2)
+	suite.Require().Equal("all", suite.issueQuery.Get("state"))
+	suite.Require().Empty(suite.issueQuery.Get("labels"))
+}
+
+func (suite *GiteaProviderSuite) TestIssueLabels() {
+	err := suite.provider.AddLabelsToIssue(giteaOrgName, giteaRepoName, 3, []string{"do-not-merge", "bug"})
+	suite.Require().Nil(err)
+	suite.Require().Equal([]interface{}{float64(1), float64(2)}, suite.issueLabels["labels"])
+
+	suite.removedLabels = nil
+	err = suite.provider.RemoveLabelFromIssue(giteaOrgName, giteaRepoName, 3, "bug")
+	suite.Require().Nil(err)
+	// a label the repository doesn't have isn't on the issue either
+	err = suite.provider.RemoveLabelFromIssue(giteaOrgName, giteaRepoName, 3, "wontfix")
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"/api/v1/repos/testorg/test-repo/issues/3/labels/2"}, suite.removedLabels)
+}
+
+func (suite *GiteaProviderSuite) TestGetTag() {
+	// gitea lists v1.0.1 too when asked for v1.0
+	tag, err := suite.provider.GetTag(giteaOrgName, giteaRepoName, "v1.0")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(tag)
+	suite.Require().Equal("v1.0", tag.Name)
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", tag.SHA)
+	suite.Require().Empty(tag.Message)
+
+	// an annotated tag resolves to the commit it tags
+	tag, err = suite.provider.GetTag(giteaOrgName, giteaRepoName, "v1.0.1")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(tag)
+	suite.Require().Equal("0f3a9c1e5b7d2f4a6c8e0b2d4f6a8c0e2b4d6f8a", tag.SHA)
+	suite.Require().Equal("Release 1.0.1\n", tag.Message)
+
+	tag, err = suite.provider.GetTag(giteaOrgName, giteaRepoName, "v2.0")
+	suite.Require().Nil(err)
+	suite.Require().Nil(tag)
+}
+
+func (suite *GiteaProviderSuite) TestResolveRef() {
+	sha, err := suite.provider.ResolveRef(giteaOrgName, giteaRepoName, "develop")
+	suite.Require().Nil(err)
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", sha)
+
+	// a missing branch may be a tag
+	sha, err = suite.provider.ResolveRef(giteaOrgName, giteaRepoName, "v1.0.1")
+	suite.Require().Nil(err)
+	suite.Require().Equal("0f3a9c1e5b7d2f4a6c8e0b2d4f6a8c0e2b4d6f8a", sha)
+
+	_, err = suite.provider.ResolveRef(giteaOrgName, giteaRepoName, "v2.0")
+	suite.Require().True(git.IsNotFound(err))
+
+	// other errors of the branch aren't hidden by looking up the tag
+	_, err = suite.provider.ResolveRef(giteaOrgName, giteaRepoName, "locked")
+	suite.Require().NotNil(err)
+	suite.Require().False(git.IsNotFound(err))
+}
+
+func (suite *GiteaProviderSuite) TestDeleteBranch() {
+	suite.deletedBranches = nil
+
+	err := suite.provider.DeleteBranch(giteaOrgName, giteaRepoName, "feature")
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"feature"}, suite.deletedBranches)
+
+	// a branch which is already gone is fine
+	err = suite.provider.DeleteBranch(giteaOrgName, giteaRepoName, "deleted")
+	suite.Require().Nil(err)
+
+	err = suite.provider.DeleteBranch(giteaOrgName, giteaRepoName, "develop")
+	suite.Require().True(git.IsDefaultBranch(err))
+	suite.Require().Len(suite.deletedBranches, 1)
+}
+
+func (suite *GiteaProviderSuite) TestCreateBranch() {
+	err := suite.provider.CreateBranch(giteaOrgName, giteaRepoName, "feature", "develop")
+	suite.Require().Nil(err)
+	suite.Require().Equal("feature", suite.createdBranch["new_branch_name"])
+	suite.Require().Equal("develop", suite.createdBranch["old_branch_name"])
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", suite.createdBranch["old_ref_name"])
+
+	// a tag is resolved to the commit it points at
+	err = suite.provider.CreateBranch(giteaOrgName, giteaRepoName, "hotfix", "v1.0")
+	suite.Require().Nil(err)
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", suite.createdBranch["old_ref_name"])
+
+	err = suite.provider.CreateBranch(giteaOrgName, giteaRepoName, "develop", "develop")
+	suite.Require().True(git.IsAlreadyExists(err))
+}
+
+func (suite *GiteaProviderSuite) TestSetIssuesEnabled() {
+	err := suite.provider.SetIssuesEnabled(giteaOrgName, giteaRepoName, true)
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"has_issues": true}, suite.repositoryEdit)
+
+	err = suite.provider.SetIssuesEnabled(giteaOrgName, giteaRepoName, false)
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"has_issues": false}, suite.repositoryEdit)
+
+	err = suite.provider.SetIssuesEnabled(giteaOrgName, "missing-repo", true)
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GiteaProviderSuite) TestGetMergeConfig() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "test-repo", "allow_merge_commits": true, "allow_rebase": false,
+			"allow_squash_merge": true, "default_merge_style": "squash"}`)
+	})
+	mux.HandleFunc("/api/v1/repos/testorg/old-repo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "old-repo", "allow_merge_commits": true, "allow_rebase": true}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &GiteaProvider{URL: server.URL, Options: suite.provider.Options}
+
+	config, err := p.GetMergeConfig(giteaOrgName, giteaRepoName)
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.MergeConfig{AllowMergeCommit: true, AllowSquash: true, DefaultMethod: git.MergeMethodSquash}, config)
+
+	config, err = p.GetMergeConfig(giteaOrgName, "old-repo")
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.MergeConfig{AllowMergeCommit: true, AllowRebase: true}, config)
+
+	_, err = p.GetMergeConfig(giteaOrgName, "missing-repo")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GiteaProviderSuite) TestSetMergeConfig() {
+	err := suite.provider.SetMergeConfig(giteaOrgName, giteaRepoName, &git.MergeConfig{AllowSquash: true, DefaultMethod: git.MergeMethodSquash})
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{
+		"allow_merge_commits": false,
+		"allow_rebase":        false,
+		"allow_squash_merge":  true,
+		"default_merge_style": "squash",
+	}, suite.repositoryEdit)
+
+	err = suite.provider.SetMergeConfig(giteaOrgName, giteaRepoName, &git.MergeConfig{AllowMergeCommit: true, AllowRebase: true})
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{
+		"allow_merge_commits": true,
+		"allow_rebase":        true,
+		"allow_squash_merge":  false,
+	}, suite.repositoryEdit)
+
+	err = suite.provider.SetMergeConfig(giteaOrgName, giteaRepoName, &git.MergeConfig{})
+	suite.Require().NotNil(err)
+}
+
+func (suite *GiteaProviderSuite) TestSetRepositoryTopics() {
+	err := suite.provider.SetRepositoryTopics(giteaOrgName, giteaRepoName, []string{"git", "go"})
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"topics": []interface{}{"git", "go"}}, suite.topics)
+
+	err = suite.provider.SetRepositoryTopics(giteaOrgName, giteaRepoName, nil)
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"topics": []interface{}{}}, suite.topics)
+}
+
+func (suite *GiteaProviderSuite) TestListRepositoryTags() {
+	tags, err := suite.provider.ListRepositoryTags(giteaOrgName, giteaRepoName)
+	suite.Require().Nil(err)
+	suite.Require().Len(tags, pageSize+1)
+	suite.Require().Equal("v1.0.1", tags[0].Name)
+	suite.Require().Equal("Release 1.0.1\n", tags[0].Message)
+	suite.Require().Equal("0f3a9c1e5b7d2f4a6c8e0b2d4f6a8c0e2b4d6f8a", tags[0].SHA)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *tags[0].Date)
+	suite.Require().Equal("v1.0", tags[pageSize].Name)
+	suite.Require().Equal("6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", tags[pageSize].SHA)
+	suite.Require().Nil(tags[pageSize].Date)
+}
+
+func (suite *GiteaProviderSuite) TestListRepositoryTagsIgnoringPage() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "v1.0", "commit": {"sha": "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00"}}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &GiteaProvider{URL: server.URL, Options: suite.provider.Options}
+
+	tags, err := p.ListRepositoryTags(giteaOrgName, giteaRepoName)
+	suite.Require().Nil(err)
+	suite.Require().Len(tags, 1)
+}
+
+func (suite *GiteaProviderSuite) TestCreateTag() {
+	err := suite.provider.CreateTag(giteaOrgName, giteaRepoName, "v2.0", "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", "Release 2.0")
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{
+		"tag_name": "v2.0",
+		"target":   "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00",
+		"message":  "Release 2.0",
+	}, suite.createdTag)
+}
+
+func (suite *GiteaProviderSuite) TestCreateTagOnOldServers() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "test-repo"}`)
+	})
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo/tags", http.NotFound)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(giteaUserName, server.URL, "test", "gitea", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	err = p.CreateTag(giteaOrgName, giteaRepoName, "v2.0", "6ad2ae5e7a50f8a4b3ac5e8e2cfa1f6b3c1b8d00", "Release 2.0")
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GiteaProviderSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(giteaUserName, user.Login)
+	suite.Require().Equal("Test Person", user.Name)
+	suite.Require().Equal("testperson@example.com", user.Email)
+	suite.Require().Equal(suite.server.URL+"/testperson", user.URL)
+}
+
+func (suite *GiteaProviderSuite) TestGetUsers() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/users/", func(w http.ResponseWriter, r *http.Request) {
+		username := strings.TrimPrefix(r.URL.Path, "/api/v1/users/")
+		switch username {
+		case "nobody":
+			http.NotFound(w, r)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprintf(w, `{"id": 3, "login": "%s", "full_name": "User %s"}`, username, username)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(giteaUserName, server.URL, "test", "gitea", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	users, err := p.GetUsers([]string{"alice", "nobody"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 1)
+	suite.Require().Equal("User alice", users["alice"].Name)
+	suite.Require().Equal(server.URL+"/alice", users["alice"].URL)
+
+	_, err = p.GetUsers([]string{"broken"})
+
+	suite.Require().EqualError(err, "Could not get user broken: 500")
+}
+
+func (suite *GiteaProviderSuite) TestServerWithSubpath() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gitea/api/v1/user", util.GetMockAPIResponseFromFile("test_data/gitea", util.MethodMap{
+		"GET": "user.json",
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(giteaUserName, server.URL+"/gitea/", "test", "gitea", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	user, err := p.GetCurrentUser()
+	suite.Require().Nil(err)
+	suite.Require().Equal(giteaUserName, user.Login)
+}
+
+func (suite *GiteaProviderSuite) TestListMilestones() {
+	milestones, err := suite.provider.ListMilestones(giteaOrgName, giteaRepoName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(milestones, 2)
+	suite.Require().Equal(int64(1), milestones[0].ID)
+	suite.Require().Equal("v1.0", milestones[0].Title)
+	suite.Require().Equal(git.MilestoneStateClosed, milestones[0].State)
+	suite.Require().Equal(time.Date(2018, 10, 9, 7, 0, 0, 0, time.UTC), *milestones[0].DueOn)
+	suite.Require().Equal(8, milestones[0].ClosedIssues)
+	suite.Require().Equal(git.MilestoneStateOpen, milestones[1].State)
+	suite.Require().Nil(milestones[1].DueOn)
+	suite.Require().Equal(4, milestones[1].OpenIssues)
+}
+
+func (suite *GiteaProviderSuite) TestCreateAndCloseMilestone() {
+	due := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+	milestone, err := suite.provider.CreateMilestone(giteaOrgName, giteaRepoName, "v1.2", &due)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(int64(3), milestone.ID)
+	suite.Require().Equal(git.MilestoneStateOpen, milestone.State)
+	suite.Require().Equal("v1.2", suite.milestone["title"])
+	suite.Require().Equal("2018-12-01T00:00:00Z", suite.milestone["due_on"])
+
+	err = suite.provider.CloseMilestone(giteaOrgName, giteaRepoName, milestone.ID)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(git.MilestoneStateClosed, suite.milestone["state"])
+}
+
+func (suite *GiteaProviderSuite) TestInvitations() {
+	invites, err := suite.provider.ListInvitations()
+	suite.Require().Nil(invites)
+	suite.Require().True(git.IsNotSupported(err))
+
+	err = suite.provider.AcceptInvitation(1)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GiteaProviderSuite) TestGetCommitStatus() {
+	mux := http.NewServeMux()
+	// the statuses of the commit are listed oldest first
+	mux.HandleFunc("/api/v1/repos/testorg/test-repo/commits/6ad2ae5e/statuses", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 1, "state": "failure", "context": "ci/build", "created_at": "2018-10-09T10:00:00Z"},
+			{"id": 2, "state": "pending", "context": "ci/lint", "created_at": "2018-10-09T10:02:00Z"},
+			{"id": 3, "state": "success", "context": "ci/build", "created_at": "2018-10-09T10:05:00Z"}
+		]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(giteaUserName, server.URL, "test", "gitea", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	status, err := p.GetCommitStatus(giteaOrgName, giteaRepoName, "6ad2ae5e", "ci/build")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("success", status.State)
+
+	status, err = p.GetCommitStatus(giteaOrgName, giteaRepoName, "6ad2ae5e", "ci/lint")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("pending", status.State)
+
+	status, err = p.GetCommitStatus(giteaOrgName, giteaRepoName, "6ad2ae5e", "missing")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(status)
+}
+
+func (suite *GiteaProviderSuite) TestUpdateCommitStatus() {
+	status, err := suite.provider.UpdateCommitStatus(giteaOrgName, giteaRepoName, "6ad2ae5e", &git.RepoStatus{State: "success"})
+	suite.Require().Nil(status)
+	suite.Require().True(git.IsNotSupported(err))
+}
+
+func (suite *GiteaProviderSuite) TestKind() {
+	suite.Require().Equal(git.KindGitea, suite.provider.Kind())
+	suite.Require().Contains(git.KindGits, suite.provider.Kind())
+	suite.Require().True(suite.provider.IsGitea())
+}
+
+func TestGiteaProviderSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestGiteaProviderSuite in short mode")
+	} else {
+		suite.Run(t, new(GiteaProviderSuite))
+	}
+}
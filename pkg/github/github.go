@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -26,23 +27,41 @@ type GitHubProvider struct {
 	URL      string
 	Git      git.Gitter
 	Name     string
+	Options  git.ProviderOptions
+	// ForkPollConfig is how ForkRepository polls for the fork
+	ForkPollConfig git.ForkPollConfig
+
+	token string
+	// users caches the users looked up by GetUsers
+	users *git.UserCache
 }
 
-func NewProvider(username, serverURL, token, providerName string, git git.Gitter) (git.Provider, error) {
-	ctx := context.Background()
+func init() {
+	git.RegisterProviderFactory(git.KindGitHub, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, git.KindGitHub, gitter, options...)
+	})
+}
 
+func NewProvider(username, serverURL, token, providerName string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
 	provider := GitHubProvider{
 		URL:      serverURL,
 		Name:     providerName,
-		Context:  ctx,
 		Username: username,
-		Git:      git,
+		Git:      gitter,
+		Options:  git.NewProviderOptions(options...),
+		token:    token,
+		users:    &git.UserCache{},
 	}
+	provider.ForkPollConfig = git.DefaultForkPollConfig
+	ctx := provider.Options.RequestContext()
+	provider.Context = ctx
 
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
+	// the oauth2 client adds the token to the requests of the client in its context
+	tc := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, provider.Options.NewHTTPClient()), ts)
+	tc.Timeout = provider.Options.RequestTimeout
 
 	var err error
 	u := serverURL
@@ -110,6 +129,32 @@ func (p *GitHubProvider) ListOrganisations() ([]git.Organisation, error) {
 	return answer, nil
 }
 
+func (p *GitHubProvider) ListTeams(org string) ([]*git.Team, error) {
+	answer := []*git.Team{}
+	options := github.ListOptions{
+		PerPage: pageSize,
+	}
+	for {
+		teams, resp, err := p.Client.Teams.ListTeams(p.Context, org, &options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the teams of organisation %s due to: %s", org, err)
+		}
+
+		for _, team := range teams {
+			answer = append(answer, &git.Team{
+				Name:       team.GetName(),
+				Slug:       team.GetSlug(),
+				Permission: team.GetPermission(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
 func (p *GitHubProvider) IsUserInOrganisation(user string, org string) (bool, error) {
 	membership, _, err := p.Client.Organizations.GetOrgMembership(p.Context, user, org)
 	if err != nil {
@@ -156,6 +201,35 @@ func (p *GitHubProvider) ListRepositories(org string) ([]*git.Repository, error)
 	return answer, nil
 }
 
+func (p *GitHubProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	return nil, fmt.Errorf("list repositories paged: %w", git.ErrNotSupported)
+}
+
+func (p *GitHubProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	answer := []*git.Repository{}
+	options := &github.RepositoryListOptions{
+		Affiliation: "owner",
+		ListOptions: github.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		// a blank user lists the repositories of the authenticated user
+		repos, resp, err := p.Client.Repositories.List(p.Context, "", options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the repositories of the current user due to: %s", err)
+		}
+		for _, repo := range repos {
+			answer = append(answer, toGitHubRepo(asText(repo.Name), repo))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
 func (p *GitHubProvider) ListReleases(org string, name string) ([]*git.Release, error) {
 	owner := org
 	if owner == "" {
@@ -208,8 +282,11 @@ func toGitHubRelease(org string, name string, release *github.RepositoryRelease)
 }
 
 func (p *GitHubProvider) GetRepository(org string, name string) (*git.Repository, error) {
-	repo, _, err := p.Client.Repositories.Get(p.Context, org, name)
+	repo, resp, err := p.Client.Repositories.Get(p.Context, org, name)
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+		}
 		return nil, fmt.Errorf("Failed to get repository %s/%s due to: %s", org, name, err)
 	}
 	return toGitHubRepo(name, repo), nil
@@ -218,7 +295,7 @@ func (p *GitHubProvider) GetRepository(org string, name string) (*git.Repository
 func (p *GitHubProvider) CreateRepository(org string, name string, private bool) (*git.Repository, error) {
 	repoConfig := &github.Repository{
 		Name:    github.String(name),
-		Private: github.Bool(private),
+		Private: github.Bool(p.Options.IsPrivate(private)),
 	}
 	if org == p.Username {
 		org = ""
@@ -252,6 +329,12 @@ func toGitHubRepo(name string, repo *github.Repository) *git.Repository {
 		Fork:             asBool(repo.Fork),
 		Language:         asText(repo.Language),
 		Stars:            asInt(repo.StargazersCount),
+		Topics:           repo.Topics,
+		HasIssuesEnabled: asBool(repo.HasIssues),
+		Size:             asInt(repo.Size),
+		PushedAt:         asTime(repo.PushedAt),
+		UpdatedAt:        asTime(repo.UpdatedAt),
+		DefaultBranch:    asText(repo.DefaultBranch),
 	}
 }
 
@@ -273,18 +356,13 @@ func (p *GitHubProvider) ForkRepository(originalOrg string, name string, destina
 		if strings.Contains(err.Error(), "try again later") {
 			log.Warnf("Waiting for the fork of %s/%s to appear...\n", owner, name)
 			// lets wait for the fork to occur...
-			start := time.Now()
-			deadline := start.Add(time.Minute)
-			for {
-				time.Sleep(5 * time.Second)
+			err = p.ForkPollConfig.Poll(p.Options, func() error {
+				var err error
 				repo, _, err = p.Client.Repositories.Get(p.Context, owner, name)
-				if repo != nil && err == nil {
-					break
-				}
-				t := time.Now()
-				if t.After(deadline) {
-					return nil, fmt.Errorf("Gave up waiting for Repository %s/%s to appear: %s", owner, name, err)
-				}
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Gave up waiting for Repository %s/%s to appear: %s", owner, name, err)
 			}
 		} else {
 			return nil, fmt.Errorf("Failed to fork repository %s/%s%s due to: %s", originalOrg, name, msg, err)
@@ -300,19 +378,21 @@ func (p *GitHubProvider) ForkRepository(originalOrg string, name string, destina
 	return answer, nil
 }
 
+// SetForkPollConfig sets how often ForkRepository checks whether the fork is ready and how long it waits for it
+func (p *GitHubProvider) SetForkPollConfig(config git.ForkPollConfig) {
+	p.ForkPollConfig = config
+}
+
 func (p *GitHubProvider) CreateWebHook(data *git.WebhookArguments) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
 	owner := data.Owner
 	if owner == "" {
 		owner = p.Username
 	}
 	repo := data.Repo.Name
-	if repo == "" {
-		return fmt.Errorf("Missing property Repo")
-	}
 	webhookUrl := data.URL
-	if repo == "" {
-		return fmt.Errorf("Missing property URL")
-	}
 	hooks, _, err := p.Client.Repositories.ListHooks(p.Context, owner, repo, nil)
 	if err != nil {
 		log.Errorf("Error querying webhooks on %s/%s: %s\n", owner, repo, err)
@@ -426,6 +506,76 @@ func (p *GitHubProvider) UpdateWebHook(data *git.WebhookArguments) error {
 	return err
 }
 
+type hookDelivery struct {
+	ID          int64      `json:"id"`
+	GUID        string     `json:"guid"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	Redelivery  bool       `json:"redelivery"`
+	Duration    float64    `json:"duration"`
+	Status      string     `json:"status"`
+	StatusCode  int        `json:"status_code"`
+	Event       string     `json:"event"`
+	Action      string     `json:"action"`
+}
+
+func (p *GitHubProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	// the hook deliveries API is not supported by the client yet so lets make the request ourselves
+	u := fmt.Sprintf("repos/%s/%s/hooks/%d/deliveries?per_page=%d", owner, repo, hookID, pageSize)
+	req, err := p.Client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []*hookDelivery{}
+	_, err = p.Client.Do(p.Context, req, &deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list the deliveries of webhook %d on %s/%s due to: %s", hookID, owner, repo, err)
+	}
+
+	answer := []*git.WebHookDelivery{}
+	for _, delivery := range deliveries {
+		answer = append(answer, &git.WebHookDelivery{
+			ID:          delivery.ID,
+			GUID:        delivery.GUID,
+			Event:       delivery.Event,
+			Action:      delivery.Action,
+			Status:      delivery.Status,
+			StatusCode:  delivery.StatusCode,
+			Duration:    time.Duration(delivery.Duration * float64(time.Second)),
+			DeliveredAt: delivery.DeliveredAt,
+			Redelivery:  delivery.Redelivery,
+		})
+	}
+	return answer, nil
+}
+
+func (p *GitHubProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	// the hook deliveries API is not supported by the client yet so lets make the request ourselves
+	u := fmt.Sprintf("repos/%s/%s/hooks/%d/deliveries/%d/attempts", owner, repo, hookID, deliveryID)
+	req, err := p.Client.NewRequest("POST", u, nil)
+	if err != nil {
+		return err
+	}
+	_, err = p.Client.Do(p.Context, req, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to redeliver %d of webhook %d on %s/%s due to: %s", deliveryID, hookID, owner, repo, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	_, err := p.Client.Repositories.PingHook(p.Context, owner, repo, hookID)
+	if err != nil {
+		return fmt.Errorf("Failed to ping webhook %d on %s/%s due to: %s", hookID, owner, repo, err)
+	}
+	return nil
+}
+
+// newPullRequest is a pull request to create, which can be a draft
+type newPullRequest struct {
+	*github.NewPullRequest
+	Draft bool `json:"draft,omitempty"`
+}
+
 func (p *GitHubProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.PullRequest, error) {
 	owner := data.Repository.Organisation
 	repo := data.Repository.Name
@@ -446,16 +596,37 @@ func (p *GitHubProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 	if base != "" {
 		config.Base = github.String(base)
 	}
-	pr, _, err := p.Client.PullRequests.Create(p.Context, owner, repo, config)
+	// draft pull requests are not supported by the client yet so lets make the request ourselves
+	u := fmt.Sprintf("repos/%s/%s/pulls", owner, repo)
+	req, err := p.Client.NewRequest("POST", u, &newPullRequest{NewPullRequest: config, Draft: data.Draft})
+	if err != nil {
+		return nil, err
+	}
+	pr := &github.PullRequest{}
+	_, err = p.Client.Do(p.Context, req, pr)
 	if err != nil {
 		return nil, err
 	}
-	return &git.PullRequest{
+	answer := &git.PullRequest{
 		URL:    notNullString(pr.HTMLURL),
 		Owner:  owner,
 		Repo:   repo,
 		Number: pr.Number,
-	}, nil
+	}
+	if len(data.Assignees) > 0 {
+		_, _, err = p.Client.Issues.AddAssignees(p.Context, owner, repo, pr.GetNumber(), data.Assignees)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to assign pull request %d on %s/%s due to: %s", pr.GetNumber(), owner, repo, err)
+		}
+	}
+	if len(data.Reviewers) > 0 {
+		reviewers := github.ReviewersRequest{Reviewers: data.Reviewers}
+		_, _, err = p.Client.PullRequests.RequestReviewers(p.Context, owner, repo, pr.GetNumber(), reviewers)
+		if err != nil {
+			log.Warnf("Failed to request reviews of pull request %d on %s/%s due to: %s\n", pr.GetNumber(), owner, repo, err)
+		}
+	}
+	return answer, nil
 }
 
 func (p *GitHubProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
@@ -467,6 +638,12 @@ func (p *GitHubProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	if err != nil {
 		return err
 	}
+	updatePullRequest(pr, result)
+	return nil
+}
+
+// updatePullRequest copies the fields of a GitHub pull request into the git pull request
+func updatePullRequest(pr *git.PullRequest, result *github.PullRequest) {
 	head := result.Head
 	if head != nil {
 		pr.LastCommitSha = notNullString(head.SHA)
@@ -497,6 +674,9 @@ func (p *GitHubProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	if result.Head != nil {
 		pr.HeadRef = result.Head.Ref
 	}
+	if result.Base != nil {
+		pr.BaseRef = result.Base.Ref
+	}
 	if result.StatusesURL != nil {
 		pr.StatusesURL = result.StatusesURL
 	}
@@ -512,7 +692,11 @@ func (p *GitHubProvider) UpdatePullRequestStatus(pr *git.PullRequest) error {
 	if result.Body != nil {
 		pr.Body = *result.Body
 	}
-	return nil
+	labels := []git.Label{}
+	for _, label := range result.Labels {
+		labels = append(labels, toGitHubLabel(label))
+	}
+	pr.Labels = labels
 }
 
 func (p *GitHubProvider) GetPullRequest(owner string, repo *git.Repository, number int) (*git.PullRequest, error) {
@@ -598,6 +782,86 @@ func (p *GitHubProvider) GetPullRequestCommits(owner string, repository *git.Rep
 	return answer, nil
 }
 
+func (p *GitHubProvider) ListPullRequests(owner string, repository *git.Repository, state string) ([]*git.PullRequest, error) {
+	repo := repository.Name
+	answer := []*git.PullRequest{}
+	options := &github.PullRequestListOptions{
+		State: state,
+		ListOptions: github.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		prs, resp, err := p.Client.PullRequests.List(p.Context, owner, repo, options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the pull requests of %s/%s due to: %s", owner, repo, err)
+		}
+		for _, result := range prs {
+			pr := &git.PullRequest{
+				URL:    notNullString(result.HTMLURL),
+				Owner:  owner,
+				Repo:   repo,
+				Number: result.Number,
+			}
+			updatePullRequest(pr, result)
+			answer = append(answer, pr)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
+// ListPullRequestsByAuthor filters the pull requests of the repository as the API only filters by the owner and
+// branch of their head, which isn't known here
+func (p *GitHubProvider) ListPullRequestsByAuthor(owner string, repository *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	prs, err := p.ListPullRequests(owner, repository, state)
+	if err != nil {
+		return nil, err
+	}
+	return git.FilterPullRequestsByAuthor(prs, author), nil
+}
+
+func (p *GitHubProvider) GetPullRequestByBranch(owner string, repository *git.Repository, branch string) (*git.PullRequest, error) {
+	repo := repository.Name
+	options := &github.PullRequestListOptions{
+		State: git.PullRequestStateOpen,
+		Head:  owner + ":" + branch,
+	}
+	prs, _, err := p.Client.PullRequests.List(p.Context, owner, repo, options)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find the pull request from branch %s of %s/%s due to: %s", branch, owner, repo, err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	pr := &git.PullRequest{
+		URL:    notNullString(prs[0].HTMLURL),
+		Owner:  owner,
+		Repo:   repo,
+		Number: prs[0].Number,
+	}
+	updatePullRequest(pr, prs[0])
+	return pr, nil
+}
+
+func (p *GitHubProvider) ClosePullRequest(pr *git.PullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for git.PullRequest %#v", pr)
+	}
+	closed := github.PullRequest{
+		State: github.String(git.PullRequestStateClosed),
+	}
+	result, _, err := p.Client.PullRequests.Edit(p.Context, pr.Owner, pr.Repo, *pr.Number, &closed)
+	if err != nil {
+		return fmt.Errorf("Failed to close pull request %d of %s/%s due to: %s", *pr.Number, pr.Owner, pr.Repo, err)
+	}
+	updatePullRequest(pr, result)
+	return nil
+}
+
 func (p *GitHubProvider) MergePullRequest(pr *git.PullRequest, message string) error {
 	if pr.Number == nil {
 		return fmt.Errorf("Missing Number for git.PullRequest %#v", pr)
@@ -644,21 +908,85 @@ func (p *GitHubProvider) CreateIssueComment(owner string, repo string, number in
 	return nil
 }
 
+func (p *GitHubProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	_, _, err := p.Client.Issues.AddLabelsToIssue(p.Context, owner, repo, number, labels)
+	if err != nil {
+		return fmt.Errorf("Failed to add labels to %s/%s#%d due to: %s", owner, repo, number, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	r, err := p.Client.Issues.RemoveLabelForIssue(p.Context, owner, repo, number, label)
+	// a label which isn't on the issue is not found
+	if r != nil && r.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to remove label %s from %s/%s#%d due to: %s", label, owner, repo, number, err)
+	}
+	return nil
+}
+
 func (p *GitHubProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
 	ref := pr.LastCommitSha
 	if ref == "" {
 		return "", fmt.Errorf("Missing String for LastCommitSha %#v", pr)
 	}
-	results, _, err := p.Client.Repositories.ListStatuses(p.Context, pr.Owner, pr.Repo, ref, nil)
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, ref)
 	if err != nil {
 		return "", err
 	}
-	for _, result := range results {
-		if result.State != nil {
-			return *result.State, nil
+	return git.OverallState(statuses), nil
+}
+
+func (p *GitHubProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	return nil, fmt.Errorf("get pull request approval state: %w", git.ErrNotSupported)
+}
+
+func (p *GitHubProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for git.PullRequest %#v", pr)
+	}
+	result, _, err := p.Client.PullRequests.Get(p.Context, pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get pull request %d of %s/%s due to: %s", *pr.Number, pr.Owner, pr.Repo, err)
+	}
+	return &git.DiffStat{
+		Additions:    result.GetAdditions(),
+		Deletions:    result.GetDeletions(),
+		ChangedFiles: result.GetChangedFiles(),
+	}, nil
+}
+
+func (p *GitHubProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for pull request %s/%s", pr.Owner, pr.Repo)
+	}
+	answer := []*git.PullRequestEvent{}
+	options := &github.ListOptions{
+		PerPage: pageSize,
+	}
+	for {
+		events, resp, err := p.Client.Issues.ListIssueTimeline(p.Context, pr.Owner, pr.Repo, *pr.Number, options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the activity of pull request %s/%s#%d due to: %s", pr.Owner, pr.Repo, *pr.Number, err)
+		}
+		for _, event := range events {
+			answer = append(answer, &git.PullRequestEvent{
+				ID:        strconv.FormatInt(notNullInt64(event.ID), 10),
+				Action:    notNullString(event.Event),
+				Actor:     toGitHubUser(event.Actor),
+				CreatedAt: event.CreatedAt,
+				CommitSHA: notNullString(event.CommitID),
+			})
 		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
 	}
-	return "", fmt.Errorf("Could not find a status for repository %s/%s with ref %s", pr.Owner, pr.Repo, ref)
+	return answer, nil
 }
 
 func (p *GitHubProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
@@ -678,13 +1006,50 @@ func (p *GitHubProvider) ListCommitStatus(org string, repo string, sha string) (
 			TargetURL:   notNullString(result.TargetURL),
 			State:       notNullString(result.State),
 			Description: notNullString(result.Description),
+			CreatedAt:   result.CreatedAt,
 		}
 		answer = append(answer, status)
 	}
 	return answer, nil
 }
 
+func (p *GitHubProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	if sha == "" {
+		return nil, fmt.Errorf("Missing String for sha %s/%s", org, repo)
+	}
+	// the combined status only contains the latest status for each context
+	options := &github.ListOptions{
+		PerPage: pageSize,
+	}
+	for {
+		combined, resp, err := p.Client.Repositories.GetCombinedStatus(p.Context, org, repo, sha, options)
+		if err != nil {
+			return nil, fmt.Errorf("Could not find a status for repository %s/%s with ref %s due to: %s", org, repo, sha, err)
+		}
+		for _, result := range combined.Statuses {
+			if notNullString(result.Context) == context {
+				return &git.RepoStatus{
+					ID:          strconv.FormatInt(notNullInt64(result.ID), 10),
+					Context:     notNullString(result.Context),
+					URL:         notNullString(result.URL),
+					TargetURL:   notNullString(result.TargetURL),
+					State:       notNullString(result.State),
+					Description: notNullString(result.Description),
+				}, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	return nil, nil
+}
+
 func (p *GitHubProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
+	if err := status.NormalizeContext(git.MaxStatusContextLength); err != nil {
+		return &git.RepoStatus{}, err
+	}
 	id64 := int64(0)
 	if status.ID != "" {
 		id, err := strconv.Atoi(status.ID)
@@ -716,17 +1081,24 @@ func (p *GitHubProvider) UpdateCommitStatus(org string, repo string, sha string,
 }
 
 func (p *GitHubProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	fileContent, _, _, err := p.Client.Repositories.GetContents(p.Context, org, name, path, &github.RepositoryContentGetOptions{Ref: ref})
+	fileContent, _, resp, err := p.Client.Repositories.GetContents(p.Context, org, name, path, &github.RepositoryContentGetOptions{Ref: ref})
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("file %s in %s/%s at %s: %w", path, org, name, ref, git.ErrNotFound)
+		}
 		return nil, err
 	}
 	if fileContent != nil {
+		content, err := fileContent.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode file %s from %s/%s due to: %s", path, org, name, err)
+		}
 		return &git.FileContent{
 			Name:        notNullString(fileContent.Name),
 			Url:         notNullString(fileContent.URL),
 			Path:        notNullString(fileContent.Path),
 			Type:        notNullString(fileContent.Type),
-			Content:     notNullString(fileContent.Content),
+			Content:     content,
 			DownloadUrl: notNullString(fileContent.DownloadURL),
 			Encoding:    notNullString(fileContent.Encoding),
 			GitUrl:      notNullString(fileContent.GitURL),
@@ -739,6 +1111,89 @@ func (p *GitHubProvider) GetContent(org string, name string, path string, ref st
 	}
 }
 
+// ResolveRef returns the SHA of the commit the branch or tag points at, looking for a branch first.
+// Annotated tags are resolved to the commit they tag
+func (p *GitHubProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	refs := []string{"heads/" + ref, "tags/" + ref}
+	if strings.HasPrefix(ref, "refs/") {
+		refs = []string{ref}
+	}
+	for _, r := range refs {
+		reference, resp, err := p.Client.Git.GetRef(p.Context, org, name, r)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return "", fmt.Errorf("Failed to get ref %s of %s/%s due to: %s", r, org, name, err)
+		}
+		object := reference.GetObject()
+		if object.GetType() == "tag" {
+			tag, _, err := p.Client.Git.GetTag(p.Context, org, name, object.GetSHA())
+			if err != nil {
+				return "", fmt.Errorf("Failed to get tag %s of %s/%s due to: %s", ref, org, name, err)
+			}
+			return tag.GetObject().GetSHA(), nil
+		}
+		return object.GetSHA(), nil
+	}
+	return "", fmt.Errorf("ref %s of %s/%s: %w", ref, org, name, git.ErrNotFound)
+}
+
+func (p *GitHubProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	options := &github.CommitsListOptions{
+		SHA:  ref,
+		Path: path,
+		ListOptions: github.ListOptions{
+			PerPage: 1,
+		},
+	}
+	commits, _, err := p.Client.Repositories.ListCommits(p.Context, org, name, options)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list the commits of %s in %s/%s due to: %s", path, org, name, err)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return toGitHubCommit(commits[0]), nil
+}
+
+// BranchesContainingCommit is not supported as GitHub has no API for it. The branches-where-head endpoint only finds the
+// branches whose head is the commit, and comparing every branch to the commit takes a request per branch
+func (p *GitHubProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	return nil, fmt.Errorf("list branches containing commit: %w", git.ErrNotSupported)
+}
+
+func toGitHubCommit(commit *github.RepositoryCommit) *git.Commit {
+	answer := &git.Commit{
+		SHA: commit.GetSHA(),
+		URL: commit.GetHTMLURL(),
+	}
+	if commit.Commit != nil {
+		answer.Message = commit.Commit.GetMessage()
+		if commit.Commit.Author != nil {
+			answer.Author = &git.User{
+				Name:  commit.Commit.Author.GetName(),
+				Email: commit.Commit.Author.GetEmail(),
+			}
+		}
+		if commit.Commit.Committer != nil {
+			answer.Committer = &git.User{
+				Name:  commit.Commit.Committer.GetName(),
+				Email: commit.Commit.Committer.GetEmail(),
+			}
+		}
+	}
+	if commit.Author != nil {
+		if answer.Author == nil {
+			answer.Author = &git.User{}
+		}
+		answer.Author.Login = commit.Author.GetLogin()
+		answer.Author.AvatarURL = commit.Author.GetAvatarURL()
+		answer.Author.URL = commit.Author.GetHTMLURL()
+	}
+	return answer
+}
+
 func notNullInt64(n *int64) int64 {
 	if n != nil {
 		return *n
@@ -792,6 +1247,135 @@ func (p *GitHubProvider) ValidateRepositoryName(org string, name string) error {
 	return err
 }
 
+// GetMergeConfig returns the merge methods allowed on the repository. GitHub has no default merge method
+func (p *GitHubProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	repo, _, err := p.Client.Repositories.Get(p.Context, org, name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get repository %s/%s due to: %s", org, name, err)
+	}
+	return &git.MergeConfig{
+		AllowMergeCommit: repo.GetAllowMergeCommit(),
+		AllowSquash:      repo.GetAllowSquashMerge(),
+		AllowRebase:      repo.GetAllowRebaseMerge(),
+	}, nil
+}
+
+// SetMergeConfig sets the merge methods allowed on the repository, the default merge method is ignored
+func (p *GitHubProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	if !config.AllowMergeCommit && !config.AllowSquash && !config.AllowRebase {
+		return fmt.Errorf("at least one merge method must be allowed on repository %s/%s", org, name)
+	}
+	repoConfig := &github.Repository{
+		AllowMergeCommit: github.Bool(config.AllowMergeCommit),
+		AllowSquashMerge: github.Bool(config.AllowSquash),
+		AllowRebaseMerge: github.Bool(config.AllowRebase),
+	}
+	_, _, err := p.Client.Repositories.Edit(p.Context, org, name, repoConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to edit repository %s/%s due to: %s", org, name, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	_, resp, err := p.Client.Repositories.ReplaceAllTopics(p.Context, org, name, topics)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+		}
+		return fmt.Errorf("Failed to set the topics of repository %s/%s due to: %s", org, name, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	answer := []*git.Milestone{}
+	options := &github.MilestoneListOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		milestones, resp, err := p.Client.Issues.ListMilestones(p.Context, org, name, options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the milestones of %s/%s due to: %s", org, name, err)
+		}
+		for _, milestone := range milestones {
+			answer = append(answer, toGitHubMilestone(milestone))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
+func (p *GitHubProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	milestone, _, err := p.Client.Issues.CreateMilestone(p.Context, org, name, &github.Milestone{
+		Title: &title,
+		DueOn: due,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create milestone %s on repository %s/%s due to: %s", title, org, name, err)
+	}
+	return toGitHubMilestone(milestone), nil
+}
+
+// CloseMilestone closes the milestone with the given number, which is the ID of GitHub milestones
+func (p *GitHubProvider) CloseMilestone(org string, name string, id int64) error {
+	_, _, err := p.Client.Issues.EditMilestone(p.Context, org, name, int(id), &github.Milestone{
+		State: github.String(git.MilestoneStateClosed),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to close milestone %d of repository %s/%s due to: %s", id, org, name, err)
+	}
+	return nil
+}
+
+func toGitHubMilestone(milestone *github.Milestone) *git.Milestone {
+	return &git.Milestone{
+		ID:           int64(milestone.GetNumber()),
+		Title:        milestone.GetTitle(),
+		State:        milestone.GetState(),
+		DueOn:        milestone.DueOn,
+		OpenIssues:   milestone.GetOpenIssues(),
+		ClosedIssues: milestone.GetClosedIssues(),
+	}
+}
+
+func (p *GitHubProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	answer := []*git.Contributor{}
+	options := &github.ListContributorsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		contributors, resp, err := p.Client.Repositories.ListContributors(p.Context, org, name, options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the contributors of %s/%s due to: %s", org, name, err)
+		}
+		for _, contributor := range contributors {
+			answer = append(answer, &git.Contributor{
+				User: &git.User{
+					Login:     contributor.GetLogin(),
+					URL:       contributor.GetHTMLURL(),
+					AvatarURL: contributor.GetAvatarURL(),
+				},
+				Contributions: contributor.GetContributions(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	git.SortContributors(answer)
+	return answer, nil
+}
+
 func (p *GitHubProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
 	release := &github.RepositoryRelease{}
 	rel, r, err := p.Client.Repositories.GetReleaseByTag(p.Context, owner, repo, tag)
@@ -852,8 +1436,18 @@ func (p *GitHubProvider) GetIssue(org string, name string, number int) (*git.Iss
 }
 
 func (p *GitHubProvider) SearchIssues(org string, name string, filter string) ([]*git.Issue, error) {
-	opts := &github.IssueListByRepoOptions{}
-	return p.searchIssuesWithOptions(org, name, opts)
+	query := git.ParseIssueQuery(filter)
+	opts := &github.IssueListByRepoOptions{
+		State:   query.State,
+		Creator: query.Author,
+		Labels:  query.Labels,
+	}
+	issues, err := p.searchIssuesWithOptions(org, name, opts)
+	if err != nil {
+		return issues, err
+	}
+	// the issues API has no free text search
+	return query.FilterIssues(issues), nil
 }
 
 func (p *GitHubProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*git.Issue, error) {
@@ -908,10 +1502,17 @@ func (p *GitHubProvider) CreateIssue(owner string, repo string, issue *git.Issue
 			labels = append(labels, name)
 		}
 	}
+	assignees := []string{}
+	for _, assignee := range issue.Assignees {
+		if assignee.Login != "" {
+			assignees = append(assignees, assignee.Login)
+		}
+	}
 	config := &github.IssueRequest{
-		Title:  &issue.Title,
-		Body:   &issue.Body,
-		Labels: &labels,
+		Title:     &issue.Title,
+		Body:      &issue.Body,
+		Labels:    &labels,
+		Assignees: &assignees,
 	}
 	i, _, err := p.Client.Issues.Create(p.Context, owner, repo, config)
 	if err != nil {
@@ -990,6 +1591,20 @@ func (p *GitHubProvider) HasIssues() bool {
 	return true
 }
 
+func (p *GitHubProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	if org == "" {
+		org = p.Username
+	}
+	config := &github.Repository{
+		HasIssues: github.Bool(enabled),
+	}
+	_, _, err := p.Client.Repositories.Edit(p.Context, org, name, config)
+	if err != nil {
+		return fmt.Errorf("Failed to edit repository %s/%s due to: %s", org, name, err)
+	}
+	return nil
+}
+
 func (p *GitHubProvider) IsGitHub() bool {
 	return true
 }
@@ -1039,14 +1654,45 @@ func (p *GitHubProvider) BranchArchiveURL(org string, name string, branch string
 }
 
 func (p *GitHubProvider) CurrentUsername() string {
+	if p.Username == "" {
+		user, err := p.GetCurrentUser()
+		if err == nil {
+			p.Username = user.Login
+		}
+	}
 	return p.Username
 }
 
+func (p *GitHubProvider) GetCurrentUser() (*git.User, error) {
+	user, _, err := p.Client.Users.Get(p.Context, "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the authenticated user due to: %s", err)
+	}
+	return &git.User{
+		Login:     user.GetLogin(),
+		Name:      user.GetName(),
+		AvatarURL: user.GetAvatarURL(),
+		URL:       user.GetHTMLURL(),
+		Email:     user.GetEmail(),
+	}, nil
+}
+
 func (p *GitHubProvider) UserInfo(username string) *git.User {
-	user, _, err := p.Client.Users.Get(p.Context, username)
-	if user == nil || err != nil {
-		log.Error("Unable to fetch user info for " + username + "\n")
-		return nil
+	user, err := p.getUser(username)
+	if err != nil {
+		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
+	}
+	return user
+}
+
+// getUser looks up a user, or returns nil if there is no such user
+func (p *GitHubProvider) getUser(username string) (*git.User, error) {
+	user, resp, err := p.Client.Users.Get(p.Context, username)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", username, err)
 	}
 
 	return &git.User{
@@ -1055,7 +1701,160 @@ func (p *GitHubProvider) UserInfo(username string) *git.User {
 		AvatarURL: user.GetAvatarURL(),
 		URL:       user.GetHTMLURL(),
 		Email:     user.GetEmail(),
+	}, nil
+}
+
+func (p *GitHubProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return p.users.GetUsers(usernames, p.getUser)
+}
+
+func (p *GitHubProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := p.GetRepository(org, name)
+	if err != nil {
+		return err
 	}
+	if branch == repo.DefaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, git.ErrDefaultBranch)
+	}
+	resp, err := p.Client.Git.DeleteRef(p.Context, org, name, "heads/"+branch)
+	if err != nil {
+		// github answers 422 rather than 404 when the ref doesn't exist
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return nil
+		}
+		return fmt.Errorf("Failed to delete branch %s of repository %s/%s due to: %s", branch, org, name, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	sha, err := p.ResolveRef(org, name, fromRef)
+	if err != nil {
+		return err
+	}
+	ref := "refs/heads/" + branch
+	_, resp, err := p.Client.Git.CreateRef(p.Context, org, name, &github.Reference{
+		Ref: &ref,
+		Object: &github.GitObject{
+			SHA: &sha,
+		},
+	})
+	if err != nil {
+		// github answers 422 when the ref already exists
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+			return fmt.Errorf("create branch %s of %s/%s: %w", branch, org, name, git.ErrAlreadyExists)
+		}
+		return fmt.Errorf("Failed to create branch %s of repository %s/%s due to: %s", branch, org, name, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	tagObject, _, err := p.Client.Git.CreateTag(p.Context, org, name, &github.Tag{
+		Tag:     &tag,
+		Message: &message,
+		Object: &github.GitObject{
+			Type: github.String("commit"),
+			SHA:  &sha,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to create tag %s on repository %s/%s due to: %s", tag, org, name, err)
+	}
+	ref := "refs/tags/" + tag
+	_, _, err = p.Client.Git.CreateRef(p.Context, org, name, &github.Reference{
+		Ref: &ref,
+		Object: &github.GitObject{
+			SHA: tagObject.SHA,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to create ref %s on repository %s/%s due to: %s", ref, org, name, err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	ref, r, err := p.Client.Git.GetRef(p.Context, org, name, "tags/"+tag)
+	if r != nil && r.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	answer := &git.GitTag{
+		Name: tag,
+	}
+	if ref.Object == nil {
+		return answer, nil
+	}
+	if notNullString(ref.Object.Type) != "tag" {
+		// lightweight tags point straight at the commit
+		answer.SHA = notNullString(ref.Object.SHA)
+		return answer, nil
+	}
+	tagObject, _, err := p.Client.Git.GetTag(p.Context, org, name, notNullString(ref.Object.SHA))
+	if err != nil {
+		return nil, err
+	}
+	answer.Message = notNullString(tagObject.Message)
+	if tagObject.Object != nil {
+		answer.SHA = notNullString(tagObject.Object.SHA)
+	}
+	return answer, nil
+}
+
+func (p *GitHubProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	answer := []*git.GitTag{}
+	options := github.ListOptions{
+		PerPage: pageSize,
+	}
+	for {
+		tags, resp, err := p.Client.Repositories.ListTags(p.Context, org, name, &options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the tags of repository %s/%s due to: %s", org, name, err)
+		}
+
+		for _, tag := range tags {
+			answer = append(answer, &git.GitTag{
+				Name: tag.GetName(),
+				SHA:  tag.GetCommit().GetSHA(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
+func (p *GitHubProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	// naming the repository too compares with a fork whose name differs from upstream
+	head := org + ":" + name + ":" + branch
+	comparison, _, err := p.Client.Repositories.CompareCommits(p.Context, upstreamOrg, upstreamName, branch, head)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed to compare %s/%s with %s/%s on branch %s due to: %s", org, name, upstreamOrg, upstreamName, branch, err)
+	}
+	return asInt(comparison.AheadBy), asInt(comparison.BehindBy), nil
+}
+
+func (p *GitHubProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	// the merge-upstream API is not supported by the client yet so lets make the request ourselves
+	u := fmt.Sprintf("repos/%s/%s/merge-upstream", org, name)
+	req, err := p.Client.NewRequest("POST", u, map[string]string{"branch": branch})
+	if err != nil {
+		return err
+	}
+	r, err := p.Client.Do(p.Context, req, nil)
+	if err == nil {
+		return nil
+	}
+	if r == nil || r.StatusCode != 404 {
+		return fmt.Errorf("Failed to sync fork %s/%s with %s/%s due to: %s", org, name, upstreamOrg, upstreamName, err)
+	}
+	// older GitHub Enterprise servers do not have the API so fall back to merging locally
+	return git.SyncForkWithGit(p, p.Git, p.Options, p.token, org, name, upstreamOrg, upstreamName, branch)
 }
 
 func (p *GitHubProvider) AddCollaborator(user string, organisation string, repo string) error {
@@ -1067,13 +1866,51 @@ func (p *GitHubProvider) AddCollaborator(user string, organisation string, repo
 	return nil
 }
 
-func (p *GitHubProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	return p.Client.Users.ListInvitations(p.Context, &github.ListOptions{})
+func (p *GitHubProvider) ListInvitations() ([]*git.Invitation, error) {
+	answer := []*git.Invitation{}
+	options := &github.ListOptions{
+		PerPage: pageSize,
+	}
+	for {
+		invitations, resp, err := p.Client.Users.ListInvitations(p.Context, options)
+		if err != nil {
+			return answer, fmt.Errorf("Failed to list the invitations of the current user due to: %s", err)
+		}
+		for _, invitation := range invitations {
+			answer = append(answer, toGitHubInvitation(invitation))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
+func toGitHubInvitation(invitation *github.RepositoryInvitation) *git.Invitation {
+	answer := &git.Invitation{
+		ID:          invitation.GetID(),
+		Permissions: invitation.GetPermissions(),
+		URL:         invitation.GetHTMLURL(),
+		CreatedAt:   asTime(invitation.CreatedAt),
+	}
+	if repo := invitation.Repo; repo != nil {
+		answer.Repository = toGitHubRepo(repo.GetName(), repo)
+		answer.Repository.Organisation = repo.GetOwner().GetLogin()
+	}
+	if invitation.Inviter != nil {
+		answer.Inviter = toGitHubUser(invitation.Inviter)
+	}
+	return answer
 }
 
-func (p *GitHubProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func (p *GitHubProvider) AcceptInvitation(ID int64) error {
+	_, err := p.Client.Users.AcceptInvitation(p.Context, ID)
+	if err != nil {
+		return fmt.Errorf("Failed to accept invitation %d due to: %s", ID, err)
+	}
 	log.Infof("Automatically accepted invitation: %v for the pipeline user.\n", ID)
-	return p.Client.Users.AcceptInvitation(p.Context, ID)
+	return nil
 }
 
 func asBool(b *bool) bool {
@@ -1096,3 +1933,10 @@ func asText(text *string) string {
 	}
 	return ""
 }
+
+func asTime(t *github.Timestamp) *time.Time {
+	if t != nil {
+		return &t.Time
+	}
+	return nil
+}
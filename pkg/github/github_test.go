@@ -0,0 +1,1009 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+	"github.com/wbrefvem/go-gits/pkg/git"
+)
+
+const (
+	githubUserName     = "test-user"
+	githubUpstreamOrg  = "upstream-org"
+	githubRepoName     = "test-repo"
+	githubDefaultRef   = "master"
+	githubProviderName = "github"
+)
+
+type GitHubProviderSuite struct {
+	suite.Suite
+	mux      *http.ServeMux
+	server   *httptest.Server
+	provider *GitHubProvider
+}
+
+var githubRouter = util.Router{
+	"/api/v3/repos/upstream-org/test-repo/compare/master...test-user:test-repo:master": util.MethodMap{
+		"GET": "compare.json",
+	},
+	"/api/v3/repos/test-user/test-repo/commits": util.MethodMap{
+		"GET": "commits.json",
+	},
+	"/api/v3/repos/test-user/test-repo/hooks/12345/deliveries": util.MethodMap{
+		"GET": "hook-deliveries.json",
+	},
+	"/api/v3/repos/test-user/test-repo/hooks/12345/deliveries/123456789/attempts": util.MethodMap{
+		"POST": "empty.json",
+	},
+	"/api/v3/repos/test-user/test-repo/hooks/12345/pings": util.MethodMap{
+		"POST": "empty.json",
+	},
+	"/api/v3/repos/test-user/test-repo/issues": util.MethodMap{
+		"POST": "issues.created.json",
+	},
+	"/api/v3/repos/test-user/test-repo": util.MethodMap{
+		"GET":   "repos.test-repo.json",
+		"PATCH": "repos.test-repo.json",
+	},
+	"/api/v3/repos/test-user/test-repo/pulls": util.MethodMap{
+		"GET": "pulls.json",
+	},
+	"/api/v3/repos/test-user/test-repo/issues/3/timeline": util.MethodMap{
+		"GET": "issues.3.timeline.json",
+	},
+	"/api/v3/repos/test-user/test-repo/pulls/4": util.MethodMap{
+		"GET": "pulls.4.json",
+	},
+	"/api/v3/repos/test-user/test-repo/pulls/3": util.MethodMap{
+		"PATCH": "pulls.3.json",
+	},
+	"/api/v3/repos/test-user/test-repo/contents/CODEOWNERS": util.MethodMap{
+		"GET": "contents.CODEOWNERS.json",
+	},
+	"/api/v3/repos/test-user/test-repo/tags": util.MethodMap{
+		"GET": "tags.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/refs/heads/feature": util.MethodMap{
+		"DELETE": "empty.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/refs/heads/master": util.MethodMap{
+		"GET": "git.refs.heads.master.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/refs/tags/v1.0.0": util.MethodMap{
+		"GET": "git.refs.tags.v1.0.0.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/tags": util.MethodMap{
+		"POST": "git.tags.v1.0.0.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/refs": util.MethodMap{
+		"POST": "git.refs.tags.v1.0.0.json",
+	},
+	"/api/v3/repos/test-user/test-repo/git/tags/940bd336248efae0f9ee5bc7b2d5c985887b16ac": util.MethodMap{
+		"GET": "git.tags.v1.0.0.json",
+	},
+	"/api/v3/repos/test-user/test-repo/commits/6dcb09b5b57875f334f61aebed695e2e4193db5e/status": util.MethodMap{
+		"GET": "status.json",
+	},
+	"/api/v3/repos/test-user/test-repo/milestones": util.MethodMap{
+		"GET": "milestones.json",
+	},
+	"/api/v3/user": util.MethodMap{
+		"GET": "user.json",
+	},
+	"/api/v3/user/repos": util.MethodMap{
+		"GET": "user-repos.json",
+	},
+	"/api/v3/orgs/upstream-org/teams": util.MethodMap{
+		"GET": "teams.json",
+	},
+}
+
+func (suite *GitHubProviderSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+	for path, methodMap := range githubRouter {
+		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/github", methodMap))
+	}
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	// a non github.com URL gives an enterprise client talking to the mock server
+	p, err := NewProvider(githubUserName, suite.server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = p.(*GitHubProvider)
+	suite.Require().True(ok)
+}
+
+func (suite *GitHubProviderSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+func (suite *GitHubProviderSuite) TestGetAheadBehind() {
+	ahead, behind, err := suite.provider.GetAheadBehind(githubUserName, githubRepoName, githubUpstreamOrg, githubRepoName, githubDefaultRef)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(1, ahead)
+	suite.Require().Equal(3, behind)
+}
+
+func (suite *GitHubProviderSuite) TestCreateIssue() {
+	issue, err := suite.provider.CreateIssue(githubUserName, githubRepoName, &git.Issue{
+		Title:     "Something is broken",
+		Body:      "Steps to reproduce",
+		Labels:    []git.Label{{Name: "bug"}},
+		Assignees: []git.User{{Login: githubUserName}},
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(issue)
+	suite.Require().Equal(7, *issue.Number)
+	suite.Require().Len(issue.Labels, 1)
+	suite.Require().Equal("bug", issue.Labels[0].Name)
+	suite.Require().Len(issue.Assignees, 1)
+	suite.Require().Equal(githubUserName, issue.Assignees[0].Login)
+}
+
+func (suite *GitHubProviderSuite) TestIssueLabels() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	added := []string{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/issues/7/labels", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&added))
+		fmt.Fprint(w, `[{"name": "bug"}, {"name": "triage"}]`)
+	})
+	removed := []string{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/issues/7/labels/", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		label := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/test-user/test-repo/issues/7/labels/")
+		if label != "triage" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Label does not exist"}`)
+			return
+		}
+		removed = append(removed, label)
+		fmt.Fprint(w, `[{"name": "bug"}]`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	err = p.AddLabelsToIssue(githubUserName, githubRepoName, 7, []string{"bug", "triage"})
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"bug", "triage"}, added)
+
+	err = p.RemoveLabelFromIssue(githubUserName, githubRepoName, 7, "triage")
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"triage"}, removed)
+
+	// a label which isn't on the issue is already removed
+	err = p.RemoveLabelFromIssue(githubUserName, githubRepoName, 7, "wontfix")
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"triage"}, removed)
+}
+
+func (suite *GitHubProviderSuite) TestGetFileLastCommit() {
+	commit, err := suite.provider.GetFileLastCommit(githubUserName, githubRepoName, "README.md", githubDefaultRef)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(commit)
+	suite.Require().Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e", commit.SHA)
+	suite.Require().Equal("Update the README", commit.Message)
+	suite.Require().Equal(githubUserName, commit.Author.Login)
+	suite.Require().Equal("test-user@example.com", commit.Author.Email)
+
+	_, err = suite.provider.GetFileLastCommit(githubUserName, "missing-repo", "README.md", githubDefaultRef)
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "404")
+}
+
+func (suite *GitHubProviderSuite) TestListWebHookDeliveries() {
+	deliveries, err := suite.provider.ListWebHookDeliveries(githubUserName, githubRepoName, 12345)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(deliveries, 2)
+	suite.Require().Equal(200, deliveries[0].StatusCode)
+	suite.Require().Equal("issues", deliveries[0].Event)
+	suite.Require().Equal(270*time.Millisecond, deliveries[0].Duration)
+	suite.Require().Equal(503, deliveries[1].StatusCode)
+	suite.Require().True(deliveries[1].Redelivery)
+	suite.Require().NotNil(deliveries[1].DeliveredAt)
+}
+
+func (suite *GitHubProviderSuite) TestRedeliverWebHook() {
+	err := suite.provider.RedeliverWebHook(githubUserName, githubRepoName, 12345, 123456789)
+
+	suite.Require().Nil(err)
+}
+
+func (suite *GitHubProviderSuite) TestPingWebHook() {
+	err := suite.provider.PingWebHook(githubUserName, githubRepoName, 12345)
+
+	suite.Require().Nil(err)
+}
+
+func (suite *GitHubProviderSuite) TestListTeams() {
+	teams, err := suite.provider.ListTeams(githubUpstreamOrg)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(teams, 2)
+	suite.Require().Equal("Justice League", teams[0].Name)
+	suite.Require().Equal("justice-league", teams[0].Slug)
+	suite.Require().Equal("admin", teams[0].Permission)
+	suite.Require().Equal("reviewers", teams[1].Slug)
+}
+
+func (suite *GitHubProviderSuite) TestSetIssuesEnabled() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	body := map[string]interface{}{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPatch, r.Method)
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"name": "test-repo", "has_issues": false}`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	err = p.SetIssuesEnabled(githubUserName, githubRepoName, false)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(false, body["has_issues"])
+}
+
+func (suite *GitHubProviderSuite) TestGetMergeConfig() {
+	config, err := suite.provider.GetMergeConfig(githubUserName, githubRepoName)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(config)
+	suite.Require().True(config.AllowMergeCommit)
+	suite.Require().True(config.AllowSquash)
+	suite.Require().False(config.AllowRebase)
+	suite.Require().True(config.Allows(git.MergeMethodSquash))
+}
+
+func (suite *GitHubProviderSuite) TestSetMergeConfig() {
+	err := suite.provider.SetMergeConfig(githubUserName, githubRepoName, &git.MergeConfig{AllowSquash: true})
+	suite.Require().Nil(err)
+
+	err = suite.provider.SetMergeConfig(githubUserName, githubRepoName, &git.MergeConfig{})
+	suite.Require().NotNil(err)
+}
+
+func (suite *GitHubProviderSuite) TestListRepositoriesForCurrentUser() {
+	repos, err := suite.provider.ListRepositoriesForCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Len(repos, 1)
+	suite.Require().Equal(githubRepoName, repos[0].Name)
+	suite.Require().Equal("https://github.com/test-user/test-repo.git", repos[0].CloneURL)
+	suite.Require().Equal(108, repos[0].Size)
+	suite.Require().Equal(80, repos[0].Stars)
+	suite.Require().Equal([]string{"git", "go"}, repos[0].Topics)
+}
+
+func (suite *GitHubProviderSuite) TestGetRepository() {
+	repo, err := suite.provider.GetRepository(githubUserName, githubRepoName)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(githubRepoName, repo.Name)
+	suite.Require().Equal(108, repo.Size)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *repo.PushedAt)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 14, 43, 0, time.UTC), *repo.UpdatedAt)
+	suite.Require().Equal("master", repo.DefaultBranch)
+	suite.Require().Equal(80, repo.Stars)
+	suite.Require().Equal([]string{"git", "go"}, repo.Topics)
+}
+
+func (suite *GitHubProviderSuite) TestGetMissingRepository() {
+	_, err := suite.provider.GetRepository(githubUserName, "missing-repo")
+
+	suite.Require().NotNil(err)
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitHubProviderSuite) TestGetContent() {
+	file, err := suite.provider.GetContent(githubUserName, githubRepoName, "CODEOWNERS", "master")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("CODEOWNERS", file.Path)
+	suite.Require().Equal("base64", file.Encoding)
+	suite.Require().Contains(file.Content, "/docs/  @upstream-org/docs")
+
+	_, err = suite.provider.GetContent(githubUserName, githubRepoName, "missing.txt", "master")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitHubProviderSuite) TestGetCodeOwners() {
+	// .github/CODEOWNERS is missing, so the file at the root is used
+	rules, err := git.GetCodeOwners(suite.provider, githubUserName, githubRepoName, "master")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(rules, 2)
+	suite.Require().Equal([]string{"@upstream-org/docs"}, git.FindCodeOwners(rules, "docs/README.md"))
+	suite.Require().Equal([]string{"@test-user"}, git.FindCodeOwners(rules, "main.go"))
+}
+
+func (suite *GitHubProviderSuite) TestListPullRequests() {
+	repo := &git.Repository{Name: githubRepoName}
+	prs, err := suite.provider.ListPullRequests(githubUserName, repo, git.PullRequestStateOpen)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 2)
+	suite.Require().Equal(3, *prs[0].Number)
+	suite.Require().Equal("https://github.com/test-user/test-repo/pull/3", prs[0].URL)
+	suite.Require().Equal("promote-1.0.2", *prs[0].HeadRef)
+	suite.Require().Equal("master", *prs[0].BaseRef)
+	suite.Require().Equal(githubUserName, prs[0].Author.Login)
+	suite.Require().True(prs[1].HasLabel("promotion"))
+}
+
+func (suite *GitHubProviderSuite) TestListPullRequestsFollowsPages() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"number": 2}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/repos/test-user/test-repo/pulls?page=2>; rel="next"`, server.URL))
+		fmt.Fprint(w, `[{"number": 1}]`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	prs, err := p.ListPullRequests(githubUserName, &git.Repository{Name: githubRepoName}, git.PullRequestStateOpen)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 2)
+	suite.Require().Equal(1, *prs[0].Number)
+	suite.Require().Equal(2, *prs[1].Number)
+}
+
+func (suite *GitHubProviderSuite) TestUpdatePullRequestStatusReadsLabels() {
+	number := 4
+	pr := &git.PullRequest{
+		Owner:  githubUserName,
+		Repo:   githubRepoName,
+		Number: &number,
+	}
+
+	err := suite.provider.UpdatePullRequestStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(pr.Labels, 2)
+	suite.Require().True(pr.HasLabel("do-not-merge"))
+	suite.Require().False(pr.HasLabel("approved"))
+}
+
+func (suite *GitHubProviderSuite) TestGetPullRequestStats() {
+	number := 4
+	pr := &git.PullRequest{
+		Owner:  githubUserName,
+		Repo:   githubRepoName,
+		Number: &number,
+	}
+
+	stats, err := suite.provider.GetPullRequestStats(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.DiffStat{Additions: 12, Deletions: 3, ChangedFiles: 2}, stats)
+}
+
+func (suite *GitHubProviderSuite) TestClosePullRequest() {
+	number := 3
+	pr := &git.PullRequest{
+		Owner:  githubUserName,
+		Repo:   githubRepoName,
+		Number: &number,
+	}
+	err := suite.provider.ClosePullRequest(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(git.PullRequestStateClosed, *pr.State)
+	suite.Require().NotNil(pr.ClosedAt)
+}
+
+func (suite *GitHubProviderSuite) TestListPullRequestActivity() {
+	number := 3
+	pr := &git.PullRequest{
+		Owner:  githubUserName,
+		Repo:   githubRepoName,
+		Number: &number,
+	}
+	events, err := suite.provider.ListPullRequestActivity(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(events, 2)
+	suite.Require().Equal("6430295168", events[0].ID)
+	suite.Require().Equal("labeled", events[0].Action)
+	suite.Require().Equal(githubUserName, events[0].Actor.Login)
+	suite.Require().Equal("merged", events[1].Action)
+	suite.Require().Equal("reviewer", events[1].Actor.Login)
+	suite.Require().Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e", events[1].CommitSHA)
+}
+
+func (suite *GitHubProviderSuite) TestResolveRef() {
+	sha, err := suite.provider.ResolveRef(githubUserName, githubRepoName, githubDefaultRef)
+	suite.Require().Nil(err)
+	suite.Require().Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e", sha)
+
+	// an annotated tag resolves to the commit it tags
+	sha, err = suite.provider.ResolveRef(githubUserName, githubRepoName, "v1.0.0")
+	suite.Require().Nil(err)
+	suite.Require().Equal("c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c", sha)
+
+	_, err = suite.provider.ResolveRef(githubUserName, githubRepoName, "missing")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitHubProviderSuite) TestDeleteBranch() {
+	err := suite.provider.DeleteBranch(githubUserName, githubRepoName, "feature")
+	suite.Require().Nil(err)
+
+	// a branch which is already gone is fine
+	err = suite.provider.DeleteBranch(githubUserName, githubRepoName, "deleted")
+	suite.Require().Nil(err)
+
+	err = suite.provider.DeleteBranch(githubUserName, githubRepoName, "master")
+	suite.Require().True(git.IsDefaultBranch(err))
+}
+
+func (suite *GitHubProviderSuite) TestCreateBranch() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/git/refs/heads/master", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ref": "refs/heads/master", "object": {"type": "commit", "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}}`)
+	})
+	var created map[string]interface{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		body := map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		if created != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Reference already exists"}`)
+			return
+		}
+		created = body
+		fmt.Fprint(w, `{"ref": "refs/heads/feature", "object": {"type": "commit", "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}}`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	err = p.CreateBranch(githubUserName, githubRepoName, "feature", "master")
+	suite.Require().Nil(err)
+	suite.Require().Equal("refs/heads/feature", created["ref"])
+	suite.Require().Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e", created["sha"])
+
+	err = p.CreateBranch(githubUserName, githubRepoName, "feature", "master")
+	suite.Require().True(git.IsAlreadyExists(err))
+}
+
+func (suite *GitHubProviderSuite) TestSetRepositoryTopics() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	var body map[string]interface{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/topics", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPut, r.Method)
+		body = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"names": ["git", "go"]}`)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/missing-repo/topics", http.NotFound)
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	err = p.SetRepositoryTopics(githubUserName, githubRepoName, []string{"git", "go"})
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"names": []interface{}{"git", "go"}}, body)
+
+	err = p.SetRepositoryTopics(githubUserName, githubRepoName, nil)
+	suite.Require().Nil(err)
+	suite.Require().Equal(map[string]interface{}{"names": []interface{}{}}, body)
+
+	err = p.SetRepositoryTopics(githubUserName, "missing-repo", []string{"go"})
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitHubProviderSuite) TestCreateTag() {
+	err := suite.provider.CreateTag(githubUserName, githubRepoName, "v1.0.0", "c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c", "Release 1.0.0")
+	suite.Require().Nil(err)
+}
+
+func (suite *GitHubProviderSuite) TestGetTag() {
+	tag, err := suite.provider.GetTag(githubUserName, githubRepoName, "v1.0.0")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(tag)
+	suite.Require().Equal("v1.0.0", tag.Name)
+	suite.Require().Equal("c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c", tag.SHA)
+	suite.Require().Equal("Release 1.0.0", tag.Message)
+
+	tag, err = suite.provider.GetTag(githubUserName, githubRepoName, "missing")
+	suite.Require().Nil(err)
+	suite.Require().Nil(tag)
+}
+
+func (suite *GitHubProviderSuite) TestListRepositoryTags() {
+	tags, err := suite.provider.ListRepositoryTags(githubUserName, githubRepoName)
+	suite.Require().Nil(err)
+	suite.Require().Len(tags, 2)
+	suite.Require().Equal("v1.0.0", tags[0].Name)
+	suite.Require().Equal("c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c", tags[0].SHA)
+	suite.Require().Nil(tags[0].Date)
+	suite.Require().Equal("v0.9.0", tags[1].Name)
+	suite.Require().Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e", tags[1].SHA)
+}
+
+func (suite *GitHubProviderSuite) TestGetCommitStatus() {
+	sha := "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	status, err := suite.provider.GetCommitStatus(githubUserName, githubRepoName, sha, "lint")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("3", status.ID)
+	suite.Require().Equal("success", status.State)
+	suite.Require().Equal("https://ci.example.com/1002", status.TargetURL)
+
+	status, err = suite.provider.GetCommitStatus(githubUserName, githubRepoName, sha, "missing")
+	suite.Require().Nil(err)
+	suite.Require().Nil(status)
+
+	_, err = suite.provider.GetCommitStatus(githubUserName, githubRepoName, "0000000", "lint")
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "404")
+}
+
+func (suite *GitHubProviderSuite) TestListMilestones() {
+	milestones, err := suite.provider.ListMilestones(githubUserName, githubRepoName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(milestones, 2)
+	suite.Require().Equal(int64(1), milestones[0].ID)
+	suite.Require().Equal("v1.0", milestones[0].Title)
+	suite.Require().Equal(git.MilestoneStateClosed, milestones[0].State)
+	suite.Require().Equal(time.Date(2018, 10, 9, 7, 0, 0, 0, time.UTC), *milestones[0].DueOn)
+	suite.Require().Equal(8, milestones[0].ClosedIssues)
+	suite.Require().Equal(git.MilestoneStateOpen, milestones[1].State)
+	suite.Require().Nil(milestones[1].DueOn)
+	suite.Require().Equal(4, milestones[1].OpenIssues)
+}
+
+func (suite *GitHubProviderSuite) TestCreateAndCloseMilestone() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	bodies := []map[string]interface{}{}
+	record := func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+	}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		record(w, r)
+		fmt.Fprint(w, `{"id": 1002606, "number": 3, "state": "open", "title": "v1.2", "due_on": "2018-12-01T00:00:00Z"}`)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/milestones/3", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPatch, r.Method)
+		record(w, r)
+		fmt.Fprint(w, `{"id": 1002606, "number": 3, "state": "closed", "title": "v1.2"}`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	due := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+	milestone, err := p.CreateMilestone(githubUserName, githubRepoName, "v1.2", &due)
+	suite.Require().Nil(err)
+	suite.Require().Equal(int64(3), milestone.ID)
+	suite.Require().Equal(git.MilestoneStateOpen, milestone.State)
+
+	err = p.CloseMilestone(githubUserName, githubRepoName, milestone.ID)
+	suite.Require().Nil(err)
+
+	suite.Require().Len(bodies, 2)
+	suite.Require().Equal("v1.2", bodies[0]["title"])
+	suite.Require().Equal("2018-12-01T00:00:00Z", bodies[0]["due_on"])
+	suite.Require().Equal(map[string]interface{}{"state": "closed"}, bodies[1])
+}
+
+func (suite *GitHubProviderSuite) TestForkRepositoryGivesUp() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// the fork is scheduled but never appears
+	mux.HandleFunc("/api/v3/repos/upstream-org/test-repo/forks", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo", http.NotFound)
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+	p.(*GitHubProvider).SetForkPollConfig(git.ForkPollConfig{
+		Interval: time.Millisecond,
+		Timeout:  10 * time.Millisecond,
+	})
+
+	_, err = p.ForkRepository(githubUpstreamOrg, githubRepoName, "")
+
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "Gave up waiting for Repository test-user/test-repo to appear: timed out after 10ms")
+}
+
+func (suite *GitHubProviderSuite) TestForkRepositoryStopsWaitingWhenCancelled() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// the fork is scheduled but never appears
+	mux.HandleFunc("/api/v3/repos/upstream-org/test-repo/forks", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo", http.NotFound)
+	ctx, cancel := context.WithCancel(context.Background())
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI(), git.WithContext(ctx))
+	suite.Require().Nil(err)
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = p.ForkRepository(githubUpstreamOrg, githubRepoName, "")
+
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "context canceled")
+	suite.Require().True(time.Since(start) < time.Second)
+}
+
+func (suite *GitHubProviderSuite) TestGetUsers() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	var lock sync.Mutex
+	lookups := map[string]int{}
+	mux.HandleFunc("/api/v3/users/", func(w http.ResponseWriter, r *http.Request) {
+		login := strings.TrimPrefix(r.URL.Path, "/api/v3/users/")
+		lock.Lock()
+		lookups[login]++
+		lock.Unlock()
+		switch login {
+		case "missing":
+			http.NotFound(w, r)
+			return
+		case "forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"login": "%s", "email": "%s@example.com"}`, login, login)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	users, err := p.GetUsers([]string{"alice", "bob", "missing"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 2)
+	suite.Require().Equal("alice@example.com", users["alice"].Email)
+	suite.Require().Equal("bob@example.com", users["bob"].Email)
+
+	users, err = p.GetUsers([]string{"alice", "bob"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 2)
+	suite.Require().Equal(map[string]int{"alice": 1, "bob": 1, "missing": 1}, lookups)
+
+	users, err = p.GetUsers([]string{"alice", "forbidden"})
+
+	suite.Require().NotNil(err)
+	suite.Require().Contains(err.Error(), "get user forbidden")
+	suite.Require().Nil(users)
+}
+
+func (suite *GitHubProviderSuite) TestRetriesTransientErrors() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	requests := 0
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"login": "%s"}`, githubUserName)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI(),
+		git.WithRetryConfig(git.RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Second}))
+	suite.Require().Nil(err)
+
+	user, err := p.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(githubUserName, user.Login)
+	suite.Require().Equal(3, requests)
+}
+
+func (suite *GitHubProviderSuite) TestListAndAcceptInvitations() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/user/repository_invitations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 7, "permissions": "write", "html_url": "https://github.com/test-org/test-repo/invitations",
+			"created_at": "2018-11-20T10:12:45Z", "inviter": {"login": "owner"},
+			"repository": {"name": "test-repo", "owner": {"login": "test-org"}}}]`)
+	})
+	accepted := ""
+	mux.HandleFunc("/api/v3/user/repository_invitations/7", func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Method
+		w.WriteHeader(http.StatusNoContent)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	invitations, err := p.ListInvitations()
+
+	suite.Require().Nil(err)
+	suite.Require().Len(invitations, 1)
+	suite.Require().Equal(int64(7), invitations[0].ID)
+	suite.Require().Equal("write", invitations[0].Permissions)
+	suite.Require().Equal("test-org", invitations[0].Repository.Organisation)
+	suite.Require().Equal("test-repo", invitations[0].Repository.Name)
+	suite.Require().Equal("owner", invitations[0].Inviter.Login)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *invitations[0].CreatedAt)
+
+	err = p.AcceptInvitation(invitations[0].ID)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(http.MethodPatch, accepted)
+}
+
+func (suite *GitHubProviderSuite) TestUpdateCommitStatusContexts() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// github keeps the latest status of each context
+	statuses := map[string]map[string]interface{}{}
+	sha := "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/statuses/"+sha, func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		body := map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		context, _ := body["context"].(string)
+		statuses[context] = body
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(body)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	_, err = p.UpdateCommitStatus(githubUserName, githubRepoName, sha, &git.RepoStatus{
+		Context: "ci/build", State: "success", Description: "Build passed",
+	})
+	suite.Require().Nil(err)
+	result, err := p.UpdateCommitStatus(githubUserName, githubRepoName, sha, &git.RepoStatus{
+		Context: " ci/lint ", State: "failure", Description: "Lint failed",
+	})
+	suite.Require().Nil(err)
+
+	suite.Require().Len(statuses, 2)
+	suite.Require().Equal("Build passed", statuses["ci/build"]["description"])
+	suite.Require().Equal("Lint failed", statuses["ci/lint"]["description"])
+	suite.Require().Equal("ci/lint", result.Context)
+
+	_, err = p.UpdateCommitStatus(githubUserName, githubRepoName, sha, &git.RepoStatus{State: "success"})
+	suite.Require().NotNil(err)
+	suite.Require().Len(statuses, 2)
+}
+
+func (suite *GitHubProviderSuite) TestCreateDraftPullRequest() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	created := map[string]interface{}{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 5, "html_url": "https://github.com/test-user/test-repo/pull/5", "draft": true}`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	pr, err := p.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: githubUserName, Name: githubRepoName},
+		Draft:      true,
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(5, *pr.Number)
+	suite.Require().Equal("https://github.com/test-user/test-repo/pull/5", pr.URL)
+	suite.Require().Equal(true, created["draft"])
+	suite.Require().Equal("Promote to version 1.0.4", created["title"])
+	suite.Require().Equal("promote-1.0.4", created["head"])
+}
+
+func (suite *GitHubProviderSuite) TestCreatePullRequestAssignsUsers() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 5, "html_url": "https://github.com/test-user/test-repo/pull/5"}`)
+	})
+	assignees := map[string]interface{}{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/issues/5/assignees", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&assignees))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 5}`)
+	})
+	reviewers := map[string]interface{}{}
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls/5/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&reviewers))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 5}`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	pr, err := p.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: githubUserName, Name: githubRepoName},
+		Assignees:  []string{"some", "few"},
+		Reviewers:  []string{"most"},
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(5, *pr.Number)
+	suite.Require().Equal([]interface{}{"some", "few"}, assignees["assignees"])
+	suite.Require().Equal([]interface{}{"most"}, reviewers["reviewers"])
+}
+
+func (suite *GitHubProviderSuite) TestCreatePullRequestFailingToAssignUsers() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 5, "html_url": "https://github.com/test-user/test-repo/pull/5"}`)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/issues/5/assignees", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls/5/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+	args := &git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: githubUserName, Name: githubRepoName},
+		Reviewers:  []string{"most"},
+	}
+
+	// failing to request reviews only warns
+	pr, err := p.CreatePullRequest(args)
+	suite.Require().Nil(err)
+	suite.Require().Equal(5, *pr.Number)
+
+	// failing to assign the pull request still returns it
+	args.Assignees = []string{"some"}
+	pr, err = p.CreatePullRequest(args)
+	suite.Require().NotNil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(5, *pr.Number)
+}
+
+func (suite *GitHubProviderSuite) TestPullRequestLastCommitStatusWithoutStatuses() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/commits/abc123/statuses", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	state, err := p.PullRequestLastCommitStatus(&git.PullRequest{Owner: githubUserName, Repo: githubRepoName, LastCommitSha: "abc123"})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("pending", state)
+}
+
+func (suite *GitHubProviderSuite) TestGetPullRequestByBranch() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal("open", r.URL.Query().Get("state"))
+		if r.URL.Query().Get("head") != "test-user:promote-1.0.3" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 3, "state": "open", "html_url": "https://github.com/test-user/test-repo/pull/3",
+			"head": {"ref": "promote-1.0.3"}, "base": {"ref": "master"}}]`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+	repo := &git.Repository{Name: githubRepoName}
+
+	pr, err := p.GetPullRequestByBranch(githubUserName, repo, "promote-1.0.3")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(3, *pr.Number)
+	suite.Require().Equal("promote-1.0.3", *pr.HeadRef)
+
+	pr, err = p.GetPullRequestByBranch(githubUserName, repo, "promote-1.0.4")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(pr)
+}
+
+func (suite *GitHubProviderSuite) TestListContributors() {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v3/repos/test-user/test-repo/contributors", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"login": "most", "contributions": 12}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/repos/test-user/test-repo/contributors?page=2>; rel="next"`, server.URL))
+		fmt.Fprint(w, `[{"login": "some", "contributions": 5, "html_url": "https://github.com/some"}, {"login": "few", "contributions": 1}]`)
+	})
+	p, err := NewProvider(githubUserName, server.URL, "test", githubProviderName, git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	contributors, err := p.ListContributors(githubUserName, githubRepoName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(contributors, 3)
+	suite.Require().Equal("most", contributors[0].User.Login)
+	suite.Require().Equal(12, contributors[0].Contributions)
+	suite.Require().Equal("some", contributors[1].User.Login)
+	suite.Require().Equal("https://github.com/some", contributors[1].User.URL)
+	suite.Require().Equal("few", contributors[2].User.Login)
+}
+
+func (suite *GitHubProviderSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(githubUserName, user.Login)
+	suite.Require().Equal("Test User", user.Name)
+	suite.Require().Equal("test-user@example.com", user.Email)
+	suite.Require().Equal("https://github.com/test-user", user.URL)
+}
+
+func (suite *GitHubProviderSuite) TestKind() {
+	suite.Require().Equal(git.KindGitHub, suite.provider.Kind())
+	suite.Require().Contains(git.KindGits, suite.provider.Kind())
+	suite.Require().True(suite.provider.IsGitHub())
+}
+
+func TestGitHubProviderSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestGitHubProviderSuite in short mode")
+	} else {
+		suite.Run(t, new(GitHubProviderSuite))
+	}
+}
@@ -1,9 +1,13 @@
 package gitlab
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -20,20 +24,37 @@ type GitlabProvider struct {
 	Client   *gitlab.Client
 	Context  context.Context
 
-	URL  string
-	Git  git.Gitter
-	Name string
+	URL     string
+	Git     git.Gitter
+	Name    string
+	Options git.ProviderOptions
+
+	token string
+	// users caches the users looked up by GetUsers
+	users *git.UserCache
+}
+
+func init() {
+	git.RegisterProviderFactory(git.KindGitlab, func(username, serverURL, token string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+		return NewProvider(username, serverURL, token, git.KindGitlab, gitter, options...)
+	})
 }
 
-func NewProvider(username, serverURL, token, providerName string, git git.Gitter) (git.Provider, error) {
-	u := serverURL
-	c := gitlab.NewClient(nil, username)
+func NewProvider(username, serverURL, token, providerName string, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	// servers hosted under a subpath have their API under that path too
+	u := git.NormalizeServerURL(serverURL)
+	c := gitlab.NewClient(git.NewProviderOptions(options...).NewHTTPClient(), token)
 	if !IsGitLabServerURL(u) {
 		if err := c.SetBaseURL(u); err != nil {
 			return nil, err
 		}
 	}
-	return WithGitlabClient(serverURL, username, c, git)
+	provider, err := WithGitlabClient(serverURL, username, c, gitter, options...)
+	if err != nil {
+		return nil, err
+	}
+	provider.(*GitlabProvider).token = token
+	return provider, nil
 }
 
 func IsGitLabServerURL(u string) bool {
@@ -42,61 +63,188 @@ func IsGitLabServerURL(u string) bool {
 }
 
 // Used by unit tests to inject a mocked client
-func WithGitlabClient(serverURL, username string, client *gitlab.Client, git git.Gitter) (git.Provider, error) {
+func WithGitlabClient(serverURL, username string, client *gitlab.Client, gitter git.Gitter, options ...git.ProviderOption) (git.Provider, error) {
+	providerOptions := git.NewProviderOptions(options...)
 	provider := &GitlabProvider{
 		Username: username,
 		Client:   client,
-		Git:      git,
+		Context:  providerOptions.RequestContext(),
+		Git:      gitter,
 		URL:      serverURL,
+		Options:  providerOptions,
+		users:    &git.UserCache{},
 	}
 	return provider, nil
 }
 
 func (g *GitlabProvider) ListRepositories(org string) ([]*git.Repository, error) {
-	result, _, err := getRepositories(g.Client, g.Username, org)
+	return git.ListAllRepositories(git.ListOptions{}, func(options git.ListOptions) (*git.RepositoryPage, error) {
+		return g.ListRepositoriesPaged(org, options)
+	})
+}
+
+// ListRepositoriesPaged lists a page of the projects of a group, or of the user if there is no such group. The
+// cursor of a page is its number
+func (g *GitlabProvider) ListRepositoriesPaged(org string, options git.ListOptions) (*git.RepositoryPage, error) {
+	listOptions := gitlab.ListOptions{
+		Page:    options.Page,
+		PerPage: options.PerPage,
+	}
+	if options.Cursor != "" {
+		page, err := strconv.Atoi(options.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %s: %s", options.Cursor, err)
+		}
+		listOptions.Page = page
+	}
+	result, response, err := getRepositories(g.Client, g.Username, org, listOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	var repos []*git.Repository
+	page := &git.RepositoryPage{
+		Repositories: []*git.Repository{},
+	}
 	for _, p := range result {
-		repos = append(repos, fromGitlabProject(p))
+		page.Repositories = append(page.Repositories, fromGitlabProject(p))
+	}
+	if response.NextPage != 0 {
+		page.HasMore = true
+		page.NextCursor = strconv.Itoa(response.NextPage)
+	}
+	return page, nil
+}
+
+func (g *GitlabProvider) ListRepositoriesForCurrentUser() ([]*git.Repository, error) {
+	repos := []*git.Repository{}
+	options := &gitlab.ListProjectsOptions{Owned: gitlab.Bool(true), Statistics: gitlab.Bool(true)}
+	for {
+		result, response, err := g.Client.Projects.ListProjects(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the projects of the current user: %s", err)
+		}
+		for _, p := range result {
+			repos = append(repos, fromGitlabProject(p))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
 	}
 	return repos, nil
 }
 
+// gitlabRelease is a release of the releases API, which the client can't list yet
+type gitlabRelease struct {
+	Name        string `json:"name"`
+	TagName     string `json:"tag_name"`
+	Description string `json:"description"`
+	Assets      struct {
+		Links []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"links"`
+	} `json:"assets"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
 func (g *GitlabProvider) ListReleases(org string, name string) ([]*git.Release, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	options := &gitlab.ListOptions{}
+
 	answer := []*git.Release{}
-	// TODO
+	for {
+		req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/releases", pid), options, nil)
+		if err != nil {
+			return nil, err
+		}
+		releases := []*gitlabRelease{}
+		response, err := g.Client.Do(req, &releases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the releases of %s/%s: %s", org, name, err)
+		}
+		for _, release := range releases {
+			answer = append(answer, g.fromGitlabRelease(org, name, release))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
 	return answer, nil
 }
 
-func getRepositories(g *gitlab.Client, username string, org string) ([]*gitlab.Project, *gitlab.Response, error) {
+func (g *GitlabProvider) fromGitlabRelease(org string, name string, release *gitlabRelease) *git.Release {
+	assets := []git.ReleaseAsset{}
+	for _, link := range release.Assets.Links {
+		assets = append(assets, git.ReleaseAsset{
+			Name:               link.Name,
+			BrowserDownloadURL: link.URL,
+		})
+	}
+	// servers before 12.0 don't link to the release, so link to its tag
+	u := release.Links.Self
+	if u == "" {
+		u = util.UrlJoin(g.ServerURL(), org, name, "-/tags", release.TagName)
+	}
+	return &git.Release{
+		Name:    release.Name,
+		TagName: release.TagName,
+		Body:    release.Description,
+		URL:     u,
+		HTMLURL: u,
+		Assets:  &assets,
+	}
+}
+
+func getRepositories(g *gitlab.Client, username string, org string, listOptions gitlab.ListOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	userOptions := &gitlab.ListProjectsOptions{
+		ListOptions: listOptions,
+		Owned:       gitlab.Bool(true),
+		Statistics:  gitlab.Bool(true),
+	}
 	if org != "" {
-		projects, resp, err := g.Groups.ListGroupProjects(org, nil)
+		projects, resp, err := g.Groups.ListGroupProjects(org, &gitlab.ListGroupProjectsOptions{ListOptions: listOptions})
 		if err != nil {
-			return g.Projects.ListUserProjects(org, &gitlab.ListProjectsOptions{Owned: gitlab.Bool(true)})
+			return g.Projects.ListUserProjects(org, userOptions)
 		}
 		return projects, resp, err
 
 	}
-	return g.Projects.ListUserProjects(username, &gitlab.ListProjectsOptions{Owned: gitlab.Bool(true)})
+	return g.Projects.ListUserProjects(username, userOptions)
 }
 
+// fromGitlabProject converts a project. Its size is only known if the project was fetched with its statistics,
+// and as gitlab doesn't record pushes on projects its last activity is taken as when it was updated
 func fromGitlabProject(p *gitlab.Project) *git.Repository {
-	return &git.Repository{
-		Name:     p.Name,
-		HTMLURL:  p.WebURL,
-		SSHURL:   p.SSHURLToRepo,
-		CloneURL: p.HTTPURLToRepo,
-		Fork:     p.ForkedFromProject != nil,
+	repo := &git.Repository{
+		Name:             p.Name,
+		HTMLURL:          p.WebURL,
+		SSHURL:           p.SSHURLToRepo,
+		CloneURL:         p.HTTPURLToRepo,
+		Fork:             p.ForkedFromProject != nil,
+		HasIssuesEnabled: p.IssuesEnabled,
+		UpdatedAt:        p.LastActivityAt,
+		DefaultBranch:    p.DefaultBranch,
+	}
+	if p.Statistics != nil {
+		repo.Size = int(p.Statistics.RepositorySize / 1024)
 	}
+	return repo
 }
 
 func (g *GitlabProvider) CreateRepository(org string, name string, private bool) (*git.Repository, error) {
 	visibility := gitlab.PublicVisibility
-	if private {
+	switch g.Options.Visibility(private) {
+	case git.RepoVisibilityPrivate:
 		visibility = gitlab.PrivateVisibility
+	case git.RepoVisibilityInternal:
+		visibility = gitlab.InternalVisibility
 	}
 
 	p := &gitlab.CreateProjectOptions{
@@ -123,13 +271,26 @@ func (g *GitlabProvider) GetRepository(org, name string) (*git.Repository, error
 	if err != nil {
 		return nil, err
 	}
-	project, response, err := g.Client.Projects.GetProject(pid)
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s", pid), &getProjectOptions{Statistics: true}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("request: %s failed due to: %s", response.Request.URL, err)
+		return nil, err
+	}
+	project := &gitlab.Project{}
+	response, err := g.Client.Do(req, project)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("repository %s/%s: %w", org, name, git.ErrNotFound)
+		}
+		return nil, fmt.Errorf("request: %s failed due to: %s", req.URL, err)
 	}
 	return fromGitlabProject(project), nil
 }
 
+// getProjectOptions are the options of getting a project with its statistics, which the client can't get yet
+type getProjectOptions struct {
+	Statistics bool `url:"statistics"`
+}
+
 func (g *GitlabProvider) ListOrganisations() ([]git.Organisation, error) {
 	groups, _, err := g.Client.Groups.ListGroups(nil)
 	if err != nil {
@@ -143,8 +304,47 @@ func (g *GitlabProvider) ListOrganisations() ([]git.Organisation, error) {
 	return organizations, nil
 }
 
+// ListTeams returns the subgroups of the group as its teams
+func (g *GitlabProvider) ListTeams(org string) ([]*git.Team, error) {
+	answer := []*git.Team{}
+	options := &gitlab.ListSubgroupsOptions{}
+	for {
+		groups, response, err := g.Client.Groups.ListSubgroups(org, options)
+		if err != nil {
+			return answer, fmt.Errorf("failed to list the subgroups of group %s: %s", org, err)
+		}
+
+		for _, group := range groups {
+			answer = append(answer, &git.Team{
+				Name: group.Name,
+				Slug: group.Path,
+			})
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return answer, nil
+}
+
+// projectIDPrefix prefixes the numeric id of a project passed as the name of a repository, such as pid:123,
+// which finds the project wherever it has moved to
+const projectIDPrefix = "pid:"
+
+// projectId returns the numeric id of a project, given either as pid:<id> or by its name. A project which
+// isn't listed by the group or user any more may have been moved, so it is then looked up by its old path,
+// which gitlab redirects to the new one, and failing that searched for by name among the projects of the user
 func (g *GitlabProvider) projectId(org, username, name string) (string, error) {
-	repos, _, err := getRepositories(g.Client, username, org)
+	if strings.HasPrefix(name, projectIDPrefix) {
+		pid := strings.TrimPrefix(name, projectIDPrefix)
+		if _, err := strconv.Atoi(pid); err != nil {
+			return "", fmt.Errorf("invalid project id %s", name)
+		}
+		return pid, nil
+	}
+
+	repos, _, err := getRepositories(g.Client, username, org, gitlab.ListOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -154,7 +354,53 @@ func (g *GitlabProvider) projectId(org, username, name string) (string, error) {
 			return strconv.Itoa(repo.ID), nil
 		}
 	}
-	return "", fmt.Errorf("no repository found with name %s", name)
+
+	pid, err := g.movedProjectId(org, username, name)
+	if err != nil {
+		return "", err
+	}
+	if pid == "" {
+		return "", fmt.Errorf("no repository found with name %s: %w", name, git.ErrNotFound)
+	}
+	return pid, nil
+}
+
+// movedProjectId returns the id of a project which has moved from org/name, or blank if it can't be found
+func (g *GitlabProvider) movedProjectId(org, username, name string) (string, error) {
+	owner := org
+	if owner == "" {
+		owner = username
+	}
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s", url.PathEscape(owner+"/"+name)), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	project := &gitlab.Project{}
+	response, err := g.Client.Do(req, project)
+	if err == nil {
+		return strconv.Itoa(project.ID), nil
+	}
+	if response == nil || response.StatusCode != http.StatusNotFound {
+		return "", fmt.Errorf("failed to get project %s/%s: %s", owner, name, err)
+	}
+
+	projects, _, err := g.Client.Projects.ListProjects(&gitlab.ListProjectsOptions{
+		Search:     gitlab.String(name),
+		Membership: gitlab.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search for project %s: %s", name, err)
+	}
+	pid := ""
+	for _, project := range projects {
+		if project.Path == name {
+			if pid != "" {
+				return "", fmt.Errorf("more than one project named %s was found after %s/%s moved", name, owner, name)
+			}
+			pid = strconv.Itoa(project.ID)
+		}
+	}
+	return pid, nil
 }
 
 func (g *GitlabProvider) DeleteRepository(org, name string) error {
@@ -207,6 +453,95 @@ func (g *GitlabProvider) ValidateRepositoryName(org, name string) error {
 	return nil
 }
 
+// GetMergeConfig returns how merge requests of the project are merged. Gitlab lets any merge request be squashed
+// and either merges with a merge commit or only fast forwards, which is reported as a rebase
+func (g *GitlabProvider) GetMergeConfig(org string, name string) (*git.MergeConfig, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	project, _, err := g.Client.Projects.GetProject(pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project %s/%s: %s", org, name, err)
+	}
+
+	if project.MergeMethod == gitlab.FastForwardMerge {
+		return &git.MergeConfig{
+			AllowSquash:   true,
+			AllowRebase:   true,
+			DefaultMethod: git.MergeMethodRebase,
+		}, nil
+	}
+	return &git.MergeConfig{
+		AllowMergeCommit: true,
+		AllowSquash:      true,
+		DefaultMethod:    git.MergeMethodMerge,
+	}, nil
+}
+
+// SetMergeConfig sets the merge method of the project. Merge commits and fast forward merges can't both be allowed
+// so the default method picks between them
+func (g *GitlabProvider) SetMergeConfig(org string, name string, config *git.MergeConfig) error {
+	var mergeMethod gitlab.MergeMethodValue
+	switch {
+	case config.AllowMergeCommit && config.AllowRebase:
+		mergeMethod = gitlab.NoFastForwardMerge
+		if config.DefaultMethod == git.MergeMethodRebase {
+			mergeMethod = gitlab.FastForwardMerge
+		}
+	case config.AllowMergeCommit:
+		mergeMethod = gitlab.NoFastForwardMerge
+	case config.AllowRebase:
+		mergeMethod = gitlab.FastForwardMerge
+	default:
+		return fmt.Errorf("gitlab needs merge commits or rebases allowed on project %s/%s", org, name)
+	}
+
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	options := &gitlab.EditProjectOptions{
+		MergeMethod: &mergeMethod,
+	}
+
+	_, _, err = g.Client.Projects.EditProject(pid, options)
+	if err != nil {
+		return fmt.Errorf("failed to edit project %s/%s: %s", org, name, err)
+	}
+	return nil
+}
+
+// editProjectTopicsOptions sets the topics of a project, which the client doesn't support. GitLab 14 renamed
+// tag_list to topics so both are sent, and neither is omitted when empty so that the topics can be cleared
+type editProjectTopicsOptions struct {
+	TagList []string `json:"tag_list"`
+	Topics  []string `json:"topics"`
+}
+
+func (g *GitlabProvider) SetRepositoryTopics(org string, name string, topics []string) error {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	if topics == nil {
+		topics = []string{}
+	}
+	options := &editProjectTopicsOptions{
+		TagList: topics,
+		Topics:  topics,
+	}
+	req, err := g.Client.NewRequest("PUT", fmt.Sprintf("projects/%s", pid), options, nil)
+	if err != nil {
+		return err
+	}
+	_, err = g.Client.Do(req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set the topics of project %s/%s: %s", org, name, err)
+	}
+	return nil
+}
+
 func (g *GitlabProvider) CreatePullRequest(data *git.PullRequestArguments) (*git.PullRequest, error) {
 	owner := data.Repository.Organisation
 	repo := data.Repository.Name
@@ -214,6 +549,10 @@ func (g *GitlabProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 	body := data.Body
 	head := data.Head
 	base := data.Base
+	// merge requests are drafts while their title starts with Draft:
+	if data.Draft && !strings.HasPrefix(title, "Draft:") {
+		title = "Draft: " + title
+	}
 
 	o := &gitlab.CreateMergeRequestOptions{
 		Title:        &title,
@@ -230,8 +569,65 @@ func (g *GitlabProvider) CreatePullRequest(data *git.PullRequestArguments) (*git
 	if err != nil {
 		return nil, err
 	}
+	answer := fromMergeRequest(mr, owner, repo)
+	if len(data.Assignees) > 0 || len(data.Reviewers) > 0 {
+		err = g.assignMergeRequest(pid, mr.IID, data.Assignees, data.Reviewers)
+		if err != nil {
+			return answer, fmt.Errorf("failed to assign merge request %d of %s/%s: %s", mr.IID, owner, repo, err)
+		}
+	}
+	return answer, nil
+}
+
+// editMergeRequestUsersOptions are the users of a merge request, as the client can't set several assignees or any
+// reviewers
+type editMergeRequestUsersOptions struct {
+	AssigneeIDs []int `json:"assignee_ids,omitempty"`
+	ReviewerIDs []int `json:"reviewer_ids,omitempty"`
+}
+
+// assignMergeRequest assigns the merge request to the users and requests reviews of the reviewers. Servers older
+// than GitLab 13.7 ignore the reviewers, and reviewers who can't be found are skipped with a warning
+func (g *GitlabProvider) assignMergeRequest(pid string, iid int, assignees []string, reviewers []string) error {
+	opt := &editMergeRequestUsersOptions{}
+	var err error
+	opt.AssigneeIDs, err = g.userIDs(assignees)
+	if err != nil {
+		return err
+	}
+	opt.ReviewerIDs, err = g.userIDs(reviewers)
+	if err != nil {
+		log.Warnf("Not requesting reviews of merge request %d: %s\n", iid, err)
+		opt.ReviewerIDs = nil
+	}
+	if len(opt.AssigneeIDs) == 0 && len(opt.ReviewerIDs) == 0 {
+		return nil
+	}
+	req, err := g.Client.NewRequest("PUT", fmt.Sprintf("projects/%s/merge_requests/%d", pid, iid), opt, nil)
+	if err != nil {
+		return err
+	}
+	_, err = g.Client.Do(req, nil)
+	return err
+}
 
-	return fromMergeRequest(mr, owner, repo), nil
+// userIDs returns the ids of the users with the logins
+func (g *GitlabProvider) userIDs(logins []string) ([]int, error) {
+	ids := []int{}
+	for _, login := range logins {
+		if login == "" {
+			continue
+		}
+		users, _, err := g.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.String(login)})
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("could not find the user %s", login)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
 }
 
 func fromMergeRequest(mr *gitlab.MergeRequest, owner, repo string) *git.PullRequest {
@@ -255,6 +651,9 @@ func fromMergeRequest(mr *gitlab.MergeRequest, owner, repo string) *git.PullRequ
 		LastCommitSha:  mr.SHA,
 		MergedAt:       mr.MergedAt,
 		ClosedAt:       mr.ClosedAt,
+		HeadRef:        &mr.SourceBranch,
+		BaseRef:        &mr.TargetBranch,
+		Labels:         git.ToLabels(mr.Labels),
 	}
 }
 
@@ -315,6 +714,7 @@ func (p *GitlabProvider) GetPullRequestCommits(owner string, repository *git.Rep
 			Author: &git.User{
 				Email: commit.AuthorEmail,
 			},
+			CommittedAt: commit.CommittedDate,
 		}
 		answer = append(answer, summary)
 	}
@@ -322,123 +722,588 @@ func (p *GitlabProvider) GetPullRequestCommits(owner string, repository *git.Rep
 	return answer, nil
 }
 
-func (g *GitlabProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
-	owner := pr.Owner
-	repo := pr.Repo
+func (g *GitlabProvider) ListPullRequests(owner string, repository *git.Repository, state string) ([]*git.PullRequest, error) {
+	repo := repository.Name
+	pid, err := g.projectId(owner, g.Username, repo)
+	if err != nil {
+		return nil, err
+	}
 
-	ref := pr.LastCommitSha
-	if ref == "" {
-		return "", fmt.Errorf("missing String for LastCommitSha %#v", pr)
+	// gitlab calls open merge requests opened and has no state for both merged and closed ones
+	mrState := "all"
+	if state == git.PullRequestStateOpen {
+		mrState = "opened"
+	}
+	options := &gitlab.ListProjectMergeRequestsOptions{State: &mrState}
+
+	answer := []*git.PullRequest{}
+	for {
+		mrs, response, err := g.Client.MergeRequests.ListProjectMergeRequests(pid, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the merge requests of %s/%s: %s", owner, repo, err)
+		}
+		for _, mr := range mrs {
+			if state == git.PullRequestStateClosed && mr.State == "opened" {
+				continue
+			}
+			answer = append(answer, fromMergeRequest(mr, owner, repo))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
 	}
+	return answer, nil
+}
+
+// listMergeRequestsOptions are the options to list merge requests with the filters the client is missing
+type listMergeRequestsOptions struct {
+	gitlab.ListOptions
+	State          *string `url:"state,omitempty" json:"state,omitempty"`
+	AuthorUsername *string `url:"author_username,omitempty" json:"author_username,omitempty"`
+	SourceBranch   *string `url:"source_branch,omitempty" json:"source_branch,omitempty"`
+}
 
+func (g *GitlabProvider) ListPullRequestsByAuthor(owner string, repository *git.Repository, author string, state string) ([]*git.PullRequest, error) {
+	repo := repository.Name
 	pid, err := g.projectId(owner, g.Username, repo)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	c, _, err := g.Client.Commits.GetCommitStatuses(pid, ref, nil)
-	if err != nil {
-		return "", err
+
+	mrState := "all"
+	if state == git.PullRequestStateOpen {
+		mrState = "opened"
 	}
+	options := &listMergeRequestsOptions{State: &mrState, AuthorUsername: &author}
 
-	for _, result := range c {
-		if result.Status != "" {
-			return result.Status, nil
+	answer := []*git.PullRequest{}
+	for {
+		req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/merge_requests", pid), options, nil)
+		if err != nil {
+			return nil, err
+		}
+		mrs := []*gitlab.MergeRequest{}
+		response, err := g.Client.Do(req, &mrs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the merge requests of %s/%s by %s: %s", owner, repo, author, err)
+		}
+		for _, mr := range mrs {
+			if state == git.PullRequestStateClosed && mr.State == "opened" {
+				continue
+			}
+			answer = append(answer, fromMergeRequest(mr, owner, repo))
 		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = response.NextPage
 	}
-	return "", fmt.Errorf("could not find a status for repository %s with ref %s", pid, ref)
+	// servers older than the author_username filter ignore it
+	return git.FilterPullRequestsByAuthor(answer, author), nil
 }
 
-func (g *GitlabProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
-	pid, err := g.projectId(org, g.Username, repo)
+func (g *GitlabProvider) GetPullRequestByBranch(owner string, repository *git.Repository, branch string) (*git.PullRequest, error) {
+	repo := repository.Name
+	pid, err := g.projectId(owner, g.Username, repo)
 	if err != nil {
 		return nil, err
 	}
-	c, _, err := g.Client.Commits.GetCommitStatuses(pid, sha, nil)
+
+	options := &listMergeRequestsOptions{State: gitlab.String("opened"), SourceBranch: &branch}
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/merge_requests", pid), options, nil)
 	if err != nil {
 		return nil, err
 	}
-
-	var statuses []*git.RepoStatus
-
-	for _, result := range c {
-		statuses = append(statuses, fromCommitStatus(result))
+	mrs := []*gitlab.MergeRequest{}
+	_, err = g.Client.Do(req, &mrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the merge request from branch %s of %s/%s: %s", branch, owner, repo, err)
 	}
-
-	return statuses, nil
-}
-
-func (b *GitlabProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
-	return &git.RepoStatus{}, errors.New("TODO")
-}
-
-func fromCommitStatus(status *gitlab.CommitStatus) *git.RepoStatus {
-	return &git.RepoStatus{
-		ID:          string(status.ID),
-		URL:         status.TargetURL,
-		State:       status.Status,
-		Description: status.Description,
+	if len(mrs) == 0 {
+		return nil, nil
 	}
+	return fromMergeRequest(mrs[0], owner, repo), nil
 }
 
-func (g *GitlabProvider) MergePullRequest(pr *git.PullRequest, message string) error {
+func (g *GitlabProvider) ClosePullRequest(pr *git.PullRequest) error {
 	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
 	if err != nil {
 		return err
 	}
 
-	opt := &gitlab.AcceptMergeRequestOptions{MergeCommitMessage: &message}
-
-	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(pid, *pr.Number, opt)
-	return err
-}
-
-func (g *GitlabProvider) CreateWebHook(data *git.WebhookArguments) error {
-	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
+	opt := &gitlab.UpdateMergeRequestOptions{StateEvent: gitlab.String("close")}
+	mr, _, err := g.Client.MergeRequests.UpdateMergeRequest(pid, *pr.Number, opt)
 	if err != nil {
-		return nil
-	}
-
-	owner := owner(g.Username, data.Owner)
-	webhookURL := util.UrlJoin(data.URL, owner, data.Repo.Name)
-	opt := &gitlab.AddProjectHookOptions{
-		URL:   &webhookURL,
-		Token: &data.Secret,
+		return fmt.Errorf("failed to close merge request %d of %s/%s: %s", *pr.Number, pr.Owner, pr.Repo, err)
 	}
 
-	_, _, err = g.Client.Projects.AddProjectHook(pid, opt)
-	return err
-}
-
-func (p *GitlabProvider) ListWebHooks(owner string, repo string) ([]*git.WebhookArguments, error) {
-	webHooks := []*git.WebhookArguments{}
-	return webHooks, fmt.Errorf("not implemented!")
+	*pr = *fromMergeRequest(mr, pr.Owner, pr.Repo)
+	return nil
 }
 
-func (p *GitlabProvider) UpdateWebHook(data *git.WebhookArguments) error {
-	return fmt.Errorf("not implemented!")
-}
+func (g *GitlabProvider) PullRequestLastCommitStatus(pr *git.PullRequest) (string, error) {
+	owner := pr.Owner
+	repo := pr.Repo
 
-func (g *GitlabProvider) SearchIssues(org, repo, query string) ([]*git.Issue, error) {
-	opt := &gitlab.ListProjectIssuesOptions{Search: &query}
-	return g.searchIssuesWithOptions(org, repo, opt)
-}
+	ref := pr.LastCommitSha
+	if ref == "" {
+		return "", fmt.Errorf("missing String for LastCommitSha %#v", pr)
+	}
 
-func (g *GitlabProvider) SearchIssuesClosedSince(org string, repo string, t time.Time) ([]*git.Issue, error) {
-	closed := "closed"
-	opt := &gitlab.ListProjectIssuesOptions{State: &closed}
-	issues, err := g.searchIssuesWithOptions(org, repo, opt)
+	statuses, err := g.ListCommitStatus(owner, repo, ref)
 	if err != nil {
-		return issues, err
+		return "", err
 	}
-	return git.FilterIssuesClosedSince(issues, t), nil
+	return git.OverallState(statuses), nil
 }
 
-func (g *GitlabProvider) searchIssuesWithOptions(org string, repo string, opt *gitlab.ListProjectIssuesOptions) ([]*git.Issue, error) {
-	pid, err := g.projectId(org, g.Username, repo)
+// ListPullRequestActivity returns the notes of the merge request. GitLab records events such as
+// approvals, pushes and merges as system notes, which are turned into the matching action
+func (g *GitlabProvider) ListPullRequestActivity(pr *git.PullRequest) ([]*git.PullRequestEvent, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("missing Number for merge request %s/%s", pr.Owner, pr.Repo)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
 	if err != nil {
 		return nil, err
 	}
-	issues, _, err := g.Client.Issues.ListProjectIssues(pid, opt)
+	notes := []*gitlab.Note{}
+	options := &gitlab.ListMergeRequestNotesOptions{}
+	for {
+		page, response, err := g.Client.Notes.ListMergeRequestNotes(pid, *pr.Number, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the notes of merge request %s/%s!%d: %s", pr.Owner, pr.Repo, *pr.Number, err)
+		}
+		notes = append(notes, page...)
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+
+	// notes are returned newest first
+	answer := make([]*git.PullRequestEvent, 0, len(notes))
+	for i := len(notes) - 1; i >= 0; i-- {
+		note := notes[i]
+		answer = append(answer, &git.PullRequestEvent{
+			ID:     strconv.Itoa(note.ID),
+			Action: gitlabNoteAction(note),
+			Actor: &git.User{
+				Login: note.Author.Username,
+				Name:  note.Author.Name,
+				Email: note.Author.Email,
+			},
+			CreatedAt: note.CreatedAt,
+			Body:      note.Body,
+		})
+	}
+	return answer, nil
+}
+
+// gitlabMergeRequestChanges are the changes of a merge request as returned by the changes API. ChangesCount is a
+// string as gitlab reports large changes as "1000+", and Overflow is true when it left some diffs out
+type gitlabMergeRequestChanges struct {
+	ChangesCount string `json:"changes_count"`
+	Overflow     bool   `json:"overflow"`
+	Changes      []struct {
+		Diff string `json:"diff"`
+	} `json:"changes"`
+}
+
+// GetPullRequestStats counts the lines of the diffs of the merge request, as gitlab only reports how many files it
+// changes
+func (g *GitlabProvider) GetPullRequestStats(pr *git.PullRequest) (*git.DiffStat, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("missing Number for merge request %s/%s", pr.Owner, pr.Repo)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/merge_requests/%d/changes", pid, *pr.Number), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	changes := &gitlabMergeRequestChanges{}
+	_, err = g.Client.Do(req, changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the changes of merge request %s/%s!%d: %s", pr.Owner, pr.Repo, *pr.Number, err)
+	}
+
+	answer := &git.DiffStat{
+		ChangedFiles: len(changes.Changes),
+		Truncated:    changes.Overflow,
+	}
+	if count, err := strconv.Atoi(strings.TrimSuffix(changes.ChangesCount, "+")); err == nil && count > answer.ChangedFiles {
+		answer.ChangedFiles = count
+		answer.Truncated = true
+	}
+	for _, change := range changes.Changes {
+		additions, deletions := git.CountDiffLines(change.Diff)
+		answer.Additions += additions
+		answer.Deletions += deletions
+	}
+	return answer, nil
+}
+
+// gitlabApprovals are the approvals of a merge request, which the client doesn't support
+type gitlabApprovals struct {
+	Approved      bool `json:"approved"`
+	ApprovalsLeft int  `json:"approvals_left"`
+	ApprovedBy    []struct {
+		User struct {
+			Username string `json:"username"`
+			Name     string `json:"name"`
+		} `json:"user"`
+	} `json:"approved_by"`
+}
+
+// GetPullRequestApprovalState returns the approvals of the merge request. GitLab doesn't say when they were given,
+// so LastApprovedAt is taken from the latest approval note. ResetOnPush is the "reset approvals on push" setting of
+// the project: when it is on, commits pushed after an approval remove it, and the merge request waits for a new one
+func (g *GitlabProvider) GetPullRequestApprovalState(pr *git.PullRequest) (*git.ApprovalState, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("missing Number for merge request %s/%s", pr.Owner, pr.Repo)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/merge_requests/%d/approvals", pid, *pr.Number), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	approvals := &gitlabApprovals{}
+	_, err = g.Client.Do(req, approvals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the approvals of merge request %s/%s!%d: %s", pr.Owner, pr.Repo, *pr.Number, err)
+	}
+
+	answer := &git.ApprovalState{
+		Approved:      approvals.Approved,
+		ApprovalsLeft: approvals.ApprovalsLeft,
+		ApprovedBy:    []*git.User{},
+	}
+	for _, approval := range approvals.ApprovedBy {
+		answer.ApprovedBy = append(answer.ApprovedBy, &git.User{
+			Login: approval.User.Username,
+			Name:  approval.User.Name,
+		})
+	}
+
+	events, err := g.ListPullRequestActivity(pr)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.Action == "approved" {
+			answer.LastApprovedAt = event.CreatedAt
+		}
+	}
+
+	req, err = g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/approvals", pid), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var settings struct {
+		ResetApprovalsOnPush bool `json:"reset_approvals_on_push"`
+	}
+	response, err := g.Client.Do(req, &settings)
+	if err != nil {
+		// editions of gitlab without approval settings never reset approvals
+		if response == nil || response.StatusCode != http.StatusNotFound {
+			return nil, fmt.Errorf("failed to get the approval settings of %s/%s: %s", pr.Owner, pr.Repo, err)
+		}
+	}
+	answer.ResetOnPush = settings.ResetApprovalsOnPush
+	return answer, nil
+}
+
+func gitlabNoteAction(note *gitlab.Note) string {
+	if !note.System {
+		return "commented"
+	}
+	body := strings.ToLower(note.Body)
+	switch {
+	case strings.HasPrefix(body, "approved"):
+		return "approved"
+	case strings.HasPrefix(body, "unapproved"):
+		return "unapproved"
+	case strings.HasPrefix(body, "merged"):
+		return "merged"
+	case strings.HasPrefix(body, "closed"):
+		return "closed"
+	case strings.HasPrefix(body, "reopened"):
+		return "reopened"
+	case strings.HasPrefix(body, "added ") && strings.Contains(body, "commit"):
+		return "pushed"
+	default:
+		return "updated"
+	}
+}
+
+func (g *GitlabProvider) ListCommitStatus(org string, repo string, sha string) ([]*git.RepoStatus, error) {
+	pid, err := g.projectId(org, g.Username, repo)
+	if err != nil {
+		return nil, err
+	}
+	c, _, err := g.Client.Commits.GetCommitStatuses(pid, sha, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*git.RepoStatus
+
+	for _, result := range c {
+		statuses = append(statuses, fromCommitStatus(result))
+	}
+
+	return statuses, nil
+}
+
+func (g *GitlabProvider) GetCommitStatus(org string, repo string, sha string, context string) (*git.RepoStatus, error) {
+	statuses, err := g.ListCommitStatus(org, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	return git.FindRepoStatus(statuses, context), nil
+}
+
+var gitlabBuildStates = map[string]gitlab.BuildStateValue{
+	"success":     gitlab.Success,
+	"failure":     gitlab.Failed,
+	"failed":      gitlab.Failed,
+	"error":       gitlab.Failed,
+	"pending":     gitlab.Pending,
+	"running":     gitlab.Running,
+	"in-progress": gitlab.Running,
+	"canceled":    gitlab.Canceled,
+	"cancelled":   gitlab.Canceled,
+}
+
+func (g *GitlabProvider) UpdateCommitStatus(org string, repo string, sha string, status *git.RepoStatus) (*git.RepoStatus, error) {
+	if err := status.NormalizeContext(git.MaxStatusContextLength); err != nil {
+		return nil, err
+	}
+	state, ok := gitlabBuildStates[status.State]
+	if !ok {
+		return nil, fmt.Errorf("invalid commit status state %s for gitlab", status.State)
+	}
+	pid, err := g.projectId(org, g.Username, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        &status.Context,
+		TargetURL:   &status.TargetURL,
+		Description: &status.Description,
+	}
+	result, _, err := g.Client.Commits.SetCommitStatus(pid, sha, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set the status of commit %s in %s/%s: %s", sha, org, repo, err)
+	}
+	return fromCommitStatus(result), nil
+}
+
+func fromCommitStatus(status *gitlab.CommitStatus) *git.RepoStatus {
+	return &git.RepoStatus{
+		ID:          string(status.ID),
+		Context:     status.Name,
+		URL:         status.TargetURL,
+		TargetURL:   status.TargetURL,
+		State:       status.Status,
+		Description: status.Description,
+		CreatedAt:   status.CreatedAt,
+	}
+}
+
+func (g *GitlabProvider) MergePullRequest(pr *git.PullRequest, message string) error {
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return err
+	}
+
+	opt := &gitlab.AcceptMergeRequestOptions{MergeCommitMessage: &message}
+
+	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(pid, *pr.Number, opt)
+	return err
+}
+
+func (g *GitlabProvider) CreateWebHook(data *git.WebhookArguments) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
+	if err != nil {
+		return err
+	}
+
+	events, err := webHookEventFlags(data.Events)
+	if err != nil {
+		return err
+	}
+
+	owner := owner(g.Username, data.Owner)
+	webhookURL := util.UrlJoin(data.URL, owner, data.Repo.Name)
+	opt := &gitlab.AddProjectHookOptions{
+		URL:                 &webhookURL,
+		Token:               &data.Secret,
+		PushEvents:          events["push"],
+		TagPushEvents:       events["tag_push"],
+		MergeRequestsEvents: events["merge_requests"],
+		IssuesEvents:        events["issues"],
+		NoteEvents:          events["note"],
+	}
+
+	_, _, err = g.Client.Projects.AddProjectHook(pid, opt)
+	return err
+}
+
+func (p *GitlabProvider) ListWebHooks(owner string, repo string) ([]*git.WebhookArguments, error) {
+	webHooks := []*git.WebhookArguments{}
+	return webHooks, fmt.Errorf("not implemented!")
+}
+
+func (p *GitlabProvider) UpdateWebHook(data *git.WebhookArguments) error {
+	return fmt.Errorf("not implemented!")
+}
+
+func (p *GitlabProvider) ListWebHookDeliveries(owner string, repo string, hookID int64) ([]*git.WebHookDelivery, error) {
+	// the recent events of a hook are only shown in the UI, the API does not expose them
+	return nil, fmt.Errorf("list webhook deliveries: %w", git.ErrNotSupported)
+}
+
+func (p *GitlabProvider) RedeliverWebHook(owner string, repo string, hookID int64, deliveryID int64) error {
+	return fmt.Errorf("redeliver webhook: %w", git.ErrNotSupported)
+}
+
+func (p *GitlabProvider) PingWebHook(owner string, repo string, hookID int64) error {
+	return fmt.Errorf("ping webhook: %w", git.ErrNotSupported)
+}
+
+// webHookEvents are the events GitLab webhooks can subscribe to, named after their option without the _events suffix
+var webHookEvents = []string{"push", "tag_push", "merge_requests", "issues", "note"}
+
+// webHookEventFlags returns the flags of the webhook options which subscribe to the events, keyed by event. There
+// are no flags if there are no events so that GitLab subscribes to its defaults
+func webHookEventFlags(events []string) (map[string]*bool, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	flags := map[string]*bool{}
+	for _, event := range webHookEvents {
+		flags[event] = gitlab.Bool(false)
+	}
+	for _, event := range events {
+		if _, ok := flags[event]; !ok {
+			return nil, fmt.Errorf("invalid webhook arguments: unknown event %s, the events are %s", event, strings.Join(webHookEvents, ", "))
+		}
+		flags[event] = gitlab.Bool(true)
+	}
+	return flags, nil
+}
+
+// CreateGroupWebHook creates a webhook on a group which receives the events of every project in the group. It
+// subscribes to every event unless the arguments choose some
+func (g *GitlabProvider) CreateGroupWebHook(group string, data *git.WebhookArguments) error {
+	if err := data.ValidateURL(); err != nil {
+		return err
+	}
+	events := data.Events
+	if len(events) == 0 {
+		events = webHookEvents
+	}
+	flags, err := webHookEventFlags(events)
+	if err != nil {
+		return err
+	}
+
+	opt := &gitlab.AddGroupHookOptions{
+		URL:                 &data.URL,
+		Token:               &data.Secret,
+		PushEvents:          flags["push"],
+		TagPushEvents:       flags["tag_push"],
+		MergeRequestsEvents: flags["merge_requests"],
+		IssuesEvents:        flags["issues"],
+		NoteEvents:          flags["note"],
+	}
+
+	_, _, err = g.Client.Groups.AddGroupHook(group, opt)
+	if err != nil {
+		return fmt.Errorf("failed to create a webhook on group %s: %s", group, err)
+	}
+	return nil
+}
+
+// ListGroupWebHooks returns the webhooks of a group
+func (g *GitlabProvider) ListGroupWebHooks(group string) ([]*git.WebhookArguments, error) {
+	webHooks := []*git.WebhookArguments{}
+	hooks, _, err := g.Client.Groups.ListGroupHooks(group)
+	if err != nil {
+		return webHooks, fmt.Errorf("failed to list the webhooks of group %s: %s", group, err)
+	}
+	for _, hook := range hooks {
+		webHooks = append(webHooks, &git.WebhookArguments{
+			ID:    int64(hook.ID),
+			Owner: group,
+			URL:   hook.URL,
+		})
+	}
+	return webHooks, nil
+}
+
+// DeleteGroupWebHook deletes a webhook from a group
+func (g *GitlabProvider) DeleteGroupWebHook(group string, id int64) error {
+	_, err := g.Client.Groups.DeleteGroupHook(group, int(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook %d of group %s: %s", id, group, err)
+	}
+	return nil
+}
+
+func (g *GitlabProvider) SearchIssues(org, repo, query string) ([]*git.Issue, error) {
+	issueQuery := git.ParseIssueQuery(query)
+	opt := &gitlab.ListProjectIssuesOptions{}
+	switch issueQuery.State {
+	case "open":
+		opt.State = gitlab.String("opened")
+	case "closed":
+		opt.State = gitlab.String("closed")
+	}
+	if len(issueQuery.Labels) > 0 {
+		opt.Labels = gitlab.Labels(issueQuery.Labels)
+	}
+	if issueQuery.Text != "" {
+		opt.Search = gitlab.String(issueQuery.Text)
+	}
+	issues, err := g.searchIssuesWithOptions(org, repo, opt)
+	if err != nil {
+		return issues, err
+	}
+	// gitlab searches the title and description for the text itself but filtering by author needs the id
+	// of the user, so only the author is filtered here
+	return git.IssueQuery{Author: issueQuery.Author}.FilterIssues(issues), nil
+}
+
+func (g *GitlabProvider) SearchIssuesClosedSince(org string, repo string, t time.Time) ([]*git.Issue, error) {
+	closed := "closed"
+	opt := &gitlab.ListProjectIssuesOptions{State: &closed}
+	issues, err := g.searchIssuesWithOptions(org, repo, opt)
+	if err != nil {
+		return issues, err
+	}
+	return git.FilterIssuesClosedSince(issues, t), nil
+}
+
+func (g *GitlabProvider) searchIssuesWithOptions(org string, repo string, opt *gitlab.ListProjectIssuesOptions) ([]*git.Issue, error) {
+	pid, err := g.projectId(org, g.Username, repo)
+	if err != nil {
+		return nil, err
+	}
+	issues, _, err := g.Client.Issues.ListProjectIssues(pid, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -468,10 +1333,20 @@ func (g *GitlabProvider) CreateIssue(owner string, repo string, issue *git.Issue
 		}
 	}
 
+	logins := []string{}
+	for _, assignee := range issue.Assignees {
+		logins = append(logins, assignee.Login)
+	}
+	assigneeIDs, err := g.userIDs(logins)
+	if err != nil {
+		return nil, err
+	}
+
 	opt := &gitlab.CreateIssueOptions{
 		Title:       &issue.Title,
 		Description: &issue.Body,
 		Labels:      labels,
+		AssigneeIDs: assigneeIDs,
 	}
 
 	pid, err := g.projectId(owner, g.Username, repo)
@@ -501,18 +1376,26 @@ func fromGitlabIssue(issue *gitlab.Issue, owner, repo string) *git.Issue {
 		labels = append(labels, git.Label{Name: v})
 	}
 
-	return &git.Issue{
+	answer := &git.Issue{
 		Number:    &issue.IID,
 		URL:       issue.WebURL,
 		Owner:     owner,
 		Repo:      repo,
 		Title:     issue.Title,
 		Body:      issue.Description,
+		State:     &issue.State,
 		Labels:    labels,
 		CreatedAt: issue.CreatedAt,
 		UpdatedAt: issue.UpdatedAt,
 		ClosedAt:  issue.ClosedAt,
 	}
+	if issue.Author != nil {
+		answer.User = &git.User{
+			Login: issue.Author.Username,
+			Name:  issue.Author.Name,
+		}
+	}
+	return answer
 }
 
 func (g *GitlabProvider) AddPRComment(pr *git.PullRequest, comment string) error {
@@ -540,32 +1423,102 @@ func (g *GitlabProvider) CreateIssueComment(owner string, repo string, number in
 	return err
 }
 
-func (g *GitlabProvider) HasIssues() bool {
-	return true
+func (g *GitlabProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	return g.editIssueLabels(owner, repo, number, func(existing []string) []string {
+		for _, label := range labels {
+			if util.StringArrayIndex(existing, label) < 0 {
+				existing = append(existing, label)
+			}
+		}
+		return existing
+	})
 }
 
-func (g *GitlabProvider) IsGitHub() bool {
-	return false
+func (g *GitlabProvider) RemoveLabelFromIssue(owner string, repo string, number int, label string) error {
+	return g.editIssueLabels(owner, repo, number, func(existing []string) []string {
+		answer := []string{}
+		for _, l := range existing {
+			if l != label {
+				answer = append(answer, l)
+			}
+		}
+		return answer
+	})
 }
 
-func (g *GitlabProvider) IsGitea() bool {
-	return false
+// editIssueLabelsOptions sets all of the labels of an issue. Unlike gitlab.UpdateIssueOptions it always sends the
+// labels, as an empty list of labels is how the last label is removed
+type editIssueLabelsOptions struct {
+	// Labels are the comma separated label names
+	Labels string `json:"labels"`
 }
 
-func (g *GitlabProvider) IsBitbucketCloud() bool {
-	return false
+// editIssueLabels replaces the labels of the issue with the ones returned by edit, as the client can only set all of
+// the labels at once
+func (g *GitlabProvider) editIssueLabels(owner string, repo string, number int, edit func([]string) []string) error {
+	pid, err := g.projectId(owner, g.Username, repo)
+	if err != nil {
+		return err
+	}
+	issue, _, err := g.Client.Issues.GetIssue(pid, number)
+	if err != nil {
+		return fmt.Errorf("failed to get issue %s/%s#%d: %s", owner, repo, number, err)
+	}
+	labels := edit(append([]string{}, issue.Labels...))
+	opt := &editIssueLabelsOptions{Labels: strings.Join(labels, ",")}
+	req, err := g.Client.NewRequest("PUT", fmt.Sprintf("projects/%s/issues/%d", pid, number), opt, nil)
+	if err != nil {
+		return err
+	}
+	_, err = g.Client.Do(req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set the labels of issue %s/%s#%d: %s", owner, repo, number, err)
+	}
+	return nil
 }
 
-func (g *GitlabProvider) IsBitbucketServer() bool {
-	return false
+func (g *GitlabProvider) HasIssues() bool {
+	return true
 }
 
-func (g *GitlabProvider) IsGerrit() bool {
-	return false
-}
+func (g *GitlabProvider) SetIssuesEnabled(org string, name string, enabled bool) error {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	options := &gitlab.EditProjectOptions{
+		IssuesEnabled: &enabled,
+	}
+
+	_, _, err = g.Client.Projects.EditProject(pid, options)
+	if err != nil {
+		return fmt.Errorf("failed to edit project %s/%s: %s", org, name, err)
+	}
+	return nil
+}
+
+func (g *GitlabProvider) IsGitHub() bool {
+	return false
+}
+
+func (g *GitlabProvider) IsGitea() bool {
+	return false
+}
+
+func (g *GitlabProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+func (g *GitlabProvider) IsBitbucketServer() bool {
+	return false
+}
+
+func (g *GitlabProvider) IsGerrit() bool {
+	return false
+}
 
 func (g *GitlabProvider) Kind() string {
-	return "gitlab"
+	return git.KindGitlab
 }
 
 func (g *GitlabProvider) JenkinsWebHookPath(gitURL string, secret string) string {
@@ -585,14 +1538,42 @@ func (p *GitlabProvider) BranchArchiveURL(org string, name string, branch string
 }
 
 func (p *GitlabProvider) CurrentUsername() string {
+	if p.Username == "" {
+		user, err := p.GetCurrentUser()
+		if err == nil {
+			p.Username = user.Login
+		}
+	}
 	return p.Username
 }
 
+func (p *GitlabProvider) GetCurrentUser() (*git.User, error) {
+	user, _, err := p.Client.Users.CurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the authenticated user due to: %s", err)
+	}
+	return &git.User{
+		Login:     user.Username,
+		URL:       user.WebURL,
+		AvatarURL: user.AvatarURL,
+		Name:      user.Name,
+		Email:     user.Email,
+	}, nil
+}
+
 func (p *GitlabProvider) UserInfo(username string) *git.User {
-	users, _, err := p.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: &username})
+	user, _ := p.getUser(username)
+	return user
+}
 
-	if err != nil || len(users) == 0 {
-		return nil
+// getUser looks up a user, or returns nil if there is no such user
+func (p *GitlabProvider) getUser(username string) (*git.User, error) {
+	users, _, err := p.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: &username})
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", username, err)
+	}
+	if len(users) == 0 {
+		return nil, nil
 	}
 
 	user := users[0]
@@ -603,10 +1584,279 @@ func (p *GitlabProvider) UserInfo(username string) *git.User {
 		AvatarURL: user.AvatarURL,
 		Name:      user.Name,
 		Email:     user.Email,
+	}, nil
+}
+
+func (p *GitlabProvider) GetUsers(usernames []string) (map[string]*git.User, error) {
+	return p.users.GetUsersBatch(usernames, p.getUsers)
+}
+
+// usersPerQuery is how many users a GraphQL query returns without paging
+const usersPerQuery = 100
+
+// usersQuery looks up a set of users, which the REST API can only look up one at a time
+const usersQuery = `query($usernames: [String!]) {
+	users(usernames: $usernames) { nodes { username name publicEmail avatarUrl webUrl } }
+}`
+
+type usersQueryResponse struct {
+	Data struct {
+		Users struct {
+			Nodes []struct {
+				Username    string `json:"username"`
+				Name        string `json:"name"`
+				PublicEmail string `json:"publicEmail"`
+				AvatarURL   string `json:"avatarUrl"`
+				WebURL      string `json:"webUrl"`
+			} `json:"nodes"`
+		} `json:"users"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// getUsers looks the users up with the GraphQL API, a page of usernames per query. Servers which can't look users
+// up by username with GraphQL have them looked up one at a time
+func (p *GitlabProvider) getUsers(usernames []string) (map[string]*git.User, error) {
+	answer := map[string]*git.User{}
+	for start := 0; start < len(usernames); start += usersPerQuery {
+		end := start + usersPerQuery
+		if end > len(usernames) {
+			end = len(usernames)
+		}
+		err := p.queryUsers(usernames[start:end], answer)
+		if git.IsNotSupported(err) {
+			var users *git.UserCache
+			return users.GetUsers(usernames, p.getUser)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
+	return answer, nil
+}
+
+// queryUsers adds the users with the given usernames to the answer, returning ErrNotSupported if the server has
+// no GraphQL API or can't look users up by username
+func (p *GitlabProvider) queryUsers(usernames []string, answer map[string]*git.User) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     usersQuery,
+		"variables": map[string]interface{}{"usernames": usernames},
+	})
+	if err != nil {
+		return err
+	}
+	serverURL := git.NormalizeServerURL(p.URL)
+	if IsGitLabServerURL(serverURL) {
+		serverURL = "https://gitlab.com"
+	}
+	req, err := http.NewRequest(http.MethodPost, util.UrlJoin(serverURL, "/api/graphql"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(p.Options.RequestContext())
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Private-Token", p.token)
+	}
+	resp, err := p.Options.NewHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("get users: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("get users with graphql: %w", git.ErrNotSupported)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("get users: %s", resp.Status)
+	}
+
+	var result usersQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("get users: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		// older servers have no users query to look users up by username
+		return fmt.Errorf("get users with graphql: %s: %w", result.Errors[0].Message, git.ErrNotSupported)
+	}
+	for _, user := range result.Data.Users.Nodes {
+		answer[user.Username] = &git.User{
+			Login:     user.Username,
+			URL:       user.WebURL,
+			AvatarURL: user.AvatarURL,
+			Name:      user.Name,
+			Email:     user.PublicEmail,
+		}
+	}
+	return nil
+}
+
+func (g *GitlabProvider) ListMilestones(org string, name string) ([]*git.Milestone, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	options := &gitlab.ListMilestonesOptions{}
+
+	answer := []*git.Milestone{}
+	for {
+		milestones, response, err := g.Client.Milestones.ListMilestones(pid, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the milestones of %s/%s: %s", org, name, err)
+		}
+		for _, milestone := range milestones {
+			answer = append(answer, fromGitlabMilestone(milestone))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return answer, nil
+}
+
+func (g *GitlabProvider) CreateMilestone(org string, name string, title string, due *time.Time) (*git.Milestone, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	options := &gitlab.CreateMilestoneOptions{
+		Title: &title,
+	}
+	if due != nil {
+		dueDate := gitlab.ISOTime(*due)
+		options.DueDate = &dueDate
+	}
+	milestone, _, err := g.Client.Milestones.CreateMilestone(pid, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create milestone %s on %s/%s: %s", title, org, name, err)
+	}
+	return fromGitlabMilestone(milestone), nil
+}
+
+func (g *GitlabProvider) CloseMilestone(org string, name string, id int64) error {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	options := &gitlab.UpdateMilestoneOptions{
+		StateEvent: gitlab.String("close"),
+	}
+	_, _, err = g.Client.Milestones.UpdateMilestone(pid, int(id), options)
+	if err != nil {
+		return fmt.Errorf("failed to close milestone %d of %s/%s: %s", id, org, name, err)
+	}
+	return nil
+}
+
+// fromGitlabMilestone converts a milestone, whose issues gitlab doesn't count. Open milestones are active on gitlab
+func fromGitlabMilestone(milestone *gitlab.Milestone) *git.Milestone {
+	answer := &git.Milestone{
+		ID:    int64(milestone.ID),
+		Title: milestone.Title,
+		State: git.MilestoneStateOpen,
+	}
+	if milestone.State == "closed" {
+		answer.State = git.MilestoneStateClosed
+	}
+	if milestone.DueDate != nil {
+		due := time.Time(*milestone.DueDate)
+		answer.DueOn = &due
+	}
+	return answer
+}
+
+// ListContributors lists contributors by name and email, as gitlab doesn't know their usernames. The client
+// can't page through contributors yet so the requests are made directly
+func (g *GitlabProvider) ListContributors(org string, name string) ([]*git.Contributor, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	options := &gitlab.ListOptions{}
+
+	answer := []*git.Contributor{}
+	for {
+		req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/repository/contributors", pid), options, nil)
+		if err != nil {
+			return nil, err
+		}
+		contributors := []*gitlab.Contributor{}
+		response, err := g.Client.Do(req, &contributors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the contributors of %s/%s: %s", org, name, err)
+		}
+		for _, contributor := range contributors {
+			answer = append(answer, &git.Contributor{
+				User: &git.User{
+					Name:  contributor.Name,
+					Email: contributor.Email,
+				},
+				Contributions: contributor.Commits,
+			})
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	git.SortContributors(answer)
+	return answer, nil
+}
+
+// releaseOptions are the options of creating or updating a release, which the client can't do yet
+type releaseOptions struct {
+	Name        *string `url:"name,omitempty" json:"name,omitempty"`
+	TagName     *string `url:"tag_name,omitempty" json:"tag_name,omitempty"`
+	Description *string `url:"description,omitempty" json:"description,omitempty"`
 }
 
 func (g *GitlabProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *git.Release) error {
+	pid, err := g.projectId(owner, g.Username, repo)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("projects/%s/releases/%s", pid, url.PathEscape(tag))
+
+	req, err := g.Client.NewRequest("GET", path, nil, nil)
+	if err != nil {
+		return err
+	}
+	release := &gitlabRelease{}
+	response, err := g.Client.Do(req, release)
+	if err != nil && (response == nil || response.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to get the release of tag %s of %s/%s: %s", tag, owner, repo, err)
+	}
+
+	var opt *releaseOptions
+	if err != nil {
+		opt = &releaseOptions{
+			Name:        &releaseInfo.Name,
+			TagName:     &tag,
+			Description: &releaseInfo.Body,
+		}
+		req, err = g.Client.NewRequest("POST", fmt.Sprintf("projects/%s/releases", pid), opt, nil)
+	} else {
+		// keep the name and notes of the release unless there are new ones
+		opt = &releaseOptions{}
+		if releaseInfo.Name != "" {
+			opt.Name = &releaseInfo.Name
+		}
+		if releaseInfo.Body != "" {
+			opt.Description = &releaseInfo.Body
+		}
+		req, err = g.Client.NewRequest("PUT", path, opt, nil)
+	}
+	if err != nil {
+		return err
+	}
+	release = &gitlabRelease{}
+	_, err = g.Client.Do(req, release)
+	if err != nil {
+		return fmt.Errorf("failed to save the release of tag %s of %s/%s: %s", tag, owner, repo, err)
+	}
+	releaseInfo.URL = g.fromGitlabRelease(owner, repo, release).URL
 	return nil
 }
 
@@ -614,13 +1864,322 @@ func (p *GitlabProvider) IssueURL(org string, name string, number int, isPull bo
 	return ""
 }
 
+func (g *GitlabProvider) DeleteBranch(org string, name string, branch string) error {
+	repo, err := g.GetRepository(org, name)
+	if err != nil {
+		return err
+	}
+	if branch == repo.DefaultBranch {
+		return fmt.Errorf("delete branch %s of %s/%s: %w", branch, org, name, git.ErrDefaultBranch)
+	}
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	response, err := g.Client.Branches.DeleteBranch(pid, branch)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to delete branch %s of %s/%s: %s", branch, org, name, err)
+	}
+	return nil
+}
+
+func (g *GitlabProvider) CreateBranch(org string, name string, branch string, fromRef string) error {
+	sha, err := g.ResolveRef(org, name, fromRef)
+	if err != nil {
+		return err
+	}
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	options := &gitlab.CreateBranchOptions{
+		Branch: &branch,
+		Ref:    &sha,
+	}
+	_, response, err := g.Client.Branches.CreateBranch(pid, options)
+	if err != nil {
+		// gitlab answers 400 with "Branch already exists" rather than a conflict
+		if response != nil && response.StatusCode == http.StatusBadRequest && strings.Contains(err.Error(), "already exists") {
+			return fmt.Errorf("create branch %s of %s/%s: %w", branch, org, name, git.ErrAlreadyExists)
+		}
+		return fmt.Errorf("failed to create branch %s of %s/%s: %s", branch, org, name, err)
+	}
+	return nil
+}
+
+func (g *GitlabProvider) CreateTag(org string, name string, tag string, sha string, message string) error {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.Client.Tags.CreateTag(pid, &gitlab.CreateTagOptions{
+		TagName: &tag,
+		Ref:     &sha,
+		Message: &message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s on project %s due to: %s", tag, pid, err)
+	}
+	return nil
+}
+
+func (g *GitlabProvider) GetTag(org string, name string, tag string) (*git.GitTag, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	t, r, err := g.Client.Tags.GetTag(pid, tag)
+	if r != nil && r.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	answer := &git.GitTag{
+		Name:    t.Name,
+		Message: t.Message,
+	}
+	if t.Commit != nil {
+		answer.SHA = t.Commit.ID
+	}
+	return answer, nil
+}
+
+func (g *GitlabProvider) ListRepositoryTags(org string, name string) ([]*git.GitTag, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	options := &gitlab.ListTagsOptions{}
+
+	answer := []*git.GitTag{}
+	for {
+		tags, response, err := g.Client.Tags.ListTags(pid, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the tags of %s/%s: %s", org, name, err)
+		}
+		for _, t := range tags {
+			tag := &git.GitTag{
+				Name:    t.Name,
+				Message: t.Message,
+			}
+			if t.Commit != nil {
+				tag.SHA = t.Commit.ID
+				tag.Date = t.Commit.CommittedDate
+			}
+			answer = append(answer, tag)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = response.NextPage
+	}
+	return answer, nil
+}
+
+// GetAheadBehind compares the branch heads within each project as the GitLab compare API
+// cannot compare across projects, so each project must contain the other's head commit
+func (g *GitlabProvider) GetAheadBehind(org string, name string, upstreamOrg string, upstreamName string, branch string) (int, int, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return 0, 0, err
+	}
+	upstreamPid, err := g.projectId(upstreamOrg, g.Username, upstreamName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// the fork doesn't have the commits only upstream has, so each side is compared from the other project
+	ahead, err := g.compareProjects(pid, upstreamPid, branch)
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := g.compareProjects(upstreamPid, pid, branch)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(ahead.Commits), len(behind.Commits), nil
+}
+
+// compareProjectsOptions are the options of a compare across projects, which the client can't make yet
+type compareProjectsOptions struct {
+	From          string `url:"from"`
+	To            string `url:"to"`
+	FromProjectID string `url:"from_project_id"`
+}
+
+// compareProjects compares the branch of project fromPid with the same branch of project pid
+func (g *GitlabProvider) compareProjects(pid string, fromPid string, branch string) (*gitlab.Compare, error) {
+	options := &compareProjectsOptions{
+		From:          branch,
+		To:            branch,
+		FromProjectID: fromPid,
+	}
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/repository/compare", pid), options, nil)
+	if err != nil {
+		return nil, err
+	}
+	compare := &gitlab.Compare{}
+	_, err = g.Client.Do(req, compare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare branch %s of project %s with project %s due to: %s", branch, pid, fromPid, err)
+	}
+	return compare, nil
+}
+
+// SyncFork merges the upstream branch into the fork using a local clone
+func (g *GitlabProvider) SyncFork(org string, name string, upstreamOrg string, upstreamName string, branch string) error {
+	return git.SyncForkWithGit(g, g.Git, g.Options, g.token, org, name, upstreamOrg, upstreamName, branch)
+}
+
 func (p *GitlabProvider) AddCollaborator(user string, organisation string, repo string) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gitlab. Please add user: %v as a collaborator to this project.\n", user)
 	return nil
 }
 
-func (p *GitlabProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on gitlab")
+// ListInvitations isn't supported as gitlab makes users members of a project as soon as they are added. Only
+// people without an account are invited, by email
+func (p *GitlabProvider) ListInvitations() ([]*git.Invitation, error) {
+	return nil, fmt.Errorf("list invitations: %w", git.ErrNotSupported)
+}
+
+// AcceptInvitation fails as invitations are accepted with the token sent by email, which the API can't do
+func (p *GitlabProvider) AcceptInvitation(ID int64) error {
+	return fmt.Errorf("accept invitation: %w", git.ErrNotSupported)
+}
+
+func (g *GitlabProvider) GetFileLastCommit(org string, name string, path string, ref string) (*git.Commit, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	opt := &gitlab.ListCommitsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 1},
+		RefName:     &ref,
+		Path:        &path,
+	}
+	commits, _, err := g.Client.Commits.ListCommits(pid, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the commits of %s in %s/%s: %s", path, org, name, err)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	commit := commits[0]
+	return &git.Commit{
+		SHA:     commit.ID,
+		Message: commit.Message,
+		Author: &git.User{
+			Name:  commit.AuthorName,
+			Email: commit.AuthorEmail,
+		},
+		Committer: &git.User{
+			Name:  commit.CommitterName,
+			Email: commit.CommitterEmail,
+		},
+	}, nil
+}
+
+// GetContent returns the file at the path on the ref. The content is decoded, Encoding is the encoding gitlab sent
+// it with. If there is no such file the returned error wraps the gitlab 404 response
+func (g *GitlabProvider) GetContent(org string, name string, path string, ref string) (*git.FileContent, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, response, err := g.Client.RepositoryFiles.GetFile(pid, path, &gitlab.GetFileOptions{Ref: &ref})
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("file %s in %s/%s at %s: %w", path, org, name, ref, git.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get file %s from %s/%s at %s: %s", path, org, name, ref, err)
+	}
+
+	content := file.Content
+	if file.Encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode file %s from %s/%s: %w", path, org, name, err)
+		}
+		content = string(data)
+	}
+	return &git.FileContent{
+		Type:     "file",
+		Encoding: file.Encoding,
+		Size:     file.Size,
+		Name:     file.FileName,
+		Path:     file.FilePath,
+		Content:  content,
+		Sha:      file.BlobID,
+	}, nil
+}
+
+// ResolveRef returns the SHA of the commit the branch or tag points at
+func (g *GitlabProvider) ResolveRef(org string, name string, ref string) (string, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return "", err
+	}
+
+	// gitlab resolves branches, tags and SHAs given as the commit
+	commit, response, err := g.Client.Commits.GetCommit(pid, ref)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("ref %s of %s/%s: %w", ref, org, name, git.ErrNotFound)
+		}
+		return "", fmt.Errorf("failed to resolve ref %s of %s/%s: %s", ref, org, name, err)
+	}
+	return commit.ID, nil
+}
+
+// commitRefsOptions are the options of the commit refs API, which the client doesn't support
+type commitRefsOptions struct {
+	gitlab.ListOptions
+	Type string `url:"type,omitempty" json:"type,omitempty"`
+}
+
+// gitlabCommitRef is a branch or tag containing a commit
+type gitlabCommitRef struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// BranchesContainingCommit returns the names of the branches which contain the commit. A missing commit gives an
+// error wrapping ErrNotFound
+func (g *GitlabProvider) BranchesContainingCommit(org string, name string, sha string) ([]string, error) {
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &commitRefsOptions{Type: "branch"}
+	answer := []string{}
+	for {
+		req, err := g.Client.NewRequest("GET", fmt.Sprintf("projects/%s/repository/commits/%s/refs", pid, url.PathEscape(sha)), options, nil)
+		if err != nil {
+			return nil, err
+		}
+		var refs []*gitlabCommitRef
+		response, err := g.Client.Do(req, &refs)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("commit %s of %s/%s: %w", sha, org, name, git.ErrNotFound)
+			}
+			return nil, fmt.Errorf("failed to list the branches of %s/%s containing %s: %s", org, name, sha, err)
+		}
+		for _, ref := range refs {
+			answer = append(answer, ref.Name)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = response.NextPage
+	}
+	return answer, nil
 }
 
 // GitlabAccessTokenURL returns the URL to click on to generate a personal access token for the Git provider
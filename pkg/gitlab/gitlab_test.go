@@ -3,13 +3,19 @@ package gitlab
 import (
 	"testing"
 
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/stretchr/testify/suite"
+	"github.com/wbrefvem/go-gits/pkg/git"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -18,6 +24,7 @@ const (
 	gitlabOrgName     = "testorg"
 	gitlabProjectName = "test-project"
 	gitlabProjectID   = "5690870"
+	gitlabCommitSHA   = "18f3e63d05582537db6d183d9d557be09e1f90c8"
 )
 
 type GitlabProviderSuite struct {
@@ -25,6 +32,30 @@ type GitlabProviderSuite struct {
 	mux      *http.ServeMux
 	server   *httptest.Server
 	provider *GitlabProvider
+
+	// milestone is the last body sent to the milestones API
+	milestone map[string]interface{}
+	// groupHook is the last body posted to the group hooks API
+	groupHook map[string]interface{}
+	// deletedGroupHook is the path of the last group hook deleted
+	deletedGroupHook string
+	// releaseMethod and release are the method and body of the last request saving a release
+	releaseMethod string
+	release       map[string]interface{}
+	// deletedBranches are the branches deleted through the branches API
+	deletedBranches []string
+	// createdBranch is the last body posted to the branches API
+	createdBranch map[string]interface{}
+	// projectEdit is the last body sent to edit the project
+	projectEdit map[string]interface{}
+	// mergeRequest is the last body posted to the merge requests API
+	mergeRequest map[string]interface{}
+	// issueEdit is the last body sent to edit an issue
+	issueEdit map[string]interface{}
+	// mergeRequestEdit is the last body sent to edit the created merge request
+	mergeRequestEdit map[string]interface{}
+	// userQueries are the usernames of each GraphQL query for users
+	userQueries [][]string
 }
 
 func (suite *GitlabProviderSuite) SetupSuite() {
@@ -53,18 +84,9 @@ func setup(suite *GitlabProviderSuite) (*http.ServeMux, *httptest.Server, *Gitla
 	client := gitlab.NewClient(nil, "")
 	client.SetBaseURL(server.URL)
 
-	userAuth := &auth.UserAuth{
-		Username: gitlabUserName,
-		ApiToken: "test",
-	}
-
-	authServer := &auth.AuthServer{
-		URL:   server.URL,
-		Users: []*auth.UserAuth{userAuth},
-	}
 	// Gitlab provider that we want to test
-	git := NewGitCLI()
-	provider, _ := WithGitlabClient(authServer, userAuth, client, git)
+	gitter := git.NewGitCLI()
+	provider, _ := WithGitlabClient(server.URL, gitlabUserName, client, gitter)
 
 	return mux, server, provider.(*GitlabProvider)
 }
@@ -81,7 +103,21 @@ func configureGitlabMock(suite *GitlabProviderSuite, mux *http.ServeMux) {
 		src, err := ioutil.ReadFile("test_data/gitlab/group-projects.json")
 
 		suite.Require().Nil(err)
-		w.Write(src)
+		if r.URL.Query().Get("per_page") != "1" {
+			w.Write(src)
+			return
+		}
+		// a page of one project at a time
+		projects := []json.RawMessage{}
+		suite.Require().Nil(json.Unmarshal(src, &projects))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		if page < len(projects) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		}
+		fmt.Fprintf(w, "[%s]", projects[page-1])
 	})
 
 	mux.HandleFunc(fmt.Sprintf("/api/v4/users/%s/projects", gitlabUserName), func(w http.ResponseWriter, r *http.Request) {
@@ -91,9 +127,373 @@ func configureGitlabMock(suite *GitlabProviderSuite, mux *http.ServeMux) {
 		w.Write(src)
 	})
 
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/statuses/%s", gitlabProjectID, gitlabCommitSHA), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal("POST", r.Method)
+		options := map[string]string{}
+		err := json.NewDecoder(r.Body).Decode(&options)
+		suite.Require().Nil(err)
+
+		// echo the options back as the created status
+		fmt.Fprintf(w, `{"id": 93, "sha": "%s", "status": "%s", "name": "%s", "target_url": "%s", "description": "%s"}`,
+			gitlabCommitSHA, options["state"], options["name"], options["target_url"], options["description"])
+	})
+
+	// the statuses of the commit are listed newest first, with an older build status
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/commits/%s/statuses", gitlabProjectID, gitlabCommitSHA), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 95, "status": "success", "name": "ci/build", "created_at": "2018-10-09T10:05:00Z"},
+			{"id": 94, "status": "failed", "name": "ci/lint", "created_at": "2018-10-09T10:02:00Z"},
+			{"id": 93, "status": "failed", "name": "ci/build", "created_at": "2018-10-09T10:00:00Z"}
+		]`)
+	})
+
+	// a compare across projects lists the commits of the project that the other project doesn't have
+	compare := func(fromProjectID string, commits ...string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			suite.Require().Equal(fromProjectID, r.URL.Query().Get("from_project_id"))
+			suite.Require().Equal("master", r.URL.Query().Get("from"))
+			suite.Require().Equal("master", r.URL.Query().Get("to"))
+			result := []map[string]string{}
+			for _, id := range commits {
+				result = append(result, map[string]string{"id": id})
+			}
+			err := json.NewEncoder(w).Encode(map[string]interface{}{"commits": result})
+			suite.Require().Nil(err)
+		}
+	}
+	mux.HandleFunc("/api/v4/projects/5860291/repository/compare", compare("5861335", "a1"))
+	mux.HandleFunc("/api/v4/projects/5861335/repository/compare", compare("5860291", "b1", "b2", "b3"))
+
+	// the notes of the merge request come back newest first, a page at a time
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/1/notes", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id": 301, "body": "Looks good", "system": false, "created_at": "2018-06-04T10:00:00Z",
+				"author": {"username": "reviewer", "name": "Re Viewer"}}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"id": 303, "body": "merged", "system": true, "created_at": "2018-06-04T12:00:00Z",
+				"author": {"username": "testperson", "name": "Test Person"}},
+			{"id": 302, "body": "approved this merge request", "system": true, "created_at": "2018-06-04T11:00:00Z",
+				"author": {"username": "reviewer", "name": "Re Viewer"}}]`)
+	})
+
+	// merge request 1 was approved before its last commit was pushed, and the project resets approvals on push
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/1/approvals", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 11, "iid": 1, "approved": false, "approvals_required": 1, "approvals_left": 1, "approved_by": []}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/approvals", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"approvals_before_merge": 1, "reset_approvals_on_push": true}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/1/commits", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "b83d6e391c22777fca1ed3012fce84f633d7fed0", "message": "Fix the review comments",
+				"author_email": "reviewer@example.com", "committed_date": "2018-06-04T11:30:00Z"},
+			{"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "message": "Add a feature",
+				"author_email": "reviewer@example.com", "committed_date": "2018-06-04T09:00:00Z"}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/1/changes", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 11, "iid": 1, "changes_count": "2", "overflow": false, "changes": [
+			{"old_path": "README.md", "new_path": "README.md", "diff": "@@ -1,2 +1,3 @@\n # Title\n-Old text\n+New text\n+More text\n"},
+			{"old_path": "main.go", "new_path": "main.go", "diff": "@@ -10 +10,0 @@\n-\treturn nil\n"}]}`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/4", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPut, r.Method)
+		suite.mergeRequestEdit = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.mergeRequestEdit))
+		fmt.Fprint(w, `{"id": 14, "iid": 4, "state": "opened"}`)
+	})
+
+	mux.HandleFunc("/api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("username") {
+		case "some":
+			fmt.Fprint(w, `[{"id": 21, "username": "some"}]`)
+		case "most":
+			fmt.Fprint(w, `[{"id": 23, "username": "most"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Variables struct {
+				Usernames []string `json:"usernames"`
+			} `json:"variables"`
+		}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&query))
+		suite.userQueries = append(suite.userQueries, query.Variables.Usernames)
+		nodes := []string{}
+		for _, username := range query.Variables.Usernames {
+			if username != "nobody" {
+				nodes = append(nodes, fmt.Sprintf(`{"username": "%s", "name": "User %s", "publicEmail": "%s@example.com"}`,
+					username, username, username))
+			}
+		}
+		fmt.Fprintf(w, `{"data": {"users": {"nodes": [%s]}}}`, strings.Join(nodes, ","))
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests/2", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 12, "iid": 2, "state": "opened", "title": "Promote to version 1.0.3",
+			"source_branch": "promote-1.0.3", "target_branch": "master", "sha": "762941318ee16e59dabbacb1b4049eec22f0d303",
+			"labels": ["promotion", "do-not-merge"], "author": {"username": "testperson"}}`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/merge_requests", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.mergeRequest = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.mergeRequest))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id": 14, "iid": 4, "state": "opened", "title": "%s", "source_branch": "%s",
+				"target_branch": "%s", "author": {"username": "testperson"}}`,
+				suite.mergeRequest["title"], suite.mergeRequest["source_branch"], suite.mergeRequest["target_branch"])
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("source_branch") == "" && query.Get("author_username") == "" {
+			// every merge request in the state asked for, a page at a time
+			if query.Get("page") == "2" {
+				fmt.Fprint(w, `[{"id": 13, "iid": 3, "state": "closed", "source_branch": "promote-1.0.2", "target_branch": "master",
+					"author": {"username": "testperson"}}]`)
+				return
+			}
+			w.Header().Set("X-Next-Page", "2")
+			if query.Get("state") == "opened" {
+				fmt.Fprint(w, `[{"id": 12, "iid": 2, "state": "opened", "source_branch": "promote-1.0.3", "target_branch": "master",
+					"author": {"username": "testperson"}}]`)
+				return
+			}
+			suite.Require().Equal("all", query.Get("state"))
+			fmt.Fprint(w, `[{"id": 12, "iid": 2, "state": "opened", "source_branch": "promote-1.0.3", "target_branch": "master",
+					"author": {"username": "testperson"}},
+				{"id": 11, "iid": 1, "state": "merged", "source_branch": "promote-1.0.1", "target_branch": "master",
+					"merged_at": "2018-06-04T12:00:00Z", "author": {"username": "testperson"}}]`)
+			return
+		}
+
+		suite.Require().Equal("opened", query.Get("state"))
+		if branch := query.Get("source_branch"); branch != "" {
+			if branch != "promote-1.0.3" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+		} else {
+			suite.Require().Equal(gitlabUserName, query.Get("author_username"))
+		}
+		fmt.Fprint(w, `[{"id": 12, "iid": 2, "state": "opened", "title": "Promote to version 1.0.3",
+			"source_branch": "promote-1.0.3", "target_branch": "master", "author": {"username": "testperson"}}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/releases", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.releaseMethod = r.Method
+			suite.release = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.release))
+			fmt.Fprintf(w, `{"name": "%s", "tag_name": "%s", "description": "%s"}`,
+				suite.release["name"], suite.release["tag_name"], suite.release["description"])
+			return
+		}
+		fmt.Fprint(w, `[{"name": "Release 1.1", "tag_name": "v1.1", "description": "Adds promotion",
+				"assets": {"links": [{"id": 1, "name": "linux-amd64", "url": "https://example.com/v1.1/linux-amd64"},
+					{"id": 2, "name": "darwin-amd64", "url": "https://example.com/v1.1/darwin-amd64"}]},
+				"_links": {"self": "https://gitlab.com/testperson/test-project/-/releases/v1.1"}},
+			{"name": "Release 1.0", "tag_name": "v1.0", "description": "The first release",
+				"assets": {"links": [{"id": 3, "name": "checksums.txt", "url": "https://example.com/v1.0/checksums.txt"}]}}]`)
+	})
+
+	// only v1.1 has a release
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/releases/", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/releases/v1.1") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			suite.releaseMethod = r.Method
+			suite.release = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.release))
+		}
+		fmt.Fprint(w, `{"name": "Release 1.1", "tag_name": "v1.1", "description": "Adds promotion",
+			"_links": {"self": "https://gitlab.com/testperson/test-project/-/releases/v1.1"}}`)
+	})
+
+	// gitlab matches the words of the search anywhere in the title or description
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal("flaky build", r.URL.Query().Get("search"))
+		suite.Require().Equal("opened", r.URL.Query().Get("state"))
+		fmt.Fprint(w, `[{"id": 41, "iid": 4, "title": "The build is flaky", "state": "opened",
+				"author": {"username": "testperson", "name": "Test Person"}},
+			{"id": 42, "iid": 5, "title": "Flaky tests break the build", "state": "opened",
+				"author": {"username": "reviewer", "name": "Re Viewer"}}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues/4", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			suite.issueEdit = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.issueEdit))
+		}
+		fmt.Fprint(w, `{"id": 41, "iid": 4, "title": "The build is flaky", "state": "opened", "labels": ["bug", "triage"],
+			"author": {"username": "testperson", "name": "Test Person"}}`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues/5", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			suite.issueEdit = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.issueEdit))
+		}
+		fmt.Fprint(w, `{"id": 42, "iid": 5, "title": "Flaky tests break the build", "state": "opened", "labels": ["flaky"],
+			"author": {"username": "reviewer", "name": "Re Viewer"}}`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/groups/%s/hooks", gitlabOrgName), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.groupHook = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.groupHook))
+			fmt.Fprint(w, `{"id": 7, "url": "https://jenkins.example.com/gitlab-webhook/"}`)
+			return
+		}
+		suite.Require().Equal(http.MethodGet, r.Method)
+		fmt.Fprint(w, `[{"id": 7, "url": "https://jenkins.example.com/gitlab-webhook/"},
+			{"id": 8, "url": "https://chat.example.com/hooks/gitlab"}]`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/groups/%s/hooks/", gitlabOrgName), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		suite.deletedGroupHook = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": 1, "username": "%s", "name": "Test Person", "email": "testperson@example.com",
+			"web_url": "https://gitlab.com/%s"}`, gitlabUserName, gitlabUserName)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/milestones", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			suite.milestone = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.milestone))
+			fmt.Fprint(w, `{"id": 14, "iid": 3, "title": "v1.2", "state": "active", "due_date": "2018-12-01"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 12, "iid": 1, "title": "v1.0", "state": "closed", "due_date": "2018-10-09"},
+			{"id": 13, "iid": 2, "title": "v1.1", "state": "active", "due_date": null}]`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/milestones/14", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPut, r.Method)
+		suite.milestone = map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.milestone))
+		fmt.Fprint(w, `{"id": 14, "iid": 3, "title": "v1.2", "state": "closed", "due_date": "2018-12-01"}`)
+	})
+
+	// only README.md has commits, a page of one is enough for the last of them
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/commits", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal("master", r.URL.Query().Get("ref_name"))
+		suite.Require().Equal("1", r.URL.Query().Get("per_page"))
+		if r.URL.Query().Get("path") != "README.md" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "message": "Sanitize for network graph",
+			"author_name": "randx", "author_email": "dmitriy.zaporozhets@gmail.com",
+			"committer_name": "Dmitriy", "committer_email": "dmitriy.zaporozhets@gmail.com"}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/contributors", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "Most", "email": "most@example.com", "commits": 12}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"name": "Some", "email": "some@example.com", "commits": 5}, {"name": "Few", "email": "few@example.com", "commits": 1}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/commits/6104942438c14ec7bd21c6cd5bd995272b3faff6/refs", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "branch" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"type": "branch", "name": "release"}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"type": "branch", "name": "master"}, {"type": "branch", "name": "feature"}]`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/branches", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodPost, r.Method)
+		body := map[string]interface{}{}
+		suite.Require().Nil(json.NewDecoder(r.Body).Decode(&body))
+		if body["branch"] == "master" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message": "Branch already exists"}`)
+			return
+		}
+		suite.createdBranch = body
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"name": "%s", "commit": {"id": "%s"}}`, body["branch"], body["ref"])
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/branches/", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Equal(http.MethodDelete, r.Method)
+		if !strings.HasSuffix(r.URL.Path, "/branches/feature") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "404 Branch Not Found"}`)
+			return
+		}
+		suite.deletedBranches = append(suite.deletedBranches, "feature")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/repository/tags", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "v1.0", "message": null,
+				"commit": {"id": "2695effb5807a22ff3d138d593fd856244e155e7", "committed_date": "2018-11-02T10:00:00Z"}}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"name": "v1.1", "message": "Release 1.1",
+			"commit": {"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "committed_date": "2018-11-20T10:12:45Z"}}]`)
+	})
+
+	// renamed-project was renamed to test-project, gitlab redirects from its old path
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/renamed-project", gitlabUserName), func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, fmt.Sprintf("/api/v4/projects/%s", gitlabProjectID), http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		// moved-project was moved into another group, where a search finds it
+		if search := r.URL.Query().Get("search"); search != "" {
+			suite.Require().Equal("true", r.URL.Query().Get("membership"))
+			if search != "moved-project" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprintf(w, `[{"id": %s, "name": "moved-project", "path": "moved-project"}, {"id": 1, "name": "moved-project-2", "path": "moved-project-2"}]`, gitlabProjectID)
+			return
+		}
+		suite.Require().Equal("true", r.URL.Query().Get("owned"))
+		src, err := ioutil.ReadFile("test_data/gitlab/user-projects.json")
+
+		suite.Require().Nil(err)
+		w.Write(src)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			suite.projectEdit = map[string]interface{}{}
+			suite.Require().Nil(json.NewDecoder(r.Body).Decode(&suite.projectEdit))
+		}
+		src, err := ioutil.ReadFile("test_data/gitlab/project.json")
+		suite.Require().Nil(err)
+		w.Write(src)
+	})
+
 	gitlabRouter := util.Router{
-		fmt.Sprintf("/api/v4/projects/%s", gitlabProjectID): util.MethodMap{
-			"GET": "project.json",
+		fmt.Sprintf("/api/v4/projects/%s/repository/files/README.md", gitlabProjectID): util.MethodMap{
+			"GET": "file.README.md.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/repository/commits/master", gitlabProjectID): util.MethodMap{
+			"GET": "commit.master.json",
 		},
 	}
 	for path, methodMap := range gitlabRouter {
@@ -143,6 +543,33 @@ func (suite *GitlabProviderSuite) TestListRepositories() {
 	}
 }
 
+func (suite *GitlabProviderSuite) TestListRepositoriesPaged() {
+	page, err := suite.provider.ListRepositoriesPaged(gitlabOrgName, git.ListOptions{PerPage: 1})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(page.Repositories, 1)
+	suite.Require().Equal("orgproject", page.Repositories[0].Name)
+	suite.Require().True(page.HasMore)
+	suite.Require().Equal("2", page.NextCursor)
+
+	page, err = suite.provider.ListRepositoriesPaged(gitlabOrgName, git.ListOptions{PerPage: 1, Cursor: page.NextCursor})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(page.Repositories, 1)
+	suite.Require().Equal("test-project", page.Repositories[0].Name)
+	suite.Require().False(page.HasMore)
+	suite.Require().Equal("", page.NextCursor)
+}
+
+func (suite *GitlabProviderSuite) TestListRepositoriesForCurrentUser() {
+	repositories, err := suite.provider.ListRepositoriesForCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Len(repositories, 2)
+	suite.Require().Equal("userproject", repositories[0].Name)
+	suite.Require().Equal("https://gitlab.com/testperson/userproject.git", repositories[0].CloneURL)
+}
+
 func (suite *GitlabProviderSuite) TestGetRepository() {
 	repo, err := suite.provider.GetRepository(gitlabUserName, gitlabProjectName)
 
@@ -150,24 +577,622 @@ func (suite *GitlabProviderSuite) TestGetRepository() {
 	suite.Require().NotNil(repo)
 
 	suite.Require().Equal(gitlabProjectName, repo.Name)
+	suite.Require().True(repo.HasIssuesEnabled)
+	suite.Require().Equal(1013, repo.Size)
+	suite.Require().Nil(repo.PushedAt)
+	suite.Require().Equal(time.Date(2018, 4, 20, 3, 37, 15, 166000000, time.UTC), *repo.UpdatedAt)
+	suite.Require().Equal("master", repo.DefaultBranch)
+}
+
+func (suite *GitlabProviderSuite) TestGetRepositoryByProjectID() {
+	repo, err := suite.provider.GetRepository(gitlabUserName, "pid:"+gitlabProjectID)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(gitlabProjectName, repo.Name)
+
+	_, err = suite.provider.GetRepository(gitlabUserName, "pid:test-project")
+	suite.Require().NotNil(err)
+}
+
+func (suite *GitlabProviderSuite) TestGetMovedRepository() {
+	repo, err := suite.provider.GetRepository(gitlabUserName, "renamed-project")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(gitlabProjectName, repo.Name)
+
+	repo, err = suite.provider.GetRepository(gitlabUserName, "moved-project")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(gitlabProjectName, repo.Name)
+
+	_, err = suite.provider.GetRepository(gitlabUserName, "missing-project")
+	suite.Require().NotNil(err)
+}
+
+func (suite *GitlabProviderSuite) TestUpdateCommitStatus() {
+	status, err := suite.provider.UpdateCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, &git.RepoStatus{
+		State:       "failure",
+		Context:     "ci/build",
+		TargetURL:   "https://ci.example.com/builds/1",
+		Description: "The build failed",
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("failed", status.State)
+	suite.Require().Equal("ci/build", status.Context)
+	suite.Require().Equal("https://ci.example.com/builds/1", status.TargetURL)
+	suite.Require().Equal("The build failed", status.Description)
+
+	_, err = suite.provider.UpdateCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, &git.RepoStatus{State: "unknown"})
+	suite.Require().NotNil(err)
+}
+
+func (suite *GitlabProviderSuite) TestGetCommitStatus() {
+	status, err := suite.provider.GetCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, "ci/build")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Require().Equal("success", status.State)
+
+	status, err = suite.provider.GetCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, "ci/lint")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("failed", status.State)
+
+	status, err = suite.provider.GetCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, "missing")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(status)
+}
+
+func (suite *GitlabProviderSuite) TestGetContent() {
+	content, err := suite.provider.GetContent(gitlabUserName, gitlabProjectName, "README.md", "master")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(content)
+	suite.Require().Equal("README.md", content.Name)
+	suite.Require().Equal("README.md", content.Path)
+	suite.Require().Equal("base64", content.Encoding)
+	suite.Require().Equal(40, content.Size)
+	suite.Require().Equal("79f7bbd25901e8334750839545a9bd021f0e4c83", content.Sha)
+	suite.Require().Equal("# test-project\n\nA project for testing\n", content.Content)
+}
+
+func (suite *GitlabProviderSuite) TestGetContentMissingFile() {
+	content, err := suite.provider.GetContent(gitlabUserName, gitlabProjectName, "missing.txt", "master")
+
+	suite.Require().Nil(content)
+	suite.Require().NotNil(err)
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitlabProviderSuite) TestResolveRef() {
+	sha, err := suite.provider.ResolveRef(gitlabUserName, gitlabProjectName, "master")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6", sha)
+
+	_, err = suite.provider.ResolveRef(gitlabUserName, gitlabProjectName, "missing")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitlabProviderSuite) TestUpdatePullRequestStatusReadsLabels() {
+	number := 2
+	pr := &git.PullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+
+	err := suite.provider.UpdatePullRequestStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(pr.Labels, 2)
+	suite.Require().True(pr.HasLabel("do-not-merge"))
+	suite.Require().False(pr.HasLabel("approved"))
+}
+
+func (suite *GitlabProviderSuite) TestSearchIssues() {
+	issues, err := suite.provider.SearchIssues(gitlabUserName, gitlabProjectName, "is:open flaky build")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 2)
+	suite.Require().Equal("opened", *issues[0].State)
+	suite.Require().Equal("testperson", issues[0].User.Login)
+
+	issues, err = suite.provider.SearchIssues(gitlabUserName, gitlabProjectName, "is:open author:reviewer flaky build")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal(5, *issues[0].Number)
+}
+
+func (suite *GitlabProviderSuite) TestIssueLabels() {
+	err := suite.provider.AddLabelsToIssue(gitlabUserName, gitlabProjectName, 4, []string{"flaky", "bug"})
+	suite.Require().Nil(err)
+	suite.Require().Equal("bug,triage,flaky", suite.issueEdit["labels"])
+
+	err = suite.provider.RemoveLabelFromIssue(gitlabUserName, gitlabProjectName, 4, "triage")
+	suite.Require().Nil(err)
+	suite.Require().Equal("bug", suite.issueEdit["labels"])
+}
+
+func (suite *GitlabProviderSuite) TestRemoveLastIssueLabel() {
+	err := suite.provider.RemoveLabelFromIssue(gitlabUserName, gitlabProjectName, 5, "flaky")
+	suite.Require().Nil(err)
+	labels, ok := suite.issueEdit["labels"]
+	suite.Require().True(ok, "the labels should be sent even when there are none left")
+	suite.Require().Equal("", labels)
+}
+
+func (suite *GitlabProviderSuite) TestCreateGroupWebHook() {
+	err := suite.provider.CreateGroupWebHook(gitlabOrgName, &git.WebhookArguments{
+		URL:    "https://jenkins.example.com/gitlab-webhook/",
+		Secret: "someSecret",
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("https://jenkins.example.com/gitlab-webhook/", suite.groupHook["url"])
+	suite.Require().Equal("someSecret", suite.groupHook["token"])
+	suite.Require().Equal(true, suite.groupHook["push_events"])
+	suite.Require().Equal(true, suite.groupHook["merge_requests_events"])
+
+	err = suite.provider.CreateGroupWebHook(gitlabOrgName, &git.WebhookArguments{
+		URL:    "https://jenkins.example.com/gitlab-webhook/",
+		Events: []string{"push", "tag_push"},
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(true, suite.groupHook["push_events"])
+	suite.Require().Equal(true, suite.groupHook["tag_push_events"])
+	suite.Require().Equal(false, suite.groupHook["merge_requests_events"])
+	suite.Require().Equal(false, suite.groupHook["note_events"])
+
+	suite.groupHook = nil
+	err = suite.provider.CreateGroupWebHook(gitlabOrgName, &git.WebhookArguments{URL: "/gitlab-webhook/"})
+	suite.Require().NotNil(err)
+	err = suite.provider.CreateGroupWebHook(gitlabOrgName, &git.WebhookArguments{
+		URL:    "https://jenkins.example.com/gitlab-webhook/",
+		Events: []string{"pushes"},
+	})
+	suite.Require().NotNil(err)
+	suite.Require().Nil(suite.groupHook)
+}
+
+func (suite *GitlabProviderSuite) TestListGroupWebHooks() {
+	hooks, err := suite.provider.ListGroupWebHooks(gitlabOrgName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(hooks, 2)
+	suite.Require().Equal(int64(7), hooks[0].ID)
+	suite.Require().Equal(gitlabOrgName, hooks[0].Owner)
+	suite.Require().Equal("https://jenkins.example.com/gitlab-webhook/", hooks[0].URL)
+}
+
+func (suite *GitlabProviderSuite) TestDeleteGroupWebHook() {
+	err := suite.provider.DeleteGroupWebHook(gitlabOrgName, 7)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(fmt.Sprintf("/api/v4/groups/%s/hooks/7", gitlabOrgName), suite.deletedGroupHook)
+}
+
+func (suite *GitlabProviderSuite) TestGetCurrentUser() {
+	user, err := suite.provider.GetCurrentUser()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(gitlabUserName, user.Login)
+	suite.Require().Equal("Test Person", user.Name)
+	suite.Require().Equal("testperson@example.com", user.Email)
+	suite.Require().Equal("https://gitlab.com/testperson", user.URL)
+}
+
+func (suite *GitlabProviderSuite) TestGetUsers() {
+	suite.userQueries = nil
+	users, err := suite.provider.GetUsers([]string{"alice", "bob", "nobody", "alice"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 2)
+	suite.Require().Equal("User alice", users["alice"].Name)
+	suite.Require().Equal("bob@example.com", users["bob"].Email)
+	suite.Require().Equal([][]string{{"alice", "bob", "nobody"}}, suite.userQueries)
+
+	// the users which were found are cached
+	users, err = suite.provider.GetUsers([]string{"alice", "carol"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 2)
+	suite.Require().Equal([][]string{{"alice", "bob", "nobody"}, {"carol"}}, suite.userQueries)
+}
+
+func (suite *GitlabProviderSuite) TestGetUsersWithoutGraphQL() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		username := r.URL.Query().Get("username")
+		if username == "nobody" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprintf(w, `[{"id": 21, "username": "%s", "name": "User %s"}]`, username, username)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(gitlabUserName, server.URL, "test", "gitlab", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	users, err := p.GetUsers([]string{"alice", "bob", "nobody"})
+
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 2)
+	suite.Require().Equal("User alice", users["alice"].Name)
+	suite.Require().Equal("User bob", users["bob"].Name)
+}
+
+func (suite *GitlabProviderSuite) TestServerWithSubpath() {
+	var privateToken string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gitlab/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		privateToken = r.Header.Get("Private-Token")
+		fmt.Fprintf(w, `{"id": 1, "username": "%s"}`, gitlabUserName)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewProvider(gitlabUserName, server.URL+"/gitlab/", "test", "gitlab", git.NewGitCLI())
+	suite.Require().Nil(err)
+
+	user, err := p.GetCurrentUser()
+	suite.Require().Nil(err)
+	suite.Require().Equal(gitlabUserName, user.Login)
+	suite.Require().Equal("test", privateToken)
+}
+
+func (suite *GitlabProviderSuite) TestWithGitlabClientContext() {
+	type key string
+	ctx := context.WithValue(context.Background(), key("test"), "value")
+	p, err := WithGitlabClient("https://gitlab.com", gitlabUserName, gitlab.NewClient(nil, ""), git.NewGitCLI(), git.WithContext(ctx))
+	suite.Require().Nil(err)
+	suite.Require().Equal(ctx, p.(*GitlabProvider).Context)
+
+	p, err = WithGitlabClient("https://gitlab.com", gitlabUserName, gitlab.NewClient(nil, ""), git.NewGitCLI())
+	suite.Require().Nil(err)
+	suite.Require().Equal(context.Background(), p.(*GitlabProvider).Context)
+}
+
+func (suite *GitlabProviderSuite) TestListMilestones() {
+	milestones, err := suite.provider.ListMilestones(gitlabUserName, gitlabProjectName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(milestones, 2)
+	suite.Require().Equal(int64(12), milestones[0].ID)
+	suite.Require().Equal("v1.0", milestones[0].Title)
+	suite.Require().Equal(git.MilestoneStateClosed, milestones[0].State)
+	suite.Require().Equal(time.Date(2018, 10, 9, 0, 0, 0, 0, time.UTC), *milestones[0].DueOn)
+	suite.Require().Equal(git.MilestoneStateOpen, milestones[1].State)
+	suite.Require().Nil(milestones[1].DueOn)
+}
+
+func (suite *GitlabProviderSuite) TestCreateAndCloseMilestone() {
+	due := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+	milestone, err := suite.provider.CreateMilestone(gitlabUserName, gitlabProjectName, "v1.2", &due)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(int64(14), milestone.ID)
+	suite.Require().Equal(git.MilestoneStateOpen, milestone.State)
+	suite.Require().Equal("v1.2", suite.milestone["title"])
+	suite.Require().Equal("2018-12-01", suite.milestone["due_date"])
+
+	err = suite.provider.CloseMilestone(gitlabUserName, gitlabProjectName, milestone.ID)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("close", suite.milestone["state_event"])
+}
+
+func (suite *GitlabProviderSuite) TestListContributors() {
+	contributors, err := suite.provider.ListContributors(gitlabUserName, gitlabProjectName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(contributors, 3)
+	suite.Require().Equal("Most", contributors[0].User.Name)
+	suite.Require().Equal("most@example.com", contributors[0].User.Email)
+	suite.Require().Equal(12, contributors[0].Contributions)
+	suite.Require().Equal("Some", contributors[1].User.Name)
+	suite.Require().Equal("Few", contributors[2].User.Name)
+}
+
+func (suite *GitlabProviderSuite) TestBranchesContainingCommit() {
+	branches, err := suite.provider.BranchesContainingCommit(gitlabUserName, gitlabProjectName, "6104942438c14ec7bd21c6cd5bd995272b3faff6")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"master", "feature", "release"}, branches)
+
+	_, err = suite.provider.BranchesContainingCommit(gitlabUserName, gitlabProjectName, "0000000000000000000000000000000000000000")
+	suite.Require().True(git.IsNotFound(err))
+}
+
+func (suite *GitlabProviderSuite) TestDeleteBranch() {
+	suite.deletedBranches = nil
+
+	err := suite.provider.DeleteBranch(gitlabUserName, gitlabProjectName, "feature")
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"feature"}, suite.deletedBranches)
+
+	// a branch which is already gone is fine
+	err = suite.provider.DeleteBranch(gitlabUserName, gitlabProjectName, "deleted")
+	suite.Require().Nil(err)
+
+	err = suite.provider.DeleteBranch(gitlabUserName, gitlabProjectName, "master")
+	suite.Require().True(git.IsDefaultBranch(err))
+	suite.Require().Len(suite.deletedBranches, 1)
+}
+
+func (suite *GitlabProviderSuite) TestCreateBranch() {
+	err := suite.provider.CreateBranch(gitlabUserName, gitlabProjectName, "feature", "master")
+	suite.Require().Nil(err)
+	suite.Require().Equal("feature", suite.createdBranch["branch"])
+	suite.Require().Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6", suite.createdBranch["ref"])
+
+	err = suite.provider.CreateBranch(gitlabUserName, gitlabProjectName, "master", "master")
+	suite.Require().True(git.IsAlreadyExists(err))
+}
+
+func (suite *GitlabProviderSuite) TestSetRepositoryTopics() {
+	err := suite.provider.SetRepositoryTopics(gitlabUserName, gitlabProjectName, []string{"git", "go"})
+	suite.Require().Nil(err)
+	suite.Require().Equal([]interface{}{"git", "go"}, suite.projectEdit["tag_list"])
+	suite.Require().Equal([]interface{}{"git", "go"}, suite.projectEdit["topics"])
+
+	// the topics are cleared rather than left alone
+	err = suite.provider.SetRepositoryTopics(gitlabUserName, gitlabProjectName, nil)
+	suite.Require().Nil(err)
+	suite.Require().Equal([]interface{}{}, suite.projectEdit["tag_list"])
+	suite.Require().Equal([]interface{}{}, suite.projectEdit["topics"])
+}
+
+func (suite *GitlabProviderSuite) TestListRepositoryTags() {
+	tags, err := suite.provider.ListRepositoryTags(gitlabUserName, gitlabProjectName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(tags, 2)
+	suite.Require().Equal("v1.1", tags[0].Name)
+	suite.Require().Equal("Release 1.1", tags[0].Message)
+	suite.Require().Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6", tags[0].SHA)
+	suite.Require().Equal(time.Date(2018, 11, 20, 10, 12, 45, 0, time.UTC), *tags[0].Date)
+	suite.Require().Equal("v1.0", tags[1].Name)
+	suite.Require().Empty(tags[1].Message)
+	suite.Require().Equal("2695effb5807a22ff3d138d593fd856244e155e7", tags[1].SHA)
+}
+
+func (suite *GitlabProviderSuite) TestGetFileLastCommit() {
+	commit, err := suite.provider.GetFileLastCommit(gitlabUserName, gitlabProjectName, "README.md", "master")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(commit)
+	suite.Require().Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6", commit.SHA)
+	suite.Require().Equal("Sanitize for network graph", commit.Message)
+	suite.Require().Equal("randx", commit.Author.Name)
+	suite.Require().Equal("dmitriy.zaporozhets@gmail.com", commit.Author.Email)
+
+	commit, err = suite.provider.GetFileLastCommit(gitlabUserName, gitlabProjectName, "missing.md", "master")
+	suite.Require().Nil(err)
+	suite.Require().Nil(commit)
+}
+
+func (suite *GitlabProviderSuite) TestListReleases() {
+	releases, err := suite.provider.ListReleases(gitlabUserName, gitlabProjectName)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(releases, 2)
+	suite.Require().Equal("Release 1.1", releases[0].Name)
+	suite.Require().Equal("v1.1", releases[0].TagName)
+	suite.Require().Equal("Adds promotion", releases[0].Body)
+	suite.Require().Equal("https://gitlab.com/testperson/test-project/-/releases/v1.1", releases[0].URL)
+	suite.Require().Len(*releases[0].Assets, 2)
+	suite.Require().Equal("linux-amd64", (*releases[0].Assets)[0].Name)
+	suite.Require().Equal("https://example.com/v1.1/linux-amd64", (*releases[0].Assets)[0].BrowserDownloadURL)
+
+	// older servers don't link to the release
+	suite.Require().Equal(suite.server.URL+"/testperson/test-project/-/tags/v1.0", releases[1].URL)
+	suite.Require().Len(*releases[1].Assets, 1)
+}
+
+func (suite *GitlabProviderSuite) TestUpdateRelease() {
+	release := &git.Release{Name: "Release 1.1", TagName: "v1.1", Body: "Adds promotion and fixes"}
+	err := suite.provider.UpdateRelease(gitlabUserName, gitlabProjectName, "v1.1", release)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(http.MethodPut, suite.releaseMethod)
+	suite.Require().Equal(map[string]interface{}{"name": "Release 1.1", "description": "Adds promotion and fixes"}, suite.release)
+	suite.Require().Equal("https://gitlab.com/testperson/test-project/-/releases/v1.1", release.URL)
+}
+
+func (suite *GitlabProviderSuite) TestUpdateReleaseCreatesIt() {
+	release := &git.Release{Name: "Release 2.0", TagName: "v2.0", Body: "Breaks everything"}
+	err := suite.provider.UpdateRelease(gitlabUserName, gitlabProjectName, "v2.0", release)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(http.MethodPost, suite.releaseMethod)
+	suite.Require().Equal(map[string]interface{}{"name": "Release 2.0", "tag_name": "v2.0", "description": "Breaks everything"}, suite.release)
+	suite.Require().Equal(suite.server.URL+"/testperson/test-project/-/tags/v2.0", release.URL)
+}
+
+func (suite *GitlabProviderSuite) TestListPullRequests() {
+	repo := &git.Repository{Name: gitlabProjectName}
+	prs, err := suite.provider.ListPullRequests(gitlabUserName, repo, git.PullRequestStateClosed)
+
+	// the open merge request on the first page is left out
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 2)
+	suite.Require().Equal(1, *prs[0].Number)
+	suite.Require().True(*prs[0].Merged)
+	suite.Require().Equal(3, *prs[1].Number)
+	suite.Require().False(*prs[1].Merged)
+}
+
+func (suite *GitlabProviderSuite) TestCreatePullRequestAssignsUsers() {
+	args := &git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: gitlabUserName, Name: gitlabProjectName},
+		Assignees:  []string{"some"},
+		Reviewers:  []string{"most"},
+	}
+	pr, err := suite.provider.CreatePullRequest(args)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(4, *pr.Number)
+	suite.Require().Equal([]interface{}{float64(21)}, suite.mergeRequestEdit["assignee_ids"])
+	suite.Require().Equal([]interface{}{float64(23)}, suite.mergeRequestEdit["reviewer_ids"])
+
+	// reviewers who can't be found are skipped
+	suite.mergeRequestEdit = nil
+	args.Assignees = nil
+	args.Reviewers = []string{"nobody"}
+	_, err = suite.provider.CreatePullRequest(args)
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(suite.mergeRequestEdit)
+
+	// failing to assign the merge request still returns it
+	args.Assignees = []string{"nobody"}
+	pr, err = suite.provider.CreatePullRequest(args)
+
+	suite.Require().NotNil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(4, *pr.Number)
+}
+
+func (suite *GitlabProviderSuite) TestCreateDraftPullRequest() {
+	pr, err := suite.provider.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: gitlabUserName, Name: gitlabProjectName},
+		Draft:      true,
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(4, *pr.Number)
+	suite.Require().Equal("Draft: Promote to version 1.0.4", suite.mergeRequest["title"])
+	suite.Require().Equal("promote-1.0.4", suite.mergeRequest["source_branch"])
+
+	// a title which is already a draft is left alone
+	_, err = suite.provider.CreatePullRequest(&git.PullRequestArguments{
+		Title:      "Draft: Promote to version 1.0.4",
+		Head:       "promote-1.0.4",
+		Base:       "master",
+		Repository: &git.Repository{Organisation: gitlabUserName, Name: gitlabProjectName},
+		Draft:      true,
+	})
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("Draft: Promote to version 1.0.4", suite.mergeRequest["title"])
+}
+
+func (suite *GitlabProviderSuite) TestGetPullRequestByBranch() {
+	repo := &git.Repository{Name: gitlabProjectName}
+	pr, err := suite.provider.GetPullRequestByBranch(gitlabUserName, repo, "promote-1.0.3")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(2, *pr.Number)
+	suite.Require().Equal("promote-1.0.3", *pr.HeadRef)
+
+	pr, err = suite.provider.GetPullRequestByBranch(gitlabUserName, repo, "promote-1.0.4")
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(pr)
+}
+
+func (suite *GitlabProviderSuite) TestListPullRequestsByAuthor() {
+	repo := &git.Repository{Name: gitlabProjectName}
+	prs, err := suite.provider.ListPullRequestsByAuthor(gitlabUserName, repo, gitlabUserName, git.PullRequestStateOpen)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 1)
+	suite.Require().Equal(2, *prs[0].Number)
+	suite.Require().Equal(gitlabUserName, prs[0].Author.Login)
+}
+
+func (suite *GitlabProviderSuite) TestGetPullRequestStats() {
+	number := 1
+	pr := &git.PullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+	stats, err := suite.provider.GetPullRequestStats(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(&git.DiffStat{Additions: 2, Deletions: 2, ChangedFiles: 2}, stats)
+}
+
+func (suite *GitlabProviderSuite) TestGetPullRequestApprovalState() {
+	number := 1
+	pr := &git.PullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+	state, err := suite.provider.GetPullRequestApprovalState(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().False(state.Approved)
+	suite.Require().Equal(1, state.ApprovalsLeft)
+	suite.Require().Empty(state.ApprovedBy)
+	suite.Require().True(state.ResetOnPush)
+	suite.Require().NotNil(state.LastApprovedAt)
+	suite.Require().Equal("2018-06-04T11:00:00Z", state.LastApprovedAt.UTC().Format(time.RFC3339))
+
+	// the last commit was pushed after the approval, which the project then removed
+	needed, err := git.NeedsReapproval(suite.provider, pr)
+	suite.Require().Nil(err)
+	suite.Require().True(needed)
+}
+
+func (suite *GitlabProviderSuite) TestListPullRequestActivity() {
+	number := 1
+	pr := &git.PullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+	events, err := suite.provider.ListPullRequestActivity(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(events, 3)
+	suite.Require().Equal("301", events[0].ID)
+	suite.Require().Equal("commented", events[0].Action)
+	suite.Require().Equal("Looks good", events[0].Body)
+	suite.Require().Equal("approved", events[1].Action)
+	suite.Require().Equal("merged", events[2].Action)
+	suite.Require().Equal(gitlabUserName, events[2].Actor.Login)
+}
+
+func (suite *GitlabProviderSuite) TestGetAheadBehind() {
+	ahead, behind, err := suite.provider.GetAheadBehind(gitlabUserName, "userproject", gitlabOrgName, "orgproject", "master")
+
+	suite.Require().Nil(err)
+	suite.Require().Equal(1, ahead)
+	suite.Require().Equal(3, behind)
 }
 
 func (suite *GitlabProviderSuite) TestAddCollaborator() {
-	err := suite.provider.AddCollaborator("derek", orgName, "repo")
+	err := suite.provider.AddCollaborator("derek", gitlabOrgName, "repo")
 	suite.Require().Nil(err)
 }
 
 func (suite *GitlabProviderSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
-	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	invites, err := suite.provider.ListInvitations()
+	suite.Require().Nil(invites)
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 func (suite *GitlabProviderSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
-	suite.Require().Nil(err)
+	err := suite.provider.AcceptInvitation(1)
+	suite.Require().True(git.IsNotSupported(err))
 }
 
 // In order for 'go test' to run this suite, we need to create